@@ -12,14 +12,19 @@ import (
 	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 
+	"core/models/modelfile"
 	"core/utils"
 )
 
 const defaultProb = 0.00000000001
 
+// DOC: defaultSmoother is the Laplace smoothing applied to the priors.
+const defaultSmoother = 1.0
+
 var ErrUnderflow = errors.New("possible underflow detected")
 
 type NBClass string
@@ -33,6 +38,11 @@ type NBClassifier struct {
 	rawDatas        map[NBClass]*classData
 	tfIdf           bool
 	DidConvertTfIdf bool
+	lastActive      map[NBClass]time.Time
+	halfLife        time.Duration
+	anchor          time.Time
+	unseenProb      float64
+	smoother        float64
 }
 
 // DOC: serializableClassifier represents a container for Classifier objects
@@ -45,36 +55,69 @@ type serializableClassifier struct {
 	RawDatas        map[NBClass]*classData
 	TfIdf           bool
 	DidConvertTfIdf bool
+	LastActive      map[NBClass]time.Time
+	HalfLife        time.Duration
+	Anchor          time.Time
 }
 
 // DOC: classData holds the frequency data for words in a particular class.
+//      Docs is the number of documents learned for the class and is needed
+//      to back the class out of the document count when it is removed.
+//      WeightedTotal and TfWeights carry the time decay weights; they are
+//      only populated, and Decayed set, when the classifier has a half-life.
 type classData struct {
-	Freqs   map[string]float64
-	FreqTfs map[string][]float64
-	Total   int
+	Freqs         map[string]float64
+	FreqTfs       map[string][]float64
+	Total         int
+	Docs          int
+	WeightedTotal float64
+	TfWeights     map[string][]float64
+	Decayed       bool
 }
 
 func newClassData() *classData {
 	return &classData{
-		Freqs:   make(map[string]float64),
-		FreqTfs: make(map[string][]float64),
+		Freqs:     make(map[string]float64),
+		FreqTfs:   make(map[string][]float64),
+		TfWeights: make(map[string][]float64),
+	}
+}
+
+// DOC: total is the denominator used for word probabilities and priors; the
+//      decayed word mass is used when it is available, even once it has
+//      underflowed to zero.
+func (d *classData) total() float64 {
+	if d.Decayed || d.WeightedTotal > 0 {
+		return d.WeightedTotal
+	}
+	return float64(d.Total)
+}
+
+// DOC: tfWeight returns the decay weight of a single term frequency sample.
+func (d *classData) tfWeight(word string, index int) float64 {
+	weights := d.TfWeights[word]
+	if index < len(weights) {
+		return weights[index]
 	}
+	return 1.0
 }
 
-// DOC: The probability of seeing a word in a document of this class.
-func (d *classData) getWordProb(word string) float64 {
+// DOC: The probability of seeing a word in a document of this class; unseen
+//      is returned for words the class has never seen or whose decayed
+//      evidence has underflowed.
+func (d *classData) getWordProb(word string, unseen float64) float64 {
 	value, ok := d.Freqs[word]
-	if !ok {
-		return defaultProb
+	if !ok || value <= 0 {
+		return unseen
 	}
-	return float64(value) / float64(d.Total)
+	return float64(value) / d.total()
 }
 
 // DOC: The probability of seeing a set of words in a document of this class.
-func (d *classData) getWordsProb(words []string) (prob float64) {
+func (d *classData) getWordsProb(words []string, unseen float64) (prob float64) {
 	prob = 1
 	for _, word := range words {
-		prob *= d.getWordProb(word)
+		prob *= d.getWordProb(word, unseen)
 	}
 	return
 }
@@ -97,10 +140,11 @@ func classifierChecks(classes ...NBClass) {
 func NewNBClassifierTfIdf(classes ...NBClass) (c *NBClassifier) {
 	classifierChecks(classes...)
 	c = &NBClassifier{
-		Classes:  classes,
-		datas:    make(map[NBClass]*classData, len(classes)),
-		rawDatas: make(map[NBClass]*classData, len(classes)),
-		tfIdf:    true,
+		Classes:    classes,
+		datas:      make(map[NBClass]*classData, len(classes)),
+		rawDatas:   make(map[NBClass]*classData, len(classes)),
+		tfIdf:      true,
+		lastActive: make(map[NBClass]time.Time, len(classes)),
 	}
 	for _, class := range classes {
 		c.datas[class] = newClassData()
@@ -117,6 +161,7 @@ func NewNBClassifier(classes ...NBClass) (c *NBClassifier) {
 		rawDatas:        make(map[NBClass]*classData, len(classes)),
 		tfIdf:           false,
 		DidConvertTfIdf: false,
+		lastActive:      make(map[NBClass]time.Time, len(classes)),
 	}
 	for _, class := range classes {
 		c.datas[class] = newClassData()
@@ -131,33 +176,32 @@ func NewNBClassifierFromFile(name string) (c *NBClassifier, err error) {
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 	return NewNBClassifierFromReader(file)
 }
 
-// DOC: This actually does the deserializing of a GOB encoded classifier
+// DOC: This actually does the deserializing of a model file (see ReadModel);
+//      legacy GOB encoded classifiers are migrated on read.
 func NewNBClassifierFromReader(r io.Reader) (c *NBClassifier, err error) {
-	dec := gob.NewDecoder(r)
-	w := new(serializableClassifier)
-	err = dec.Decode(w)
-
-	return &NBClassifier{w.Classes, w.Learned, int32(w.Seen), w.Datas, w.RawDatas, w.TfIdf, w.DidConvertTfIdf}, err
+	c, _, err = ReadModel(r)
+	return c, err
 }
 
 func (c *NBClassifier) getPriors() (priors []float64) {
 	n := len(c.Classes)
 	priors = make([]float64, n, n)
-	sum := 0
-	smoother := 1.0
+	sum := 0.0
+	smoother := c.Smoother()
 	for index, class := range c.Classes {
-		total := c.datas[class].Total
-		priors[index] = float64(total)
+		total := c.datas[class].total()
+		priors[index] = total
 		sum += total
 	}
 	if sum != 0 {
 		for i := 0; i < n; i++ {
 			// DOC: This is Laplace smoothing implemented below.
 			priors[i] += smoother
-			priors[i] /= (float64(sum) + (smoother * float64(n)))
+			priors[i] /= (sum + (smoother * float64(n)))
 		}
 	}
 	return
@@ -175,6 +219,91 @@ func (c *NBClassifier) IsTfIdf() bool {
 	return c.tfIdf
 }
 
+// DOC: SetHalfLife enables exponential time decay of the training evidence.
+//      A document resolved one half-life before another counts for half as
+//      much in both the word frequencies and the priors. It must be set
+//      before any documents are learned; zero disables decay.
+func (c *NBClassifier) SetHalfLife(halfLife time.Duration) {
+	c.halfLife = halfLife
+}
+
+func (c *NBClassifier) HalfLife() time.Duration {
+	return c.halfLife
+}
+
+// DOC: SetUnseenProb sets the probability given to words a class has never
+//      seen; zero restores the default.
+func (c *NBClassifier) SetUnseenProb(prob float64) {
+	c.unseenProb = prob
+}
+
+func (c *NBClassifier) UnseenProb() float64 {
+	if c.unseenProb <= 0 {
+		return defaultProb
+	}
+	return c.unseenProb
+}
+
+// DOC: SetSmoother sets the Laplace smoothing of the priors; zero restores
+//      the default.
+func (c *NBClassifier) SetSmoother(smoother float64) {
+	c.smoother = smoother
+}
+
+func (c *NBClassifier) Smoother() float64 {
+	if c.smoother <= 0 {
+		return defaultSmoother
+	}
+	return c.smoother
+}
+
+// DOC: decayWeight returns the weight of a document resolved at the given
+//      time relative to the newest document learned (the anchor), which
+//      weighs 1. A newer document moves the anchor and scales the stored
+//      evidence down, so weights never exceed 1 and the Laplace smoothing
+//      and unseen probability always apply to the same decayed mass;
+//      evidence many half-lives old underflows to zero.
+func (c *NBClassifier) decayWeight(at time.Time) float64 {
+	if c.halfLife <= 0 || at.IsZero() {
+		return 1.0
+	}
+	if c.anchor.IsZero() {
+		c.anchor = at
+	}
+	if at.After(c.anchor) {
+		c.rescale(math.Exp2(-float64(at.Sub(c.anchor)) / float64(c.halfLife)))
+		c.anchor = at
+	}
+	return math.Exp2(-float64(c.anchor.Sub(at)) / float64(c.halfLife))
+}
+
+// DOC: rescale multiplies every decayed quantity by the factor.
+func (c *NBClassifier) rescale(factor float64) {
+	for _, datas := range []map[NBClass]*classData{c.datas, c.rawDatas} {
+		for _, data := range datas {
+			data.WeightedTotal *= factor
+			for word := range data.Freqs {
+				data.Freqs[word] *= factor
+			}
+			for _, weights := range data.TfWeights {
+				for i := range weights {
+					weights[i] *= factor
+				}
+			}
+		}
+	}
+	if c.DidConvertTfIdf {
+		// Converted term frequencies already carry their weight.
+		for _, data := range c.datas {
+			for _, tfs := range data.FreqTfs {
+				for i := range tfs {
+					tfs[i] *= factor
+				}
+			}
+		}
+	}
+}
+
 // DOC: WordCount finds the number of words for each class in the classifier.
 func (c *NBClassifier) WordCount() (result []int) {
 	result = make([]int, len(c.Classes))
@@ -193,75 +322,173 @@ func (c *NBClassifier) Observe(word string, count int, which NBClass) {
 
 // DOC: Learn will accept new training documents for supervised learning.
 func (c *NBClassifier) Learn(document []string, which NBClass) {
+	c.LearnAt(document, which, time.Time{})
+}
+
+// DOC: LearnAt is Learn for a document resolved at the given time; the time
+//      is only used when a half-life has been set.
+func (c *NBClassifier) LearnAt(document []string, which NBClass, at time.Time) {
+	weight := c.decayWeight(at)
 	if c.tfIdf {
 		if c.DidConvertTfIdf {
 			//utils.ModelSummary.Panic("Cannot call ConvertTermsFreqToTfIdf more than once. Reset and relearn to reconvert.")
 		}
+		c.observeTfs(document, which, weight)
+	}
+	c.observeWords(document, which, weight)
+}
 
-		// DOC: Term frequency: word count in document / document length.
-		docTf := make(map[string]float64)
-		for _, word := range document {
-			docTf[word]++
-		}
+// DOC: observeTfs records the term frequency of each word in the document:
+//      word count in document / document length.
+func (c *NBClassifier) observeTfs(document []string, which NBClass, weight float64) {
+	docTf := make(map[string]float64)
+	for _, word := range document {
+		docTf[word]++
+	}
 
-		docLen := float64(len(document))
-		for wIndex, wCount := range docTf {
-			docTf[wIndex] = wCount / docLen
-			c.datas[which].FreqTfs[wIndex] = append(c.datas[which].FreqTfs[wIndex], docTf[wIndex])
-			c.rawDatas[which].FreqTfs[wIndex] = append(c.rawDatas[which].FreqTfs[wIndex], docTf[wIndex])
+	docLen := float64(len(document))
+	for wIndex, wCount := range docTf {
+		docTf[wIndex] = wCount / docLen
+		c.datas[which].FreqTfs[wIndex] = append(c.datas[which].FreqTfs[wIndex], docTf[wIndex])
+		c.rawDatas[which].FreqTfs[wIndex] = append(c.rawDatas[which].FreqTfs[wIndex], docTf[wIndex])
+		if c.halfLife > 0 {
+			c.rawDatas[which].TfWeights[wIndex] = append(c.rawDatas[which].TfWeights[wIndex], weight)
 		}
 	}
+}
 
+func (c *NBClassifier) observeWords(document []string, which NBClass, weight float64) {
 	data := c.datas[which]
 	rawData := c.rawDatas[which]
 	for _, word := range document {
-		data.Freqs[word]++
+		data.Freqs[word] += weight
 		data.Total++
-		rawData.Freqs[word]++
+		rawData.Freqs[word] += weight
 		rawData.Total++
+		if c.halfLife > 0 {
+			data.WeightedTotal += weight
+			rawData.WeightedTotal += weight
+			data.Decayed, rawData.Decayed = true, true
+		}
 	}
+	data.Docs++
+	rawData.Docs++
 	c.learned++
 }
 
-func (c *NBClassifier) OnlineLearn(document []string, which NBClass) {
-	if !c.tfIdf {
-		c.Learn(document, which)
+// DOC: HasClass reports whether the class is currently known to the
+//      classifier.
+func (c *NBClassifier) HasClass(class NBClass) bool {
+	_, ok := c.datas[class]
+	return ok
+}
+
+// DOC: AddClass registers a class that was not present at Learn time (e.g. a
+//      new contributor). It is a no-op for classes that already exist.
+func (c *NBClassifier) AddClass(class NBClass) {
+	if c.HasClass(class) {
+		return
 	}
-	// DOC: Term frequency: word count in document / document length.
-	docTf := make(map[string]float64)
-	for _, word := range document {
-		docTf[word]++
+	c.Classes = append(c.Classes, class)
+	c.datas[class] = newClassData()
+	c.rawDatas[class] = newClassData()
+}
+
+// DOC: RemoveClass drops a class and its word statistics; the document count
+//      used by TF-IDF is reduced by the documents the class contributed.
+func (c *NBClassifier) RemoveClass(class NBClass) {
+	if !c.HasClass(class) {
+		return
+	}
+	for i := 0; i < len(c.Classes); i++ {
+		if c.Classes[i] == class {
+			c.Classes = append(c.Classes[:i], c.Classes[i+1:]...)
+			break
+		}
 	}
+	c.learned -= c.rawDatas[class].Docs
+	delete(c.datas, class)
+	delete(c.rawDatas, class)
+	delete(c.lastActive, class)
+	if c.tfIdf && c.DidConvertTfIdf {
+		c.recomputeTfIdf()
+	}
+}
 
-	if c.rawDatas[which] == nil {
-		c.rawDatas[which] = newClassData()
-		c.datas[which] = newClassData()
+// DOC: MarkActive records the most recent activity for a class; older
+//      timestamps are ignored.
+func (c *NBClassifier) MarkActive(class NBClass, at time.Time) {
+	if c.lastActive == nil {
+		c.lastActive = make(map[NBClass]time.Time)
+	}
+	if at.After(c.lastActive[class]) {
+		c.lastActive[class] = at
 	}
+}
 
-	docLen := float64(len(document))
-	for wIndex, wCount := range docTf {
-		docTf[wIndex] = wCount / docLen
-		c.rawDatas[which].FreqTfs[wIndex] = append(c.rawDatas[which].FreqTfs[wIndex], docTf[wIndex])
-		c.datas[which].FreqTfs[wIndex] = append(c.datas[which].FreqTfs[wIndex], docTf[wIndex])
+// DOC: LastActive returns the most recent recorded activity for a class.
+func (c *NBClassifier) LastActive(class NBClass) time.Time {
+	return c.lastActive[class]
+}
+
+// DOC: RetireInactive removes every class whose last activity is older than
+//      window relative to now. Classes without any recorded activity are
+//      kept and at least two classes always remain, as required by the
+//      constructors. The retired classes are returned.
+func (c *NBClassifier) RetireInactive(now time.Time, window time.Duration) []NBClass {
+	retired := []NBClass{}
+	if window <= 0 {
+		return retired
+	}
+	cutoff := now.Add(-window)
+	candidates := []NBClass{}
+	for _, class := range c.Classes {
+		last, ok := c.lastActive[class]
+		if ok && !last.IsZero() && last.Before(cutoff) {
+			candidates = append(candidates, class)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return c.lastActive[candidates[i]].Before(c.lastActive[candidates[j]])
+	})
+	for _, class := range candidates {
+		if len(c.Classes) <= 2 {
+			break
+		}
+		c.RemoveClass(class)
+		retired = append(retired, class)
 	}
+	return retired
+}
 
-	data := c.datas[which]
-	rawData := c.rawDatas[which]
-	for _, word := range document {
-		data.Freqs[word]++
-		data.Total++
-		rawData.Freqs[word]++
-		rawData.Total++
+func (c *NBClassifier) OnlineLearn(document []string, which NBClass) {
+	c.OnlineLearnAt(document, which, time.Time{})
+}
+
+// DOC: OnlineLearnAt is OnlineLearn for a document resolved at the given
+//      time; the time is only used when a half-life has been set.
+func (c *NBClassifier) OnlineLearnAt(document []string, which NBClass, at time.Time) {
+	c.AddClass(which)
+	if !c.tfIdf {
+		c.LearnAt(document, which, at)
+		return
 	}
-	c.learned++
+	weight := c.decayWeight(at)
+	c.observeTfs(document, which, weight)
+	c.observeWords(document, which, weight)
+	c.recomputeTfIdf()
+}
 
+// DOC: recomputeTfIdf rebuilds the weighted frequencies of every class from
+//      the raw term frequencies using the current document count.
+func (c *NBClassifier) recomputeTfIdf() {
 	for className, _ := range c.rawDatas {
 		sample := math.Log1p(float64(c.learned) / float64(c.rawDatas[className].Total))
 		for wIndex, _ := range c.rawDatas[className].FreqTfs {
 			tfIdfAdder := float64(0)
 			freqTfs := c.datas[className].FreqTfs[wIndex]
 			for tfSampleIndex, tf := range c.rawDatas[className].FreqTfs[wIndex] {
-				result := math.Log1p(tf) * sample
+				result := math.Log1p(tf) * sample * c.rawDatas[className].tfWeight(wIndex, tfSampleIndex)
 				tfIdfAdder += result
 				freqTfs[tfSampleIndex] = result
 			}
@@ -279,7 +506,8 @@ func (c *NBClassifier) ConvertTermsFreqToTfIdf() {
 			tfIdfAdder := float64(0)
 			for tfSampleIndex, _ := range c.datas[className].FreqTfs[wIndex] {
 				tf := c.datas[className].FreqTfs[wIndex][tfSampleIndex]
-				c.datas[className].FreqTfs[wIndex][tfSampleIndex] = math.Log1p(tf) * math.Log1p(float64(c.learned)/float64(c.datas[className].Total))
+				weight := c.rawDatas[className].tfWeight(wIndex, tfSampleIndex)
+				c.datas[className].FreqTfs[wIndex][tfSampleIndex] = math.Log1p(tf) * math.Log1p(float64(c.learned)/float64(c.datas[className].Total)) * weight
 				tfIdfAdder += c.datas[className].FreqTfs[wIndex][tfSampleIndex]
 			}
 			c.datas[className].Freqs[wIndex] = tfIdfAdder
@@ -302,7 +530,7 @@ func (c *NBClassifier) LogScores(document []string) (scores []float64, inx int,
 		data := c.datas[class]
 		score := math.Log(priors[index])
 		for _, word := range document {
-			score += math.Log(data.getWordProb(word))
+			score += math.Log(data.getWordProb(word, c.UnseenProb()))
 		}
 		scores[index] = score
 	}
@@ -324,7 +552,7 @@ func (c *NBClassifier) ProbScores(doc []string) (scores []float64, inx int, stri
 		data := c.datas[class]
 		score := priors[index]
 		for _, word := range doc {
-			score *= data.getWordProb(word)
+			score *= data.getWordProb(word, c.UnseenProb())
 		}
 		scores[index] = score
 		sum += score
@@ -352,7 +580,7 @@ func (c *NBClassifier) SafeProbScores(doc []string) (scores []float64, inx int,
 		score := priors[index]
 		logScore := math.Log(priors[index])
 		for _, word := range doc {
-			p := data.getWordProb(word)
+			p := data.getWordProb(word, c.UnseenProb())
 			score *= p
 			logScore += math.Log(p)
 		}
@@ -381,7 +609,7 @@ func (c *NBClassifier) WordFrequencies(words []string) (freqMatrix [][]float64)
 		arr := make([]float64, l)
 		data := c.datas[c.Classes[i]]
 		for j, _ := range arr {
-			arr[j] = data.getWordProb(words[j])
+			arr[j] = data.getWordProb(words[j], c.UnseenProb())
 		}
 		freqMatrix[i] = arr
 	}
@@ -391,17 +619,18 @@ func (c *NBClassifier) WordFrequencies(words []string) (freqMatrix [][]float64)
 // DOC: Returns a map of words and their probability for a given class.
 func (c *NBClassifier) WordsByClass(class NBClass) (freqMap map[string]float64) {
 	freqMap = make(map[string]float64)
-	for word, cnt := range c.datas[class].Freqs {
-		freqMap[word] = float64(cnt) / float64(c.datas[class].Total)
+	for word := range c.datas[class].Freqs {
+		freqMap[word] = c.datas[class].getWordProb(word, 0)
 	}
 	return freqMap
 }
 
 func (c *NBClassifier) WriteToFile(name string) (err error) {
-	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 	return c.WriteTo(file)
 }
 
@@ -424,11 +653,10 @@ func (c *NBClassifier) WriteClassToFile(name NBClass, rootPath string) (err erro
 	return
 }
 
+// DOC: WriteTo writes the classifier as a model file with a bare header; use
+//      WriteModel to record the repository and training cursor.
 func (c *NBClassifier) WriteTo(w io.Writer) (err error) {
-	enc := gob.NewEncoder(w)
-	err = enc.Encode(&serializableClassifier{c.Classes, c.learned, int(c.seen), c.datas, c.rawDatas, c.tfIdf, c.DidConvertTfIdf})
-
-	return
+	return c.WriteModel(w, modelfile.Header{})
 }
 
 func (c *NBClassifier) ReadClassFromFile(class NBClass, location string) (err error) {
@@ -503,7 +731,7 @@ func (c *NBClassifier) GenerateProbabilityTable(issueID int64, content string, a
 		results[i+1][0] = words[i]
 		for j := range assignees {
 			data := c.datas[NBClass(assignees[j])]
-			results[i+1][j+1] = fmt.Sprintf("%f", math.Log(data.getWordProb(words[i])))
+			results[i+1][j+1] = fmt.Sprintf("%f", math.Log(data.getWordProb(words[i], c.UnseenProb())))
 		}
 	}
 
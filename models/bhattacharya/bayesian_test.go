@@ -0,0 +1,195 @@
+package bhattacharya
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"core/models/modelfile"
+)
+
+func TestOnlineLearnNewClass(t *testing.T) {
+	c := NewNBClassifierTfIdf("luke", "leia")
+	c.Learn([]string{"lightsaber", "force"}, "luke")
+	c.Learn([]string{"blaster", "rebellion"}, "leia")
+	c.ConvertTermsFreqToTfIdf()
+
+	c.OnlineLearn([]string{"falcon", "smuggler"}, "han")
+	if !c.HasClass("han") {
+		t.Error("\nNEW CLASS NOT ADDED DURING ONLINE LEARN")
+	}
+	if len(c.Classes) != 3 {
+		t.Error(
+			"\nCLASSES NOT UPDATED",
+			"\nEXPECTED: ", 3,
+			"\nACTUAL:   ", len(c.Classes),
+		)
+	}
+
+	scores, inx, _ := c.LogScores([]string{"falcon", "smuggler"})
+	if len(scores) != 3 || c.Classes[inx] != "han" {
+		t.Error(
+			"\nNEW CLASS NOT PREDICTED",
+			"\nSCORES:   ", scores,
+		)
+	}
+
+	priors := c.getPriors()
+	sum := 0.0
+	for i := range priors {
+		sum += priors[i]
+	}
+	if sum < 0.9999 || sum > 1.0001 {
+		t.Error(
+			"\nPRIORS NOT NORMALIZED",
+			"\nSUM:      ", sum,
+		)
+	}
+}
+
+func TestRetireInactive(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewNBClassifierTfIdf("obiwan", "yoda", "anakin")
+	c.Learn([]string{"high", "ground"}, "obiwan")
+	c.Learn([]string{"do", "not", "try"}, "yoda")
+	c.Learn([]string{"sand", "coarse"}, "anakin")
+	c.ConvertTermsFreqToTfIdf()
+	c.MarkActive("obiwan", now)
+	c.MarkActive("yoda", now.AddDate(0, -1, 0))
+	c.MarkActive("anakin", now.AddDate(-2, 0, 0))
+
+	retired := c.RetireInactive(now, 365*24*time.Hour)
+	if len(retired) != 1 || retired[0] != "anakin" {
+		t.Error(
+			"\nINACTIVE CLASS NOT RETIRED",
+			"\nRETIRED:  ", retired,
+		)
+	}
+	if c.HasClass("anakin") || len(c.Classes) != 2 {
+		t.Error(
+			"\nRETIRED CLASS STILL PRESENT",
+			"\nCLASSES:  ", c.Classes,
+		)
+	}
+	if c.Learned() != 2 {
+		t.Error(
+			"\nDOCUMENT COUNT NOT ADJUSTED",
+			"\nEXPECTED: ", 2,
+			"\nACTUAL:   ", c.Learned(),
+		)
+	}
+
+	retired = c.RetireInactive(now.AddDate(10, 0, 0), time.Hour)
+	if len(retired) != 0 || len(c.Classes) != 2 {
+		t.Error(
+			"\nCLASSIFIER RETIRED BELOW TWO CLASSES",
+			"\nCLASSES:  ", c.Classes,
+		)
+	}
+}
+
+func TestTimeDecayPriors(t *testing.T) {
+	past := time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := past.AddDate(5, 0, 0)
+	learn := func(c *NBClassifier) {
+		for i := 0; i < 4; i++ {
+			c.LearnAt([]string{"parser", "grammar"}, "vader", past)
+		}
+		c.LearnAt([]string{"parser", "grammar"}, "rey", recent)
+		c.ConvertTermsFreqToTfIdf()
+	}
+
+	flat := NewNBClassifierTfIdf("vader", "rey")
+	learn(flat)
+	priors := flat.getPriors()
+	if priors[0] <= priors[1] {
+		t.Error(
+			"\nUNDECAYED PRIORS SHOULD FAVOR MORE EVIDENCE",
+			"\nPRIORS:   ", priors,
+		)
+	}
+
+	decayed := NewNBClassifierTfIdf("vader", "rey")
+	decayed.SetHalfLife(180 * 24 * time.Hour)
+	learn(decayed)
+	priors = decayed.getPriors()
+	if priors[1] <= priors[0] {
+		t.Error(
+			"\nDECAYED PRIORS SHOULD FAVOR RECENT EVIDENCE",
+			"\nPRIORS:   ", priors,
+		)
+	}
+}
+
+func TestTimeDecayOnlineLearn(t *testing.T) {
+	past := time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewNBClassifierTfIdf("vader", "rey")
+	c.SetHalfLife(30 * 24 * time.Hour)
+	c.LearnAt([]string{"lexer", "tokenizer", "lexer"}, "vader", past)
+	c.LearnAt([]string{"lexer", "tokenizer"}, "vader", past)
+	c.LearnAt([]string{"docs", "website"}, "rey", past)
+	c.ConvertTermsFreqToTfIdf()
+
+	document := []string{"lexer", "tokenizer"}
+	_, inx, _ := c.LogScores(document)
+	if c.Classes[inx] != "vader" {
+		t.Error("\nINITIAL EVIDENCE NOT LEARNED")
+	}
+
+	c.OnlineLearnAt([]string{"lexer", "tokenizer"}, "rey", past.AddDate(2, 0, 0))
+	_, inx, _ = c.LogScores(document)
+	if c.Classes[inx] != "rey" {
+		t.Error(
+			"\nOLDER EVIDENCE NOT FADING",
+			"\nVADER:    ", c.datas["vader"].getWordProb("lexer", defaultProb),
+			"\nREY:      ", c.datas["rey"].getWordProb("lexer", defaultProb),
+		)
+	}
+}
+
+func TestTimeDecayLongSpan(t *testing.T) {
+	start := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewNBClassifierTfIdf("vader", "rey")
+	c.SetHalfLife(24 * time.Hour)
+	c.LearnAt([]string{"lexer", "tokenizer"}, "vader", start)
+	c.LearnAt([]string{"docs", "website"}, "rey", start)
+	for day := 1; day <= 3*365; day++ {
+		c.LearnAt([]string{"parser", "grammar"}, "rey", start.AddDate(0, 0, day))
+	}
+	c.LearnAt([]string{"lexer", "tokenizer"}, "vader", start.AddDate(3, 0, 0))
+	c.ConvertTermsFreqToTfIdf()
+
+	for _, class := range c.Classes {
+		if total := c.datas[class].total(); math.IsInf(total, 0) || math.IsNaN(total) || total > float64(c.datas[class].Total) {
+			t.Error(
+				"\nDECAYED MASS NOT BOUNDED",
+				"\nCLASS:    ", class,
+				"\nACTUAL:   ", total,
+			)
+		}
+	}
+	scores, inx, _ := c.LogScores([]string{"lexer", "tokenizer"})
+	for _, score := range scores {
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			t.Fatal(
+				"\nSCORES NOT FINITE",
+				"\nACTUAL:   ", scores,
+			)
+		}
+	}
+	if c.Classes[inx] != "vader" {
+		t.Error(
+			"\nRECENT EVIDENCE NOT LEARNED",
+			"\nEXPECTED: ", "vader",
+			"\nACTUAL:   ", c.Classes[inx],
+		)
+	}
+	buffer := bytes.Buffer{}
+	if err := c.WriteModel(&buffer, modelfile.Header{}); err != nil {
+		t.Error(
+			"\nDECAYED MODEL NOT WRITTEN",
+			"\nACTUAL:   ", err,
+		)
+	}
+}
@@ -1,21 +1,48 @@
 package bhattacharya
 
 import (
+	"io"
+	"math"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"core/models/modelfile"
+	"core/models/ownership"
+	"core/models/preprocess"
 	"core/pipeline/gateway/conflation"
 	"core/utils"
 )
 
-// DOC: NBClassifier is the struct implemented as the model algorithm.
+// DOC: NBModel is the struct implemented as the model algorithm; it wraps
+//      an NBClassifier. Params.Model builds one from the per repo Params.
 type NBModel struct {
 	classifier *NBClassifier
 	assignees  []NBClass
+	// DOC: InactivityWindow, when set, retires assignees whose most recent
+	//      resolved issue is older than the window during OnlineLearn.
+	InactivityWindow time.Duration
+	// DOC: HalfLife, when set, decays older issues (by Issue.Resolved) in
+	//      both Learn and OnlineLearn.
+	HalfLife time.Duration
+	// DOC: RepoID, TrainingCursor and ModelVersion are recorded in the header
+	//      of recovery files (see SetCursor and SetVersion).
+	RepoID         int64
+	TrainingCursor int64
+	ModelVersion   int64
+	// DOC: Preprocessor, when set, replaces the whitespace split, stopword
+	//      removal and stemming of issue bodies.
+	Preprocessor *preprocess.Preprocessor
+	// DOC: Ownership, when set, adds OwnershipWeight times the code ownership
+	//      score of each assignee to its log score in Predict.
+	Ownership       *ownership.Index
+	OwnershipWeight float64
+	// DOC: Params are the tunable hyperparameters (see Params).
+	Params Params
 }
 
 type Result struct {
@@ -41,22 +68,33 @@ func (c *NBModel) IsBootstrapped() bool {
 	return c.classifier != nil
 }
 
+func (c *NBModel) Classes() []string {
+	return convertClassToString(c.assignees)
+}
+
 func (c *NBModel) Learn(input []conflation.ExpandedIssue) {
 	adjusted := c.converter(input...)
-
-	removeStopWords(adjusted...)
-	stemIssues(adjusted...)
+	c.prepare(adjusted)
 	c.assignees = distinctAssignees(adjusted)
 	if len(c.assignees) < 2 {
 		//TODO: Add logging
 		return
 	}
 	utils.ModelLog.Info("Bhattacharya Learn", zap.Int("AssigneesCount", len(c.assignees))) //, zap.String("Repository", repo))
-	c.classifier = NewNBClassifierTfIdf(c.assignees...)
+	if c.Params.DisableTfIdf {
+		c.classifier = NewNBClassifier(c.assignees...)
+	} else {
+		c.classifier = NewNBClassifierTfIdf(c.assignees...)
+	}
+	c.classifier.SetHalfLife(c.HalfLife)
+	c.Params.apply(c.classifier)
 	for i := 0; i < len(input); i++ {
-		c.classifier.Learn(strings.Split(adjusted[i].Body, " "), NBClass(adjusted[i].Assignees[0])) // NOTE: First position is a workaround
+		c.classifier.LearnAt(strings.Split(adjusted[i].Body, " "), NBClass(adjusted[i].Assignees[0]), adjusted[i].Resolved) // NOTE: First position is a workaround
+		c.classifier.MarkActive(NBClass(adjusted[i].Assignees[0]), adjusted[i].Resolved)
+	}
+	if c.classifier.IsTfIdf() {
+		c.classifier.ConvertTermsFreqToTfIdf()
 	}
-	c.classifier.ConvertTermsFreqToTfIdf()
 	//TODO: Fix later (logging related)
 	/*
 		for _, class := range c.assignees {
@@ -68,18 +106,63 @@ func (c *NBModel) Learn(input []conflation.ExpandedIssue) {
 
 func (c *NBModel) OnlineLearn(input []conflation.ExpandedIssue) {
 	adjusted := c.converter(input...)
-	removeStopWords(adjusted...)
-	stemIssues(adjusted...)
+	c.prepare(adjusted)
+	latest := time.Time{}
 	for i := 0; i < len(input); i++ {
-		c.classifier.OnlineLearn(strings.Split(adjusted[i].Body, " "), NBClass(adjusted[i].Assignees[0])) // NOTE: First position is a workaround
+		class := NBClass(adjusted[i].Assignees[0]) // NOTE: First position is a workaround
+		if !c.classifier.HasClass(class) {
+			utils.ModelLog.Info("Bhattacharya OnlineLearn new class", zap.String("Class", string(class)))
+		}
+		c.classifier.OnlineLearnAt(strings.Split(adjusted[i].Body, " "), class, adjusted[i].Resolved)
+		c.classifier.MarkActive(class, adjusted[i].Resolved)
+		if adjusted[i].Resolved.After(latest) {
+			latest = adjusted[i].Resolved
+		}
 	}
+	if c.InactivityWindow > 0 && !latest.IsZero() {
+		retired := c.classifier.RetireInactive(latest, c.InactivityWindow)
+		if len(retired) > 0 {
+			utils.ModelLog.Info("Bhattacharya OnlineLearn retired classes", zap.Strings("Classes", convertClassToString(retired)))
+		}
+	}
+	c.assignees = c.classifier.Classes
 }
 
 func (c *NBModel) Predict(input conflation.ExpandedIssue) []string {
+	names, _ := c.rank(input)
+	return names
+}
+
+// DOC: PredictWithConfidence returns the ranked assignees along with their
+//      posterior probabilities (a softmax over the log scores).
+func (c *NBModel) PredictWithConfidence(input conflation.ExpandedIssue) ([]string, []float64) {
+	names, results := c.rank(input)
+	confidences := make([]float64, len(results))
+	if len(results) == 0 {
+		return names, confidences
+	}
+	total := 0.0
+	for i := range results {
+		confidences[i] = math.Exp(results[i].score - results[0].score)
+		total += confidences[i]
+	}
+	for i := range confidences {
+		confidences[i] /= total
+	}
+	return names, confidences
+}
+
+func (c *NBModel) rank(input conflation.ExpandedIssue) ([]string, Results) {
 	adjusted := c.converter(input)
-	removeStopWordsSingle(&adjusted[0])
-	stemIssuesSingle(&adjusted[0])
+	body := adjusted[0].Body
+	c.prepare(adjusted)
 	scores, _, _ := c.classifier.LogScores(strings.Split(adjusted[0].Body, " "))
+	if c.Ownership != nil && c.OwnershipWeight > 0 {
+		owners := c.Ownership.Scores(preprocess.Parse(body))
+		for i := 0; i < len(scores); i++ {
+			scores[i] += c.OwnershipWeight * owners[string(c.assignees[i])]
+		}
+	}
 
 	results := Results{}
 	for i := 0; i < len(scores); i++ {
@@ -110,27 +193,79 @@ func (c *NBModel) Predict(input conflation.ExpandedIssue) []string {
 	for i := 0; i < len(names); i++ {
 		utils.ModelLog.Info("", zap.String("Class", strconv.Itoa(i)+": "+names[i]+", Score: "+strconv.FormatFloat(results[i].score, 'f', -1, 64)))
 	}
-	return names
+	return names, results
+}
+
+// DOC: SetCursor sets the RepoID and TrainingCursor of the recovery files.
+func (c *NBModel) SetCursor(repoID, cursor int64) {
+	c.RepoID, c.TrainingCursor = repoID, cursor
+}
+
+// DOC: SetVersion sets the ModelVersion of the recovery files.
+func (c *NBModel) SetVersion(version int64) {
+	c.ModelVersion = version
+}
+
+// DOC: Version returns the ModelVersion, e.g. as recovered from a file.
+func (c *NBModel) Version() int64 {
+	return c.ModelVersion
+}
+
+func (c *NBModel) header() modelfile.Header {
+	return modelfile.Header{RepoID: c.RepoID, TrainingCursor: c.TrainingCursor, ModelVersion: c.ModelVersion}
 }
 
 func (c *NBModel) GenerateRecoveryFile(path string) error {
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
-	return c.classifier.WriteTo(file)
+	defer file.Close()
+	return c.classifier.WriteModel(file, c.header())
 }
 
+// DOC: RecoverModelFromFile only replaces the classifier and assignees once
+//      the file has been fully decoded and verified.
 func (c *NBModel) RecoverModelFromFile(path string) error {
-	NBClassifier, err := NewNBClassifierFromFile(path)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	classifier, header, err := ReadModel(file)
 	if err != nil {
 		return err
 	}
-	c.classifier = NBClassifier
-	c.assignees = NBClassifier.Classes
+	c.Params.apply(classifier)
+	c.classifier = classifier
+	c.assignees = classifier.Classes
+	c.RepoID = header.RepoID
+	c.TrainingCursor = header.TrainingCursor
+	c.ModelVersion = header.ModelVersion
 	return nil
 }
 
+// DOC: ExportJSON writes the model as indented JSON for inspection.
+func (c *NBModel) ExportJSON(w io.Writer) error {
+	return c.classifier.ExportJSON(w, c.header())
+}
+
+// DOC: prepare normalizes the issue bodies into space separated features.
+func (c *NBModel) prepare(issues []Issue) {
+	if c.Preprocessor == nil {
+		if !c.Params.KeepStopWords {
+			removeStopWords(issues...)
+		}
+		if !c.Params.DisableStemming {
+			stemIssues(issues...)
+		}
+		return
+	}
+	for i := range issues {
+		issues[i].Body = strings.Join(c.Preprocessor.Features(issues[i].Body), " ")
+	}
+}
+
 func distinctAssignees(issues []Issue) []NBClass {
 	result := []NBClass{}
 	j := 0
@@ -0,0 +1,117 @@
+package bhattacharya
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"core/models/preprocess"
+	"core/pipeline/gateway/conflation"
+)
+
+// DOC: TermContribution is what a term of the issue adds to a candidate's
+//      log score. Lift is LogProb relative to the mean over all candidates,
+//      so terms with a positive lift favour the candidate.
+type TermContribution struct {
+	Term    string  `json:"term"`
+	Count   int     `json:"count"`
+	LogProb float64 `json:"log_prob"`
+	Lift    float64 `json:"lift"`
+}
+
+// DOC: Explanation breaks a candidate's log score down into the prior, the
+//      issue terms and any other boosts (e.g. code ownership). PriorShare is
+//      the fraction of the score that comes from the prior.
+type Explanation struct {
+	Assignee   string             `json:"assignee"`
+	Score      float64            `json:"score"`
+	LogPrior   float64            `json:"log_prior"`
+	PriorShare float64            `json:"prior_share"`
+	Boost      float64            `json:"boost,omitempty"`
+	Terms      []TermContribution `json:"terms"`
+}
+
+// DOC: Because returns the terms that favour the candidate, strongest first.
+func (e Explanation) Because() []string {
+	terms := []string{}
+	for _, term := range e.Terms {
+		if term.Lift > 0 {
+			terms = append(terms, term.Term)
+		}
+	}
+	return terms
+}
+
+// DOC: Explain attributes the log score of every class to its prior and to
+//      the distinct terms of the document. Each term contributes its count
+//      times the log probability of the term in the class; terms are ranked
+//      by their lift over the mean contribution across classes and at most
+//      top terms are kept (all when top is not positive). The results are in
+//      class order.
+func (c *NBClassifier) Explain(document []string, top int) []Explanation {
+	counts := make(map[string]int)
+	terms := []string{}
+	for _, word := range document {
+		if word == "" {
+			continue
+		}
+		if counts[word] == 0 {
+			terms = append(terms, word)
+		}
+		counts[word]++
+	}
+
+	mean := make(map[string]float64)
+	for _, class := range c.Classes {
+		for _, term := range terms {
+			mean[term] += float64(counts[term]) * math.Log(c.datas[class].getWordProb(term, c.UnseenProb())) / float64(len(c.Classes))
+		}
+	}
+
+	priors := c.getPriors()
+	explanations := make([]Explanation, len(c.Classes))
+	for index, class := range c.Classes {
+		data := c.datas[class]
+		explanation := Explanation{Assignee: string(class), LogPrior: math.Log(priors[index])}
+		explanation.Score = explanation.LogPrior
+		contributions := []TermContribution{}
+		for _, term := range terms {
+			logProb := float64(counts[term]) * math.Log(data.getWordProb(term, c.UnseenProb()))
+			explanation.Score += logProb
+			contributions = append(contributions, TermContribution{Term: term, Count: counts[term], LogProb: logProb, Lift: logProb - mean[term]})
+		}
+		sort.SliceStable(contributions, func(i, j int) bool {
+			return contributions[i].Lift > contributions[j].Lift
+		})
+		if top > 0 && len(contributions) > top {
+			contributions = contributions[:top]
+		}
+		explanation.Terms = contributions
+		if explanation.Score != 0 {
+			explanation.PriorShare = explanation.LogPrior / explanation.Score
+		}
+		explanations[index] = explanation
+	}
+	return explanations
+}
+
+// DOC: Explain returns the explanation of every assignee, best first, for
+//      the same document and scores Predict uses (including the ownership
+//      boost).
+func (c *NBModel) Explain(input conflation.ExpandedIssue, top int) []Explanation {
+	adjusted := c.converter(input)
+	body := adjusted[0].Body
+	c.prepare(adjusted)
+	explanations := c.classifier.Explain(strings.Split(adjusted[0].Body, " "), top)
+	if c.Ownership != nil && c.OwnershipWeight > 0 {
+		owners := c.Ownership.Scores(preprocess.Parse(body))
+		for i := range explanations {
+			explanations[i].Boost = c.OwnershipWeight * owners[explanations[i].Assignee]
+			explanations[i].Score += explanations[i].Boost
+		}
+	}
+	sort.SliceStable(explanations, func(i, j int) bool {
+		return explanations[i].Score > explanations[j].Score
+	})
+	return explanations
+}
@@ -0,0 +1,45 @@
+package bhattacharya
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	c := NewNBClassifierTfIdf("luke", "leia")
+	c.Learn([]string{"lightsaber", "force", "force"}, "luke")
+	c.Learn([]string{"blaster", "rebellion"}, "leia")
+	c.ConvertTermsFreqToTfIdf()
+
+	document := []string{"lightsaber", "force", "blaster", "force"}
+	scores, _, _ := c.LogScores(document)
+	explanations := c.Explain(document, 2)
+	for i, explanation := range explanations {
+		if math.Abs(explanation.Score-scores[i]) > 1e-9 {
+			t.Error(
+				"\nEXPLANATION DOES NOT ADD UP TO SCORE",
+				"\nEXPECTED: ", scores[i],
+				"\nACTUAL:   ", explanation.Score,
+			)
+		}
+		if explanation.PriorShare <= 0 || explanation.PriorShare >= 1 || len(explanation.Terms) != 2 {
+			t.Error(
+				"\nEXPLANATION INCOMPLETE",
+				"\nACTUAL:   ", explanation,
+			)
+		}
+	}
+	luke := explanations[0]
+	if luke.Assignee != "luke" || luke.Terms[0].Term != "force" || luke.Terms[0].Count != 2 || luke.Because()[1] != "lightsaber" {
+		t.Error(
+			"\nTOP TERMS NOT RANKED BY LIFT",
+			"\nACTUAL:   ", luke.Terms,
+		)
+	}
+	if leia := explanations[1]; leia.Because()[0] != "blaster" {
+		t.Error(
+			"\nTOP TERMS NOT RANKED BY LIFT",
+			"\nACTUAL:   ", leia.Terms,
+		)
+	}
+}
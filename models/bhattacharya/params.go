@@ -0,0 +1,97 @@
+package bhattacharya
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"core/models/preprocess"
+)
+
+// DOC: Params are the hyperparameters of the model; the zero value is the
+//      default configuration. UnseenProb is the probability given to a word
+//      a class has never seen and Smoother the Laplace smoothing of the
+//      priors; zero keeps their defaults. DisableTfIdf learns raw word
+//      counts. KeepStopWords and DisableStemming only apply without a
+//      Preprocessor. InactivityWindow, HalfLife and Preprocess (with
+//      NGrams) set the fields of the same name of the NBModel built by
+//      Model. Params are encoded as JSON for the backend to load per repo.
+type Params struct {
+	UnseenProb       float64       `json:"unseen_prob,omitempty"`
+	Smoother         float64       `json:"smoother,omitempty"`
+	DisableTfIdf     bool          `json:"disable_tfidf,omitempty"`
+	KeepStopWords    bool          `json:"keep_stop_words,omitempty"`
+	DisableStemming  bool          `json:"disable_stemming,omitempty"`
+	InactivityWindow time.Duration `json:"inactivity_window,omitempty"`
+	HalfLife         time.Duration `json:"half_life,omitempty"`
+	Preprocess       bool          `json:"preprocess,omitempty"`
+	NGrams           int           `json:"ngrams,omitempty"`
+}
+
+// DOC: Model returns an untrained NBModel configured with the parameters.
+func (p Params) Model() *NBModel {
+	model := &NBModel{
+		InactivityWindow: p.InactivityWindow,
+		HalfLife:         p.HalfLife,
+		Params:           p,
+	}
+	if p.Preprocess {
+		model.Preprocessor = &preprocess.Preprocessor{NGrams: p.NGrams, Normalize: NormalizeToken}
+	}
+	return model
+}
+
+// DOC: Valid reports whether the parameters are within their ranges.
+func (p Params) Valid() bool {
+	return p.UnseenProb >= 0 && p.UnseenProb < 1 && p.Smoother >= 0 &&
+		p.InactivityWindow >= 0 && p.HalfLife >= 0 && p.NGrams >= 0
+}
+
+func (p Params) apply(classifier *NBClassifier) {
+	classifier.SetUnseenProb(p.UnseenProb)
+	classifier.SetSmoother(p.Smoother)
+}
+
+// DOC: String summarizes the parameters with their effective values.
+func (p Params) String() string {
+	unseen, smoother := p.UnseenProb, p.Smoother
+	if unseen <= 0 {
+		unseen = defaultProb
+	}
+	if smoother <= 0 {
+		smoother = defaultSmoother
+	}
+	fields := []string{
+		"unseen=" + strconv.FormatFloat(unseen, 'g', -1, 64),
+		"smoother=" + strconv.FormatFloat(smoother, 'g', -1, 64),
+		"tfidf=" + strconv.FormatBool(!p.DisableTfIdf),
+		"stopwords=" + strconv.FormatBool(!p.KeepStopWords),
+		"stemming=" + strconv.FormatBool(!p.DisableStemming),
+	}
+	if p.InactivityWindow > 0 {
+		fields = append(fields, "inactivity="+p.InactivityWindow.String())
+	}
+	if p.HalfLife > 0 {
+		fields = append(fields, "halflife="+p.HalfLife.String())
+	}
+	if p.Preprocess {
+		fields = append(fields, "ngrams="+strconv.Itoa(p.NGrams))
+	}
+	return strings.Join(fields, " ")
+}
+
+// DOC: ReadParams decodes parameters written by WriteParams.
+func ReadParams(r io.Reader) (Params, error) {
+	p := Params{}
+	err := json.NewDecoder(r).Decode(&p)
+	return p, err
+}
+
+// DOC: WriteParams encodes the parameters as indented JSON.
+func WriteParams(w io.Writer, p Params) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
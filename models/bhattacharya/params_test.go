@@ -0,0 +1,101 @@
+package bhattacharya
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParams(t *testing.T) {
+	c := NewNBClassifier("luke", "leia")
+	c.Learn([]string{"lightsaber", "force"}, "luke")
+	c.Learn([]string{"blaster", "rebellion", "rebellion"}, "leia")
+
+	defaults, _, _ := c.LogScores([]string{"podrace"})
+	Params{UnseenProb: 0.001, Smoother: 10}.apply(c)
+	tuned, _, _ := c.LogScores([]string{"podrace"})
+
+	// luke has 2 of the 5 words, smoothed by 10 over two classes.
+	expected := math.Log(12.0/25) + math.Log(0.001)
+	if math.Abs(tuned[0]-expected) > 1e-9 || tuned[0] == defaults[0] {
+		t.Error(
+			"\nPARAMETERS NOT APPLIED",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", tuned[0],
+		)
+	}
+
+	Params{}.apply(c)
+	if c.UnseenProb() != defaultProb || c.Smoother() != defaultSmoother {
+		t.Error(
+			"\nZERO PARAMETERS DO NOT RESTORE DEFAULTS",
+			"\nACTUAL:   ", c.UnseenProb(), c.Smoother(),
+		)
+	}
+}
+
+func TestParamsPrepare(t *testing.T) {
+	issues := []Issue{{Body: "the jedi are running"}}
+	model := NBModel{Params: Params{KeepStopWords: true, DisableStemming: true}}
+	model.prepare(issues)
+	if issues[0].Body != "the jedi are running" {
+		t.Error(
+			"\nBODY PREPROCESSED",
+			"\nACTUAL:   ", issues[0].Body,
+		)
+	}
+}
+
+func TestParamsReadWrite(t *testing.T) {
+	params := Params{UnseenProb: 1e-6, Smoother: 0.5, DisableTfIdf: true}
+	buffer := bytes.Buffer{}
+	if err := WriteParams(&buffer, params); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadParams(&buffer)
+	if err != nil || read != params {
+		t.Error(
+			"\nPARAMETERS NOT ROUND TRIPPED",
+			"\nEXPECTED: ", params,
+			"\nACTUAL:   ", read, err,
+		)
+	}
+	if s := (Params{}).String(); s != "unseen=1e-11 smoother=1 tfidf=true stopwords=true stemming=true" {
+		t.Error(
+			"\nUNEXPECTED SUMMARY",
+			"\nACTUAL:   ", s,
+		)
+	}
+}
+
+func TestParamsModel(t *testing.T) {
+	params := Params{InactivityWindow: 90 * 24 * time.Hour, HalfLife: 30 * 24 * time.Hour, Preprocess: true, NGrams: 2}
+	model := params.Model()
+	if model.InactivityWindow != params.InactivityWindow || model.HalfLife != params.HalfLife || model.Params != params {
+		t.Error(
+			"\nPARAMETERS NOT APPLIED",
+			"\nEXPECTED: ", params,
+			"\nACTUAL:   ", model.InactivityWindow, model.HalfLife, model.Params,
+		)
+	}
+	if model.Preprocessor == nil || model.Preprocessor.NGrams != 2 {
+		t.Error(
+			"\nPREPROCESSOR NOT SET",
+			"\nACTUAL:   ", model.Preprocessor,
+		)
+	}
+	if (Params{}).Model().Preprocessor != nil {
+		t.Error("\nPREPROCESSOR SET BY DEFAULT")
+	}
+	if (Params{HalfLife: -time.Hour}).Valid() {
+		t.Error("\nNEGATIVE HALF LIFE ACCEPTED")
+	}
+	if s := params.String(); !strings.HasSuffix(s, "inactivity=2160h0m0s halflife=720h0m0s ngrams=2") {
+		t.Error(
+			"\nUNEXPECTED SUMMARY",
+			"\nACTUAL:   ", s,
+		)
+	}
+}
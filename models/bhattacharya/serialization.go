@@ -0,0 +1,103 @@
+package bhattacharya
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"time"
+
+	"core/models/modelfile"
+)
+
+// DOC: Algorithm identifies Bhattacharya model files.
+const Algorithm = "bhattacharya"
+
+func init() {
+	// DOC: Version 1 files are the headerless GOB encoding used before the
+	//      versioned format; version 2 stores the same fields as JSON.
+	modelfile.RegisterMigration(Algorithm, 1, migrateGobToJSON)
+}
+
+func migrateGobToJSON(payload []byte) ([]byte, error) {
+	w := new(serializableClassifier)
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(w); err != nil {
+		return nil, err
+	}
+	return json.Marshal(w)
+}
+
+func (c *NBClassifier) serializable() *serializableClassifier {
+	return &serializableClassifier{c.Classes, c.learned, c.Seen(), c.datas, c.rawDatas, c.tfIdf, c.DidConvertTfIdf, c.lastActive, c.halfLife, c.anchor}
+}
+
+func (w *serializableClassifier) classifier() *NBClassifier {
+	if w.LastActive == nil {
+		w.LastActive = make(map[NBClass]time.Time)
+	}
+	for _, datas := range []map[NBClass]*classData{w.Datas, w.RawDatas} {
+		for _, data := range datas {
+			if data.Freqs == nil {
+				data.Freqs = make(map[string]float64)
+			}
+			if data.FreqTfs == nil {
+				data.FreqTfs = make(map[string][]float64)
+			}
+			if data.TfWeights == nil {
+				data.TfWeights = make(map[string][]float64)
+			}
+		}
+	}
+	return &NBClassifier{
+		Classes:         w.Classes,
+		learned:         w.Learned,
+		seen:            int32(w.Seen),
+		datas:           w.Datas,
+		rawDatas:        w.RawDatas,
+		tfIdf:           w.TfIdf,
+		DidConvertTfIdf: w.DidConvertTfIdf,
+		lastActive:      w.LastActive,
+		halfLife:        w.HalfLife,
+		anchor:          w.Anchor,
+	}
+}
+
+// DOC: WriteModel writes the classifier as a versioned model file. The
+//      algorithm is always set; the repository, training cursor and creation
+//      time are taken from the header.
+func (c *NBClassifier) WriteModel(w io.Writer, header modelfile.Header) error {
+	header.Algorithm = Algorithm
+	payload, err := json.Marshal(c.serializable())
+	if err != nil {
+		return err
+	}
+	return modelfile.Write(w, header, payload)
+}
+
+// DOC: ReadModel reads a model file, migrating older formats, and returns the
+//      classifier along with the file header. Nothing is returned on error so
+//      callers never see a partially decoded classifier.
+func ReadModel(r io.Reader) (*NBClassifier, modelfile.Header, error) {
+	header, payload, err := modelfile.Read(r, Algorithm)
+	if err != nil {
+		return nil, header, err
+	}
+	w := new(serializableClassifier)
+	if err := json.Unmarshal(payload, w); err != nil {
+		return nil, header, err
+	}
+	return w.classifier(), header, nil
+}
+
+// DOC: ExportJSON writes the header and classifier as indented JSON for
+//      inspection and diffing.
+func (c *NBClassifier) ExportJSON(w io.Writer, header modelfile.Header) error {
+	header.Algorithm = Algorithm
+	header.FormatVersion = modelfile.CurrentVersion
+	payload, err := json.Marshal(c.serializable())
+	if err != nil {
+		return err
+	}
+	header.Checksum = modelfile.Checksum(payload)
+	return modelfile.Export(w, header, payload)
+}
@@ -0,0 +1,118 @@
+package bhattacharya
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func trainedClassifier() *NBClassifier {
+	c := NewNBClassifierTfIdf("luke", "leia")
+	c.Learn([]string{"lightsaber", "force"}, "luke")
+	c.Learn([]string{"blaster", "rebellion"}, "leia")
+	c.ConvertTermsFreqToTfIdf()
+	c.MarkActive("luke", time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC))
+	return c
+}
+
+func TestWriteModelReadModel(t *testing.T) {
+	c := trainedClassifier()
+	buffer := new(bytes.Buffer)
+	if err := c.WriteTo(buffer); err != nil {
+		t.Fatal(err)
+	}
+	recovered, header, err := ReadModel(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Algorithm != Algorithm {
+		t.Error(
+			"\nALGORITHM NOT RECORDED",
+			"\nACTUAL:   ", header.Algorithm,
+		)
+	}
+	expected, _, _ := c.LogScores([]string{"force"})
+	actual, _, _ := recovered.LogScores([]string{"force"})
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Error(
+				"\nRECOVERED CLASSIFIER SCORES DIFFER",
+				"\nEXPECTED: ", expected,
+				"\nACTUAL:   ", actual,
+			)
+			break
+		}
+	}
+	if !recovered.LastActive("luke").Equal(c.LastActive("luke")) {
+		t.Error("\nLAST ACTIVE NOT RECOVERED")
+	}
+}
+
+func TestReadModelLegacyGob(t *testing.T) {
+	c := trainedClassifier()
+	buffer := new(bytes.Buffer)
+	gob.NewEncoder(buffer).Encode(c.serializable())
+
+	recovered, header, err := ReadModel(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.FormatVersion != 2 || len(recovered.Classes) != 2 {
+		t.Error(
+			"\nLEGACY MODEL NOT MIGRATED",
+			"\nVERSION:  ", header.FormatVersion,
+			"\nCLASSES:  ", recovered.Classes,
+		)
+	}
+}
+
+func TestRecoverModelFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bhattacharya")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	model := NBModel{classifier: trainedClassifier(), RepoID: 66, TrainingCursor: 1138, ModelVersion: 4}
+	model.assignees = model.classifier.Classes
+	path := filepath.Join(dir, "test.model")
+	if err := model.GenerateRecoveryFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := NBModel{}
+	if err := recovered.RecoverModelFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if recovered.RepoID != 66 || recovered.TrainingCursor != 1138 || recovered.ModelVersion != 4 || len(recovered.assignees) != 2 {
+		t.Error(
+			"\nMODEL HEADER NOT RECOVERED",
+			"\nREPOID:   ", recovered.RepoID,
+			"\nCURSOR:   ", recovered.TrainingCursor,
+			"\nVERSION:  ", recovered.ModelVersion,
+		)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.model")
+	contents, _ := ioutil.ReadFile(path)
+	ioutil.WriteFile(corrupt, contents[:len(contents)-5], 0644)
+	if err := recovered.RecoverModelFromFile(corrupt); err == nil {
+		t.Error("\nCORRUPT MODEL FILE NOT REJECTED")
+	}
+	if recovered.classifier == nil || len(recovered.assignees) != 2 {
+		t.Error("\nFAILED RECOVERY MODIFIED THE MODEL")
+	}
+
+	export := new(bytes.Buffer)
+	if err := recovered.ExportJSON(export); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(export.String(), `"repo_id": 66`) {
+		t.Error("\nEXPORT MISSING HEADER")
+	}
+}
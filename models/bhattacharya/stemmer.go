@@ -1,6 +1,7 @@
 package bhattacharya
 
 import (
+	"github.com/bbalet/stopwords"
 	"github.com/kljensen/snowball"
 	"strings"
 )
@@ -33,3 +34,14 @@ func stemIssuesSingle(issue *Issue) {
 	wordString := strings.Join(wordList, " ")
 	issue.Body = wordString
 }
+
+// DOC: NormalizeToken removes stopwords and stems the remaining token; it is
+//      intended as the Normalize function of a preprocess.Preprocessor.
+func NormalizeToken(token string) string {
+	cleaned := strings.TrimSpace(stopwords.CleanString(token, "en", false))
+	if cleaned == "" {
+		return ""
+	}
+	stem, _ := snowball.Stem(cleaned, "english", true)
+	return stem
+}
@@ -1,8 +1,17 @@
 package models
 
 import (
+	"encoding/csv"
 	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
+
+	"go.uber.org/zap"
+
+	"core/utils"
 )
 
 type matrix map[string]map[string]int
@@ -12,7 +21,7 @@ func (m *Model) BuildMatrix(expected, predicted []string) (matrix, []string, err
 		return nil, nil, fmt.Errorf("Input slices are not equal length; expected length: %v, predicted length: %v", len(expected), len(predicted))
 	}
 
-	all := append(expected, predicted...)
+	all := append(append([]string{}, expected...), predicted...)
 	distinctAssignees := []string{}
 	j := 0
 	for i := 0; i < len(all); i++ {
@@ -86,13 +95,13 @@ func (m matrix) getClassFN(class string) float64 {
 func (m matrix) getPrecision(class string) float64 {
 	classTP := m.getClassTP(class)
 	classFP := m.getClassFP(class)
-	return Round(classTP / (classTP + classFP))
+	return Round(safeDivide(classTP, classTP+classFP))
 }
 
 func (m matrix) getRecall(class string) float64 {
 	classTP := m.getClassTP(class)
 	classFN := m.getClassFN(class)
-	return Round(classTP / (classTP + classFN))
+	return Round(safeDivide(classTP, classTP+classFN))
 }
 
 func (m matrix) getAccuracy() float64 {
@@ -106,7 +115,7 @@ func (m matrix) getAccuracy() float64 {
 			total += float64(m[columnHead][rowHead])
 		}
 	}
-	return Round(float64(correct) / float64(total))
+	return Round(safeDivide(correct, total))
 }
 
 func (m matrix) getTestCount() float64 {
@@ -133,17 +142,26 @@ func (m matrix) fillMatrix() matrix {
 }
 
 func (m matrix) getClassF1(class string) float64 {
-	p := m.getClassTP(class) / (m.getClassTP(class) + m.getClassFP(class))
-	r := m.getClassTP(class) / (m.getClassTP(class) + m.getClassFN(class))
-	output := (2 * p * r) / (p + r)
-	return output
+	p := safeDivide(m.getClassTP(class), m.getClassTP(class)+m.getClassFP(class))
+	r := safeDivide(m.getClassTP(class), m.getClassTP(class)+m.getClassFN(class))
+	return f1(p, r)
+}
+
+// safeDivide is n/d, or 0 when d is 0.
+func safeDivide(n, d float64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+func f1(precision, recall float64) float64 {
+	return safeDivide(2*precision*recall, precision+recall)
 }
 
 func (m matrix) classesEvaluation(classes []string) {
 	for i := 0; i < len(classes); i++ {
-		fmt.Println("Class:", classes[i], "\n", m.ClassSummary(classes[i]))
-		//TODO: Fix
-		//utils.ModelLog.Debug("Class: " + classes[i] + "\n" + m.ClassSummary(classes[i]))
+		utils.ModelLog.Debug("Class Evaluation", zap.String("Class", classes[i]), zap.String("Summary", m.ClassSummary(classes[i])))
 	}
 }
 
@@ -169,3 +187,241 @@ func (m matrix) FullSummary() string {
 	output := strings.Join(input, " ")
 	return output
 }
+
+// Confusion is the confusion matrix of the first prediction for each issue.
+// The ranked predictions are kept for the top-K metrics. Classes are the
+// expected and predicted assignees, sorted.
+type Confusion struct {
+	Classes     []string
+	counts      matrix
+	expected    []string
+	predictions [][]string
+}
+
+// NewConfusion builds the confusion matrix of the expected assignees and the
+// ranked predictions for each of them. An issue without predictions is a
+// false negative of its expected assignee.
+func NewConfusion(expected []string, predictions [][]string) (*Confusion, error) {
+	if len(expected) != len(predictions) {
+		return nil, fmt.Errorf("Input slices are not equal length; expected length: %v, predictions length: %v", len(expected), len(predictions))
+	}
+	c := &Confusion{Classes: []string{}, counts: make(matrix), expected: expected, predictions: predictions}
+	classes := make(map[string]bool)
+	for i := range expected {
+		top := ""
+		if len(predictions[i]) > 0 {
+			top = predictions[i][0]
+			classes[top] = true
+		}
+		classes[expected[i]] = true
+		if _, ok := c.counts[expected[i]]; !ok {
+			c.counts[expected[i]] = make(map[string]int)
+		}
+		c.counts[expected[i]][top]++
+	}
+	for class := range classes {
+		c.Classes = append(c.Classes, class)
+	}
+	sort.Strings(c.Classes)
+	return c, nil
+}
+
+// Count is the number of issues of the expected assignee whose first
+// prediction was the predicted assignee.
+func (c *Confusion) Count(expected, predicted string) int {
+	return c.counts[expected][predicted]
+}
+
+// ClassMetrics are the one-vs-rest results of an assignee. Support is the
+// number of issues the assignee was expected for.
+type ClassMetrics struct {
+	Class          string  `json:"class"`
+	Support        int     `json:"support"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// Metrics summarize a confusion matrix. Accuracy and the class metrics use
+// the first prediction; TopK maps each cut-off K to the share of issues whose
+// assignee was among the first K predictions. Coverage is the share of issues
+// with at least one prediction. Every ratio with a zero denominator is 0.
+type Metrics struct {
+	Accuracy       float64         `json:"accuracy"`
+	TopK           map[int]float64 `json:"top_k"`
+	MRR            float64         `json:"mrr"`
+	Coverage       float64         `json:"coverage"`
+	MacroPrecision float64         `json:"macro_precision"`
+	MacroRecall    float64         `json:"macro_recall"`
+	MacroF1        float64         `json:"macro_f1"`
+	MicroPrecision float64         `json:"micro_precision"`
+	MicroRecall    float64         `json:"micro_recall"`
+	MicroF1        float64         `json:"micro_f1"`
+	WeightedF1     float64         `json:"weighted_f1"`
+	Kappa          float64         `json:"kappa"`
+	Classes        []ClassMetrics  `json:"classes"`
+}
+
+// Metrics computes the per-class and aggregate metrics with the top-K
+// accuracy for each of ks.
+func (c *Confusion) Metrics(ks []int) Metrics {
+	total := float64(len(c.expected))
+	metrics := Metrics{TopK: make(map[int]float64), Classes: []ClassMetrics{}}
+
+	hits := make(map[int]int)
+	reciprocal, covered := 0.0, 0
+	for i, expected := range c.expected {
+		if len(c.predictions[i]) > 0 {
+			covered++
+		}
+		for rank, prediction := range c.predictions[i] {
+			if prediction != expected {
+				continue
+			}
+			reciprocal += 1 / float64(rank+1)
+			for _, k := range ks {
+				if rank < k {
+					hits[k]++
+				}
+			}
+			break
+		}
+	}
+	for _, k := range ks {
+		metrics.TopK[k] = safeDivide(float64(hits[k]), total)
+	}
+	metrics.MRR = safeDivide(reciprocal, total)
+	metrics.Coverage = safeDivide(float64(covered), total)
+
+	var tp, fp, fn, chance float64
+	for _, class := range c.Classes {
+		classTP := c.counts.getClassTP(class)
+		classFP := c.counts.getClassFP(class)
+		classFN := c.counts.getClassFN(class)
+		support := classTP + classFN
+		precision := safeDivide(classTP, classTP+classFP)
+		recall := safeDivide(classTP, support)
+		classF1 := f1(precision, recall)
+		metrics.Classes = append(metrics.Classes, ClassMetrics{
+			Class:          class,
+			Support:        int(support),
+			TruePositives:  int(classTP),
+			FalsePositives: int(classFP),
+			FalseNegatives: int(classFN),
+			Precision:      precision,
+			Recall:         recall,
+			F1:             classF1,
+		})
+		metrics.MacroPrecision += precision
+		metrics.MacroRecall += recall
+		metrics.MacroF1 += classF1
+		metrics.WeightedF1 += classF1 * support
+		tp, fp, fn = tp+classTP, fp+classFP, fn+classFN
+		chance += support * (classTP + classFP)
+	}
+	classes := float64(len(c.Classes))
+	metrics.MacroPrecision = safeDivide(metrics.MacroPrecision, classes)
+	metrics.MacroRecall = safeDivide(metrics.MacroRecall, classes)
+	metrics.MacroF1 = safeDivide(metrics.MacroF1, classes)
+	metrics.WeightedF1 = safeDivide(metrics.WeightedF1, total)
+	metrics.MicroPrecision = safeDivide(tp, tp+fp)
+	metrics.MicroRecall = safeDivide(tp, tp+fn)
+	metrics.MicroF1 = f1(metrics.MicroPrecision, metrics.MicroRecall)
+	metrics.Accuracy = safeDivide(tp, total)
+
+	// Cohen's kappa compares the observed agreement with the agreement
+	// expected by chance from the row and column totals.
+	chance = safeDivide(chance, total*total)
+	metrics.Kappa = safeDivide(metrics.Accuracy-chance, 1-chance)
+	return metrics
+}
+
+// WriteCSV writes the matrix with a row per expected assignee and a column
+// per first prediction; the last column counts issues without predictions.
+func (c *Confusion) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string{"expected"}, c.Classes...), "none")); err != nil {
+		return err
+	}
+	for _, expected := range c.Classes {
+		row := []string{expected}
+		for _, predicted := range c.columns() {
+			row = append(row, strconv.Itoa(c.Count(expected, predicted)))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// columns are the predicted assignees followed by "" for no prediction.
+func (c *Confusion) columns() []string {
+	return append(append([]string{}, c.Classes...), "")
+}
+
+type heatmapCell struct {
+	Count int
+	Share float64
+}
+
+type heatmapRow struct {
+	Class string
+	Cells []heatmapCell
+}
+
+var heatmap = template.Must(template.New("heatmap").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+th { background: #f5f5f5; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Accuracy {{printf "%.4f" .Metrics.Accuracy}}, macro F1 {{printf "%.4f" .Metrics.MacroF1}}, weighted F1 {{printf "%.4f" .Metrics.WeightedF1}}, kappa {{printf "%.4f" .Metrics.Kappa}}, coverage {{printf "%.4f" .Metrics.Coverage}}.</p>
+<table>
+<tr><th>expected \ predicted</th>{{range .Classes}}<th>{{.}}</th>{{end}}<th>none</th></tr>
+{{range .Rows}}<tr><th>{{.Class}}</th>{{range .Cells}}<td style="background-color: rgba(200, 40, 40, {{printf "%.2f" .Share}})">{{.Count}}</td>{{end}}</tr>
+{{end}}</table>
+<table>
+<tr><th>class</th><th>support</th><th>precision</th><th>recall</th><th>F1</th></tr>
+{{range .Metrics.Classes}}<tr><th>{{.Class}}</th><td>{{.Support}}</td><td>{{printf "%.4f" .Precision}}</td><td>{{printf "%.4f" .Recall}}</td><td>{{printf "%.4f" .F1}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained HTML report with the matrix as a heatmap,
+// each cell shaded by its share of the expected assignee's issues, followed
+// by the class metrics.
+func (c *Confusion) WriteHTML(w io.Writer, title string) error {
+	rows := []heatmapRow{}
+	for _, expected := range c.Classes {
+		row := heatmapRow{Class: expected}
+		support := 0
+		for _, count := range c.counts[expected] {
+			support += count
+		}
+		for _, predicted := range c.columns() {
+			count := c.Count(expected, predicted)
+			row.Cells = append(row.Cells, heatmapCell{Count: count, Share: safeDivide(float64(count), float64(support))})
+		}
+		rows = append(rows, row)
+	}
+	return heatmap.Execute(w, struct {
+		Title   string
+		Classes []string
+		Rows    []heatmapRow
+		Metrics Metrics
+	}{title, c.Classes, rows, c.Metrics(nil)})
+}
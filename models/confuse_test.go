@@ -1,6 +1,9 @@
 package models
 
 import (
+	"bytes"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -116,3 +119,105 @@ func TestBuildMatrix(t *testing.T) {
 		)
 	}
 }
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestConfusionMetrics(t *testing.T) {
+	predictions := [][]string{}
+	for i := range pre {
+		predictions = append(predictions, []string{pre[i]})
+	}
+	confusion, err := NewConfusion(exp, predictions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := confusion.Metrics([]int{1})
+
+	expected := map[string][2]float64{
+		"Accuracy":   {2.0 / 9, result.Accuracy},
+		"TopK":       {2.0 / 9, result.TopK[1]},
+		"Coverage":   {1, result.Coverage},
+		"MacroF1":    {13.0 / 63, result.MacroF1},
+		"MicroF1":    {2.0 / 9, result.MicroF1},
+		"WeightedF1": {13.0 / 63, result.WeightedF1},
+		"Kappa":      {-1.0 / 6, result.Kappa},
+		"MikeF1":     {2.0 / 7, result.Classes[1].F1},
+		"WozF1":      {0, result.Classes[2].F1},
+	}
+	for name, values := range expected {
+		if !near(values[0], values[1]) {
+			t.Error(
+				"\nMETRIC MISCALCULATED: "+name,
+				"\nEXPECTED:  ", values[0],
+				"\nACTUAL:    ", values[1])
+		}
+	}
+	if confusion.Count("Woz", "Mike") != 2 {
+		t.Error(
+			"\nMATRIX MISCOUNT",
+			"\nEXPECTED:  ", 2,
+			"\nACTUAL:    ", confusion.Count("Woz", "Mike"))
+	}
+
+	if _, err := NewConfusion(exp, predictions[1:]); err == nil {
+		t.Error("\nUNEQUAL INPUT ACCEPTED")
+	}
+}
+
+func TestConfusionZeroDenominators(t *testing.T) {
+	confusion, _ := NewConfusion([]string{"Yoda", "Yoda"}, [][]string{nil, {"Dooku", "Yoda"}})
+	result := confusion.Metrics([]int{1, 3})
+	values := []float64{result.Accuracy, result.MacroF1, result.MicroF1, result.WeightedF1, result.Kappa, result.TopK[1]}
+	for _, class := range result.Classes {
+		values = append(values, class.Precision, class.Recall, class.F1)
+	}
+	for _, value := range values {
+		if math.IsNaN(value) || value != 0 {
+			t.Error(
+				"\nZERO DENOMINATOR NOT HANDLED",
+				"\nACTUAL:    ", result)
+			break
+		}
+	}
+	if result.TopK[3] != 0.5 || result.Coverage != 0.5 || result.MRR != 0.25 {
+		t.Error(
+			"\nRANKED METRICS MISCALCULATED",
+			"\nACTUAL:    ", result.TopK, result.Coverage, result.MRR)
+	}
+
+	model := Model{}
+	matrix, _, _ := model.BuildMatrix([]string{"Yoda"}, []string{"Dooku"})
+	if f1 := matrix.getClassF1("Yoda"); math.IsNaN(f1) {
+		t.Error(
+			"\nCLASS F1 IS NAN",
+			"\nACTUAL:    ", f1)
+	}
+}
+
+func TestConfusionExport(t *testing.T) {
+	confusion, _ := NewConfusion([]string{"Yoda", "Yoda", "Dooku"}, [][]string{{"Yoda"}, nil, {"Yoda"}})
+
+	var buffer bytes.Buffer
+	if err := confusion.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	if csv := "expected,Dooku,Yoda,none\nDooku,0,1,0\nYoda,0,1,1\n"; buffer.String() != csv {
+		t.Error(
+			"\nCSV EXPORT INCORRECT",
+			"\nEXPECTED:  ", csv,
+			"\nACTUAL:    ", buffer.String())
+	}
+
+	buffer.Reset()
+	if err := confusion.WriteHTML(&buffer, "Jedi <Council>"); err != nil {
+		t.Fatal(err)
+	}
+	html := buffer.String()
+	if !strings.Contains(html, "<title>Jedi &lt;Council&gt;</title>") || !strings.Contains(html, "rgba(200, 40, 40, 0.50)") || strings.Contains(html, "<script") {
+		t.Error(
+			"\nHTML REPORT INCORRECT",
+			"\nACTUAL:    ", html)
+	}
+}
@@ -0,0 +1,136 @@
+package evaluation
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"core/models"
+	"core/pipeline/gateway/conflation"
+)
+
+// Chronological replays the issue history the way the backend sees it. Each
+// issue is predicted when it is created, by a model that has only learned
+// the issues closed before then. As in the backend, only conflated issues
+// with an assignee are learned. Until the model is bootstrapped every batch
+// relearns all closed issues with Learn; later batches use OnlineLearn. The
+// results are reported per time window of issue creation.
+type Chronological struct {
+	// New must return an untrained algorithm.
+	New func() models.Algorithm
+	// K are the top-K accuracy cut-offs; DefaultK if empty.
+	K []int
+	// Window is the width of each reported window; a week if zero.
+	Window time.Duration
+	// Warmup is the number of issues the model must have learned before
+	// its predictions are scored. At least one issue is always required.
+	Warmup int
+}
+
+// event is the creation (predict) or resolution (learn) of an issue.
+type event struct {
+	at    time.Time
+	learn bool
+	issue conflation.ExpandedIssue
+}
+
+// Run replays the issues and reports a fold per window. A fold's Train is
+// the number of issues learned when its first issue was predicted. The input
+// slice is never modified.
+func (c Chronological) Run(issues []conflation.ExpandedIssue) Report {
+	ks := c.K
+	if len(ks) == 0 {
+		ks = DefaultK
+	}
+	window := c.Window
+	if window <= 0 {
+		window = 7 * 24 * time.Hour
+	}
+	report := Report{Splitter: fmt.Sprintf("chronological-%s", window), K: ks, Folds: []Fold{}}
+
+	events := timeline(issues)
+	if len(events) == 0 {
+		report.summarize()
+		return report
+	}
+	model := models.Model{Algorithm: c.New()}
+	pending := []conflation.ExpandedIssue{}
+	history := []conflation.ExpandedIssue{}
+	learned := 0
+	windows := []time.Time{}
+	trained := map[time.Time]int{}
+	outcomes := map[time.Time][]outcome{}
+	for _, e := range events {
+		if e.learn {
+			pending = append(pending, e.issue)
+			continue
+		}
+		if len(pending) > 0 {
+			// NOTE: Learn needs enough assignees to bootstrap the model and
+			// OnlineLearn needs a bootstrapped model.
+			if !model.IsBootstrapped() {
+				history = append(history, pending...)
+				model.Learn(history)
+			} else {
+				model.OnlineLearn(pending)
+			}
+			learned += len(pending)
+			pending = []conflation.ExpandedIssue{}
+		}
+		expected, ok := Assignee(e.issue)
+		if !ok || learned == 0 || learned < c.Warmup {
+			continue
+		}
+		start := events[0].at.Add(e.at.Sub(events[0].at) / window * window)
+		if _, ok := trained[start]; !ok {
+			windows = append(windows, start)
+			trained[start] = learned
+		}
+		outcomes[start] = append(outcomes[start], outcome{expected: expected, predictions: predict(&model, e.issue)})
+	}
+
+	for _, start := range windows {
+		fold := score(outcomes[start], ks)
+		fold.Name, fold.Train = start.UTC().Format(time.RFC3339), trained[start]
+		report.Folds = append(report.Folds, fold)
+	}
+	report.summarize()
+	return report
+}
+
+// timeline orders the creation and resolution of the issues. On equal times
+// predictions come first, so an issue is never predicted with data from the
+// instant it was created. Closed issues the backend would not train on are
+// never learned.
+func timeline(issues []conflation.ExpandedIssue) []event {
+	events := []event{}
+	for _, issue := range issues {
+		if created, ok := CreatedAt(issue); ok {
+			events = append(events, event{at: created, issue: issue})
+		}
+		if _, ok := Assignee(issue); !ok || !issue.Conflate {
+			continue
+		}
+		if closed, ok := ClosedAt(issue); ok {
+			events = append(events, event{at: closed, learn: true, issue: issue})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return !events[i].learn && events[j].learn
+	})
+	return events
+}
+
+// CreatedAt returns when the issue, or else its pull request, was opened.
+func CreatedAt(issue conflation.ExpandedIssue) (time.Time, bool) {
+	if issue.Issue.CreatedAt != nil && !issue.Issue.CreatedAt.IsZero() {
+		return *issue.Issue.CreatedAt, true
+	}
+	if issue.PullRequest.CreatedAt != nil && !issue.PullRequest.CreatedAt.IsZero() {
+		return *issue.PullRequest.CreatedAt, true
+	}
+	return time.Time{}, false
+}
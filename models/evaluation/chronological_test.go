@@ -0,0 +1,141 @@
+package evaluation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/models"
+	"core/models/bhattacharya"
+	"core/pipeline/gateway/conflation"
+)
+
+// countingAlgorithm counts how the replay trains it.
+type countingAlgorithm struct {
+	titleAlgorithm
+	learns, onlineLearns int
+}
+
+func (c *countingAlgorithm) Learn(input []conflation.ExpandedIssue) {
+	c.learns++
+	c.titleAlgorithm.Learn(input)
+}
+
+func (c *countingAlgorithm) OnlineLearn(input []conflation.ExpandedIssue) {
+	c.onlineLearns++
+	c.titleAlgorithm.OnlineLearn(input)
+}
+
+func buildTimedIssue(number int, title, assignee string, created, closed time.Duration) conflation.ExpandedIssue {
+	issue := buildIssue(number, title, assignee, start.Add(closed))
+	opened := start.Add(created)
+	issue.Issue.CreatedAt = &opened
+	issue.Conflate = true
+	if closed < 0 {
+		issue.Issue.ClosedAt = nil
+	}
+	return issue
+}
+
+func TestChronological(t *testing.T) {
+	day := 24 * time.Hour
+	issues := []conflation.ExpandedIssue{
+		// Closed long after it was opened; never known when predicting 6.
+		buildTimedIssue(5, "Hyperdrive", "Chewbacca", 4*day, 20*day),
+		buildTimedIssue(6, "Hyperdrive", "Chewbacca", 5*day, 21*day),
+		// Nothing is learned before these are opened, so they are not
+		// scored; 3 is predicted before 1 closes at the same instant.
+		buildTimedIssue(1, "Blaster", "Han", 0, 2*day),
+		buildTimedIssue(2, "Blaster", "Han", day, 3*day),
+		buildTimedIssue(3, "Blaster", "Han", 2*day, 9*day),
+		buildTimedIssue(4, "Blaster", "Han", 8*day, 10*day),
+		buildTimedIssue(7, "Blaster", "Han", 9*day, -1),
+		buildTimedIssue(8, "Blaster", "Han", 11*day, -1),
+	}
+	algorithm := &countingAlgorithm{}
+	report := Chronological{
+		New:    func() models.Algorithm { return algorithm },
+		Window: 7 * day,
+	}.Run(issues)
+
+	if len(report.Folds) != 2 {
+		t.Fatal("\nUNEXPECTED WINDOWS", "\nEXPECTED: ", 2, "\nACTUAL:   ", len(report.Folds))
+	}
+	first, second := report.Folds[0], report.Folds[1]
+	if first.Name != start.Format(time.RFC3339) || first.Test != 2 || first.Train != 2 || first.TopK[1] != 0 {
+		t.Error("\nFIRST WINDOW INCORRECT", "\nACTUAL:   ", first.Name, first.Test, first.Train, first.TopK)
+	}
+	if second.Test != 3 || second.Train != 2 || second.TopK[1] != 1 {
+		t.Error("\nSECOND WINDOW INCORRECT", "\nACTUAL:   ", second.Name, second.Test, second.Train, second.TopK)
+	}
+	if report.Overall.Test != 5 || report.Overall.TopK[1] != 0.6 {
+		t.Error("\nOVERALL INCORRECT", "\nACTUAL:   ", report.Overall.Test, report.Overall.TopK)
+	}
+	if algorithm.learns != 1 || algorithm.onlineLearns != 1 {
+		t.Error(
+			"\nUNEXPECTED TRAINING",
+			"\nEXPECTED: ", 1, 1,
+			"\nACTUAL:   ", algorithm.learns, algorithm.onlineLearns,
+		)
+	}
+	if issues[0].Issue.GetNumber() != 5 {
+		t.Error("\nINPUT ISSUES MODIFIED", "\nACTUAL:   ", numbers(issues))
+	}
+}
+
+func TestChronologicalUntrainable(t *testing.T) {
+	day := 24 * time.Hour
+	unconflated := buildTimedIssue(3, "Hyperdrive", "Chewbacca", 0, day)
+	unconflated.Conflate = false
+	issues := []conflation.ExpandedIssue{
+		buildTimedIssue(1, "Blaster", "Han", 0, day),
+		buildTimedIssue(2, "Hyperdrive", "", 0, day),
+		unconflated,
+		buildTimedIssue(4, "Blaster", "Han", 2*day, -1),
+		buildTimedIssue(5, "Hyperdrive", "Chewbacca", 2*day, -1),
+	}
+	report := Chronological{New: newTitleAlgorithm, Window: 7 * day}.Run(issues)
+
+	if len(report.Folds) != 1 || report.Folds[0].Train != 1 {
+		t.Fatal("\nUNTRAINABLE ISSUES LEARNED", "\nEXPECTED: ", 1, "\nACTUAL:   ", report.Folds)
+	}
+	if report.Overall.Test != 2 || report.Overall.TopK[1] != 0.5 {
+		t.Error(
+			"\nUNEXPECTED PREDICTIONS",
+			"\nEXPECTED: ", 2, 0.5,
+			"\nACTUAL:   ", report.Overall.Test, report.Overall.TopK,
+		)
+	}
+}
+
+func TestChronologicalNBModel(t *testing.T) {
+	day := 24 * time.Hour
+	issue := func(number int, body, assignee string, created, closed time.Duration) conflation.ExpandedIssue {
+		issue := buildTimedIssue(number, "", assignee, created, closed)
+		issue.Issue.Body = github.String(body)
+		issue.Issue.URL = github.String("https://api.github.com/repos/rebellion/falcon/issues/1")
+		return issue
+	}
+	// Han resolves the first two issues alone, which is not enough for the
+	// model to bootstrap.
+	issues := []conflation.ExpandedIssue{
+		issue(1, "blaster jammed", "Han", 0, day),
+		issue(2, "blaster overheats", "Han", 2*day, 3*day),
+		issue(3, "hyperdrive motivator", "Chewbacca", 4*day, 5*day),
+		issue(4, "blaster jammed again", "Han", 6*day, 7*day),
+		issue(5, "hyperdrive motivator broken", "Chewbacca", 8*day, -1),
+	}
+	report := Chronological{
+		New:    func() models.Algorithm { return &bhattacharya.NBModel{} },
+		Window: 30 * day,
+	}.Run(issues)
+
+	if report.Overall.Test != 4 || report.Overall.TopK[1] != 0.5 {
+		t.Error(
+			"\nNBMODEL NOT REPLAYED",
+			"\nEXPECTED: ", 4, 0.5,
+			"\nACTUAL:   ", report.Overall.Test, report.Overall.TopK,
+		)
+	}
+}
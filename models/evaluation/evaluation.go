@@ -0,0 +1,76 @@
+package evaluation
+
+import (
+	"core/models"
+	"core/pipeline/gateway/conflation"
+)
+
+// DefaultK are the cut-offs reported for top-K accuracy.
+var DefaultK = []int{1, 3, 5}
+
+// Evaluator backtests an algorithm. New must return an untrained algorithm;
+// a fresh one is trained for every split so folds never share state.
+type Evaluator struct {
+	New func() models.Algorithm
+	// K are the top-K accuracy cut-offs; DefaultK if empty.
+	K []int
+}
+
+// Run evaluates the algorithm on each split of the issues. Issues without
+// an assignee (or pull request author) are left out of the test sets. The
+// input slice is never modified.
+func (e Evaluator) Run(issues []conflation.ExpandedIssue, splitter Splitter) Report {
+	ks := e.K
+	if len(ks) == 0 {
+		ks = DefaultK
+	}
+	report := Report{Splitter: splitter.Name(), K: ks, Folds: []Fold{}}
+	for _, split := range splitter.Splits(issues) {
+		model := models.Model{Algorithm: e.New()}
+		model.Learn(split.Train)
+		fold := evaluate(&model, split.Test, ks)
+		fold.Name, fold.Train = split.Name, len(split.Train)
+		report.Folds = append(report.Folds, fold)
+	}
+	report.summarize()
+	return report
+}
+
+// Assignee returns the login an issue was resolved by: its first assignee,
+// or the author of the resolving pull request.
+func Assignee(issue conflation.ExpandedIssue) (string, bool) {
+	if len(issue.Issue.Assignees) > 0 && issue.Issue.Assignees[0].Login != nil {
+		return *issue.Issue.Assignees[0].Login, true
+	}
+	if issue.PullRequest.User != nil && issue.PullRequest.User.Login != nil {
+		return *issue.PullRequest.User.Login, true
+	}
+	return "", false
+}
+
+// evaluate predicts each test issue with the trained model. A model that
+// could not bootstrap (e.g. too few assignees) predicts nothing.
+func evaluate(model *models.Model, test []conflation.ExpandedIssue, ks []int) Fold {
+	outcomes := []outcome{}
+	for i := range test {
+		expected, ok := Assignee(test[i])
+		if !ok {
+			continue
+		}
+		outcomes = append(outcomes, outcome{expected: expected, predictions: predict(model, test[i])})
+	}
+	return score(outcomes, ks)
+}
+
+func predict(model *models.Model, issue conflation.ExpandedIssue) []string {
+	if !model.IsBootstrapped() {
+		return nil
+	}
+	return model.Predict(issue)
+}
+
+// outcome is the ranked prediction for an issue with a known assignee.
+type outcome struct {
+	expected    string
+	predictions []string
+}
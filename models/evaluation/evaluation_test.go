@@ -0,0 +1,196 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/models"
+	"core/pipeline/gateway/conflation"
+)
+
+// titleAlgorithm predicts the assignees it has seen resolve an issue with the
+// same title, most recent first.
+type titleAlgorithm struct {
+	assignees map[string][]string
+}
+
+func (t *titleAlgorithm) IsBootstrapped() bool { return t.assignees != nil }
+
+func (t *titleAlgorithm) Learn(input []conflation.ExpandedIssue) {
+	t.assignees = make(map[string][]string)
+	t.OnlineLearn(input)
+}
+
+func (t *titleAlgorithm) OnlineLearn(input []conflation.ExpandedIssue) {
+	for _, issue := range input {
+		if assignee, ok := Assignee(issue); ok {
+			title := issue.Issue.GetTitle()
+			t.assignees[title] = append([]string{assignee}, t.assignees[title]...)
+		}
+	}
+}
+
+func (t *titleAlgorithm) Predict(input conflation.ExpandedIssue) []string {
+	return t.assignees[input.Issue.GetTitle()]
+}
+
+func (t *titleAlgorithm) GenerateRecoveryFile(path string) error { return nil }
+func (t *titleAlgorithm) RecoverModelFromFile(path string) error { return nil }
+
+func newTitleAlgorithm() models.Algorithm { return &titleAlgorithm{} }
+
+var start = time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC)
+
+func buildIssue(number int, title, assignee string, closed time.Time) conflation.ExpandedIssue {
+	issue := github.Issue{Number: &number, Title: &title, ClosedAt: &closed}
+	if assignee != "" {
+		issue.Assignees = []*github.User{{Login: &assignee}}
+	}
+	return conflation.ExpandedIssue{Issue: conflation.CRIssue{Issue: issue}}
+}
+
+// buildIssues returns ten issues closed in reverse order of their numbers.
+func buildIssues() []conflation.ExpandedIssue {
+	issues := []conflation.ExpandedIssue{}
+	for i := 0; i < 10; i++ {
+		title, assignee := "Hyperdrive", "Chewbacca"
+		if i%2 == 0 {
+			title, assignee = "Blaster", "Han"
+		}
+		issues = append(issues, buildIssue(i, title, assignee, start.Add(time.Duration(10-i)*time.Hour)))
+	}
+	return issues
+}
+
+func numbers(issues []conflation.ExpandedIssue) []int {
+	n := []int{}
+	for _, issue := range issues {
+		n = append(n, issue.Issue.GetNumber())
+	}
+	return n
+}
+
+func TestSplitters(t *testing.T) {
+	issues := buildIssues()
+	original := numbers(issues)
+	cases := []struct {
+		splitter Splitter
+		train    []int
+		test     [][]int
+	}{
+		{KFold{K: 5}, []int{8, 8, 8, 8, 8}, [][]int{{0, 1}, {2, 3}, {4, 5}, {6, 7}, {8, 9}}},
+		{Incremental{Steps: 5}, []int{2, 4, 6, 8}, [][]int{{7, 6}, {5, 4}, {3, 2}, {1, 0}}},
+		{RollingWindow{Train: 4, Test: 3}, []int{4, 4}, [][]int{{5, 4, 3}, {2, 1, 0}}},
+	}
+	for _, c := range cases {
+		splits := c.splitter.Splits(issues)
+		if len(splits) != len(c.test) {
+			t.Error("\nUNEXPECTED SPLIT COUNT", "\nSPLITTER: ", c.splitter.Name(), "\nEXPECTED: ", len(c.test), "\nACTUAL:   ", len(splits))
+			continue
+		}
+		for i, split := range splits {
+			if len(split.Train) != c.train[i] || !equal(numbers(split.Test), c.test[i]) {
+				t.Error(
+					"\nUNEXPECTED SPLIT",
+					"\nSPLITTER: ", c.splitter.Name(), split.Name,
+					"\nEXPECTED: ", c.train[i], c.test[i],
+					"\nACTUAL:   ", len(split.Train), numbers(split.Test),
+				)
+			}
+		}
+	}
+	if !equal(numbers(issues), original) {
+		t.Error("\nINPUT ISSUES MODIFIED", "\nEXPECTED: ", original, "\nACTUAL:   ", numbers(issues))
+	}
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestScore(t *testing.T) {
+	outcomes := []outcome{
+		{expected: "Luke", predictions: []string{"Luke", "Leia"}},
+		{expected: "Leia", predictions: []string{"Luke", "Leia", "Han"}},
+		{expected: "Han", predictions: []string{"Luke", "Leia", "Lando", "Chewbacca", "Han"}},
+		{expected: "Han", predictions: nil},
+	}
+	fold := score(outcomes, DefaultK)
+	if fold.TopK[1] != 0.25 || fold.TopK[3] != 0.5 || fold.TopK[5] != 0.75 {
+		t.Error("\nTOP-K ACCURACY INCORRECT", "\nACTUAL:   ", fold.TopK)
+	}
+	if mrr := (1 + 0.5 + 0.2) / 4; fold.MRR != mrr {
+		t.Error("\nMRR INCORRECT", "\nEXPECTED: ", mrr, "\nACTUAL:   ", fold.MRR)
+	}
+	expected := []models.ClassMetrics{
+		{Class: "Han", Support: 2},
+		{Class: "Leia", Support: 1},
+		{Class: "Luke", Support: 1, Precision: 1.0 / 3, Recall: 1, F1: 0.5},
+	}
+	if len(fold.Classes) != len(expected) {
+		t.Fatal("\nCLASSES MISSING", "\nACTUAL:   ", fold.Classes)
+	}
+	for i := range expected {
+		actual := fold.Classes[i]
+		if actual.Class != expected[i].Class || actual.Support != expected[i].Support || !near(actual.Precision, expected[i].Precision) || !near(actual.Recall, expected[i].Recall) || !near(actual.F1, expected[i].F1) {
+			t.Error("\nCLASS METRICS INCORRECT", "\nEXPECTED: ", expected[i], "\nACTUAL:   ", actual)
+		}
+	}
+	if empty := score(nil, DefaultK); empty.MRR != 0 || empty.TopK[1] != 0 {
+		t.Error("\nEMPTY FOLD NOT ZERO", "\nACTUAL:   ", empty)
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
+
+func TestRun(t *testing.T) {
+	// The unassigned issue is resolved last and is left out of the final test.
+	issues := append(buildIssues(), buildIssue(10, "Blaster", "", start.Add(11*time.Hour)))
+	report := Evaluator{New: newTitleAlgorithm}.Run(issues, Incremental{Steps: 5})
+	if len(report.Folds) != 4 || report.Overall.Test != 8 {
+		t.Fatal("\nUNEXPECTED FOLDS", "\nACTUAL:   ", len(report.Folds), report.Overall.Test)
+	}
+	// The first step only trains on two issues but each title is seen.
+	if report.Overall.TopK[1] != 1 || report.Overall.MRR != 1 {
+		t.Error("\nOVERALL ACCURACY INCORRECT", "\nACTUAL:   ", report.Overall.TopK, report.Overall.MRR)
+	}
+
+	var buffer bytes.Buffer
+	if err := report.WriteCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if lines[0] != "fold,train,test,top1,top3,top5,mrr,coverage,macro_f1,weighted_f1,kappa" || lines[len(lines)-1] != "overall,5,8,1.0000,1.0000,1.0000,1.0000,1.0000,1.0000,1.0000,1.0000" {
+		t.Error("\nCSV INCORRECT", "\nACTUAL:   ", buffer.String())
+	}
+	buffer.Reset()
+	if err := report.WriteClassCSV(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "overall,Han,4,1.0000,1.0000,1.0000") {
+		t.Error("\nCLASS CSV INCORRECT", "\nACTUAL:   ", buffer.String())
+	}
+	buffer.Reset()
+	if err := report.WriteJSON(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	decoded := Report{}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil || decoded.Overall.TopK[3] != 1 {
+		t.Error("\nJSON INCORRECT", "\nACTUAL:   ", buffer.String())
+	}
+}
@@ -0,0 +1,130 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"core/models"
+)
+
+// Report holds the results of every fold and of all folds pooled together.
+type Report struct {
+	Splitter string `json:"splitter"`
+	K        []int  `json:"k"`
+	Folds    []Fold `json:"folds"`
+	Overall  Fold   `json:"overall"`
+}
+
+// Fold holds the results of a split, computed from the confusion matrix of
+// its test issues.
+type Fold struct {
+	Name  string `json:"name"`
+	Train int    `json:"train"`
+	Test  int    `json:"test"`
+	models.Metrics
+
+	outcomes []outcome
+}
+
+func score(outcomes []outcome, ks []int) Fold {
+	if outcomes == nil {
+		outcomes = []outcome{}
+	}
+	fold := Fold{Test: len(outcomes), outcomes: outcomes}
+	fold.Metrics = fold.Confusion().Metrics(ks)
+	return fold
+}
+
+// Confusion returns the confusion matrix of the fold's test issues, for
+// export as CSV or HTML. It is nil for a decoded report.
+func (f Fold) Confusion() *models.Confusion {
+	if f.outcomes == nil {
+		return nil
+	}
+	expected := make([]string, len(f.outcomes))
+	predictions := make([][]string, len(f.outcomes))
+	for i, o := range f.outcomes {
+		expected[i], predictions[i] = o.expected, o.predictions
+	}
+	// The slices always have equal lengths.
+	confusion, _ := models.NewConfusion(expected, predictions)
+	return confusion
+}
+
+func (r *Report) summarize() {
+	outcomes := []outcome{}
+	train := 0
+	for _, fold := range r.Folds {
+		outcomes = append(outcomes, fold.outcomes...)
+		train += fold.Train
+	}
+	r.Overall = score(outcomes, r.K)
+	r.Overall.Name = "overall"
+	if len(r.Folds) > 0 {
+		r.Overall.Train = train / len(r.Folds)
+	}
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes a row per fold, and a final overall row, with the top-K
+// accuracies, MRR and aggregate metrics.
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"fold", "train", "test"}
+	for _, k := range r.K {
+		header = append(header, fmt.Sprintf("top%d", k))
+	}
+	header = append(header, "mrr", "coverage", "macro_f1", "weighted_f1", "kappa")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, fold := range r.all() {
+		row := []string{fold.Name, strconv.Itoa(fold.Train), strconv.Itoa(fold.Test)}
+		for _, k := range r.K {
+			row = append(row, formatFloat(fold.TopK[k]))
+		}
+		row = append(row, formatFloat(fold.MRR), formatFloat(fold.Coverage), formatFloat(fold.MacroF1), formatFloat(fold.WeightedF1), formatFloat(fold.Kappa))
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteClassCSV writes a row per fold and class with its precision, recall
+// and F1.
+func (r Report) WriteClassCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"fold", "class", "support", "precision", "recall", "f1"}); err != nil {
+		return err
+	}
+	for _, fold := range r.all() {
+		for _, class := range fold.Classes {
+			row := []string{fold.Name, class.Class, strconv.Itoa(class.Support), formatFloat(class.Precision), formatFloat(class.Recall), formatFloat(class.F1)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// all returns the folds followed by the overall results.
+func (r Report) all() []Fold {
+	return append(append([]Fold{}, r.Folds...), r.Overall)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
@@ -0,0 +1,143 @@
+package evaluation
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"core/pipeline/gateway/conflation"
+)
+
+// Split is a train/test division of the issues.
+type Split struct {
+	Name  string
+	Train []conflation.ExpandedIssue
+	Test  []conflation.ExpandedIssue
+}
+
+// Splitter divides issues into splits. Splits are new slices; the input is
+// never modified.
+type Splitter interface {
+	Name() string
+	Splits(issues []conflation.ExpandedIssue) []Split
+}
+
+// KFold tests on each of K contiguous chunks of the issues in turn, training
+// on the others. Shuffle the issues first for a randomized k-fold.
+type KFold struct {
+	K int
+}
+
+func (k KFold) Name() string {
+	return fmt.Sprintf("%d-fold", k.K)
+}
+
+func (k KFold) Splits(issues []conflation.ExpandedIssue) []Split {
+	splits := []Split{}
+	if k.K < 2 || len(issues) < k.K {
+		return splits
+	}
+	for i := 0; i < k.K; i++ {
+		start, end := i*len(issues)/k.K, (i+1)*len(issues)/k.K
+		train := make([]conflation.ExpandedIssue, 0, len(issues)-(end-start))
+		train = append(train, issues[:start]...)
+		train = append(train, issues[end:]...)
+		splits = append(splits, Split{
+			Name:  fmt.Sprintf("fold-%d", i+1),
+			Train: train,
+			Test:  copyIssues(issues[start:end]),
+		})
+	}
+	return splits
+}
+
+// Incremental orders the issues by resolution time and divides them into
+// Steps chunks; each split trains on every chunk before one and tests on it.
+type Incremental struct {
+	Steps int
+}
+
+func (n Incremental) Name() string {
+	return fmt.Sprintf("incremental-%d", n.Steps)
+}
+
+func (n Incremental) Splits(issues []conflation.ExpandedIssue) []Split {
+	splits := []Split{}
+	if n.Steps < 2 || len(issues) < n.Steps {
+		return splits
+	}
+	ordered := ByClosedAt(issues)
+	for i := 1; i < n.Steps; i++ {
+		start, end := i*len(ordered)/n.Steps, (i+1)*len(ordered)/n.Steps
+		splits = append(splits, Split{
+			Name:  fmt.Sprintf("step-%d", i),
+			Train: copyIssues(ordered[:start]),
+			Test:  copyIssues(ordered[start:end]),
+		})
+	}
+	return splits
+}
+
+// RollingWindow orders the issues by resolution time and trains on a window
+// of the Train most recent issues before testing on the next Test issues;
+// the window then slides forward by Test issues.
+type RollingWindow struct {
+	Train int
+	Test  int
+}
+
+func (w RollingWindow) Name() string {
+	return fmt.Sprintf("rolling-%d-%d", w.Train, w.Test)
+}
+
+func (w RollingWindow) Splits(issues []conflation.ExpandedIssue) []Split {
+	splits := []Split{}
+	if w.Train < 1 || w.Test < 1 {
+		return splits
+	}
+	ordered := ByClosedAt(issues)
+	for start := 0; start+w.Train < len(ordered); start += w.Test {
+		end := start + w.Train + w.Test
+		if end > len(ordered) {
+			end = len(ordered)
+		}
+		splits = append(splits, Split{
+			Name:  fmt.Sprintf("window-%d", len(splits)+1),
+			Train: copyIssues(ordered[start : start+w.Train]),
+			Test:  copyIssues(ordered[start+w.Train : end]),
+		})
+	}
+	return splits
+}
+
+// ByClosedAt returns a copy of the issues ordered by resolution time; issues
+// without one keep their relative order at the end.
+func ByClosedAt(issues []conflation.ExpandedIssue) []conflation.ExpandedIssue {
+	ordered := copyIssues(issues)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, aok := ClosedAt(ordered[i])
+		b, bok := ClosedAt(ordered[j])
+		if !aok || !bok {
+			return aok && !bok
+		}
+		return a.Before(b)
+	})
+	return ordered
+}
+
+// ClosedAt returns when the issue, or else its pull request, was resolved.
+func ClosedAt(issue conflation.ExpandedIssue) (time.Time, bool) {
+	if issue.Issue.ClosedAt != nil && !issue.Issue.ClosedAt.IsZero() {
+		return *issue.Issue.ClosedAt, true
+	}
+	if issue.PullRequest.ClosedAt != nil && !issue.PullRequest.ClosedAt.IsZero() {
+		return *issue.PullRequest.ClosedAt, true
+	}
+	return time.Time{}, false
+}
+
+func copyIssues(issues []conflation.ExpandedIssue) []conflation.ExpandedIssue {
+	copied := make([]conflation.ExpandedIssue, len(issues))
+	copy(copied, issues)
+	return copied
+}
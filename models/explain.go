@@ -0,0 +1,21 @@
+package models
+
+import (
+	"core/models/bhattacharya"
+	"core/pipeline/gateway/conflation"
+)
+
+// ExplainingAlgorithm is implemented by algorithms that can attribute their
+// predictions to the terms of the issue.
+type ExplainingAlgorithm interface {
+	Explain(input conflation.ExpandedIssue, top int) []bhattacharya.Explanation
+}
+
+// Explain returns an explanation per candidate in prediction order with at
+// most top terms each, or nil if the algorithm cannot explain itself.
+func (m *Model) Explain(input conflation.ExpandedIssue, top int) []bhattacharya.Explanation {
+	if algorithm, ok := m.Algorithm.(ExplainingAlgorithm); ok {
+		return algorithm.Explain(input, top)
+	}
+	return nil
+}
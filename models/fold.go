@@ -1,13 +1,10 @@
 package models
 
 import (
-	"fmt"
-	"os"
 	"path/filepath"
 	"strconv"
-	"time"
+	"strings"
 
-	strftime "github.com/lestrrat/go-strftime"
 	"go.uber.org/zap"
 
 	"core/models/bhattacharya"
@@ -43,8 +40,8 @@ func (m *Model) foldImplementation(test []conflation.ExpandedIssue, genProbTable
 		//utils.ModelSummary.Debug("Actual Assignee: ", *test[i].Issue.Assignees[0].Login)
 		predictions := m.Predict(test[i])
 		//utils.ModelSummary.Debug("Predicted: ", predictions)
-		nbm := m.Algorithm.(*bhattacharya.NBModel)
-		if genProbTable {
+		nbm, ok := m.Algorithm.(*bhattacharya.NBModel)
+		if genProbTable && ok {
 			if test[i].Issue.ID != nil {
 				nbm.GenerateProbabilityTable(
 					*test[i].Issue.ID,
@@ -110,6 +107,10 @@ func (m *Model) TrainFold(train []conflation.ExpandedIssue, test []conflation.Ex
 }
 
 // JohnFold gradually increases the training data by increments of 1/10th.
+// Each loop logs next to the configured model log.
+//
+// Deprecated: use evaluation.Incremental from core/models/evaluation, which
+// works on any Algorithm and returns structured results.
 func (m *Model) JohnFold(issues []conflation.ExpandedIssue) float64 {
 	utils.ModelLog.Info("John Fold", zap.Int("Issues#", len(issues)))
 	finalScore := 0.00
@@ -117,10 +118,8 @@ func (m *Model) JohnFold(issues []conflation.ExpandedIssue) float64 {
 	var mat matrix
 	var distinct []string
 	for i := 0.1; i <= 0.9; i += 0.1 {
-		p, _ := strftime.New("$GOPATH/src/core/data/backtests/model-%Y%m%d%H%M-fold-" + strconv.Itoa(int(i*10.0)) + ".log")
-		f := p.FormatString(time.Now())
-		o := filepath.Join(os.Getenv("GOPATH"), f[7:])
-		utils.ModelLog = utils.IntializeLog(o)
+		ext := filepath.Ext(utils.Config.ModelLogPath)
+		utils.ModelLog = utils.IntializeLog(strings.TrimSuffix(utils.Config.ModelLogPath, ext) + "-fold-" + strconv.Itoa(int(i*10.0)) + ext)
 
 		split := int(Round(i * float64(len(issues))))
 		if i < 0.8 {
@@ -130,13 +129,13 @@ func (m *Model) JohnFold(issues []conflation.ExpandedIssue) float64 {
 		}
 		modelRecoveryFile := utils.Config.DataCachesPath + "/JFold" + ToString(i*10.0) + ".model"
 		m.GenerateRecoveryFile(modelRecoveryFile)
-		fmt.Println("John Fold Loop#", (int)(Round(i)*10.0), "Accuracy", score)
 		utils.ModelLog.Info("John Fold", zap.Int("Loop#", (int)(Round(i)*10.0)), zap.Float64("Accuracy", score))
 		distinct = distinct
 		mat = mat
 		finalScore += score
-		nbm := m.Algorithm.(*bhattacharya.NBModel)
-		nbm.LogClassWords()
+		if nbm, ok := m.Algorithm.(*bhattacharya.NBModel); ok {
+			nbm.LogClassWords()
+		}
 	}
 	finalScore = Round(finalScore / 9.00)
 	utils.ModelLog.Info("John Fold", zap.Float64("Score", finalScore))
@@ -144,6 +143,8 @@ func (m *Model) JohnFold(issues []conflation.ExpandedIssue) float64 {
 }
 
 // TwoFold splits data in half - alternating training on each half.
+//
+// Deprecated: use evaluation.KFold from core/models/evaluation.
 func (m *Model) TwoFold(issues []conflation.ExpandedIssue) string {
 	//TODO: Fix log
 	//utils.ModelSummary.Info("Two Fold issues count: ", len(issues))
@@ -159,6 +160,8 @@ func (m *Model) TwoFold(issues []conflation.ExpandedIssue) string {
 }
 
 // TenFold trains on a rolling 1/10th chunk of the input data.
+//
+// Deprecated: use evaluation.KFold from core/models/evaluation.
 func (m *Model) TenFold(issues []conflation.ExpandedIssue) string {
 	//TODO: Fix log
 	//utils.ModelSummary.Info("Ten Fold issues count: ", len(issues))
@@ -167,8 +170,9 @@ func (m *Model) TenFold(issues []conflation.ExpandedIssue) string {
 	for i := 0.10; i <= 1.00; i += 0.10 {
 		end := int(Round(i * float64(len(issues))))
 		segment := issues[start:end]
-		remainder := []conflation.ExpandedIssue{}
-		remainder = append(issues[:start], issues[end:]...)
+		remainder := make([]conflation.ExpandedIssue, 0, len(issues)-len(segment))
+		remainder = append(remainder, issues[:start]...)
+		remainder = append(remainder, issues[end:]...)
 		score, matrix, distinct := m.fold(segment, remainder, false)
 		//utils.ModelSummary.Info("Loop: " + ToString(i*10.0) + ", Accuracy: " + ToString(score))
 		matrix.classesEvaluation(distinct)
@@ -90,4 +90,14 @@ func TestTenFold(t *testing.T) {
 			"\nEXPECTED BETWEEN 0.00 AND 1.00 - ACTUAL: %f", number,
 		)
 	}
+	for i := range testingIssues {
+		if *testingIssues[i].Issue.ID != int64(i+1) {
+			t.Error(
+				"\nINPUT ISSUES MODIFIED - TEN FOLD",
+				"\nEXPECTED: ", i+1,
+				"\nACTUAL:   ", *testingIssues[i].Issue.ID,
+			)
+			break
+		}
+	}
 }
@@ -0,0 +1,100 @@
+package labelmaker
+
+import (
+	"github.com/bbalet/stopwords"
+
+	"core/models/preprocess"
+	"core/pipeline/gateway/conflation"
+)
+
+// CorrectionMargin is how many more corrected issues must vote for one issue
+// type than for any other before the corrections override the classifier.
+const CorrectionMargin = 3
+
+// correctedIssue is the issue type and title terms of an issue whose type
+// label was corrected.
+type correctedIssue struct {
+	kind  int
+	terms map[string]bool
+}
+
+// corrections holds the corrected issues by number. They are kept in memory
+// only and are lost when the backend restarts.
+type corrections map[int]correctedIssue
+
+// kindOf maps the labels of an issue onto the configured issue types.
+func (c *LBModel) kindOf(labels []string) int {
+	for _, label := range labels {
+		switch {
+		case c.BugLabel != nil && label == *c.BugLabel:
+			return Bug
+		case c.FeatureLabel != nil && label == *c.FeatureLabel:
+			return Feature
+		case c.ImprovementLabel != nil && label == *c.ImprovementLabel:
+			return Improvement
+		}
+	}
+	return Unknown
+}
+
+func titleTerms(title string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, term := range preprocess.Tokenize(stopwords.CleanString(title, "en", false)) {
+		if len(term) > 2 {
+			terms[term] = true
+		}
+	}
+	return terms
+}
+
+// learnCorrection records an issue whose type label was corrected by a
+// maintainer; a later correction of the same issue replaces it.
+func (c *LBModel) learnCorrection(input conflation.ExpandedIssue) {
+	if input.Issue.Title == nil || input.Issue.Number == nil {
+		return
+	}
+	labels := []string{}
+	for _, label := range input.Issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	kind := c.kindOf(labels)
+	if kind == Unknown {
+		return
+	}
+	if c.corrections == nil {
+		c.corrections = make(corrections)
+	}
+	c.corrections[*input.Issue.Number] = correctedIssue{kind: kind, terms: titleTerms(*input.Issue.Title)}
+}
+
+// corrected returns the issue type the corrections vote for, if any type
+// leads the others by at least CorrectionMargin. Each corrected issue sharing
+// a title term with the input votes once.
+func (c *LBModel) corrected(input conflation.ExpandedIssue) (int, bool) {
+	if len(c.corrections) == 0 || input.Issue.Title == nil {
+		return Unknown, false
+	}
+	terms := titleTerms(*input.Issue.Title)
+	votes := make(map[int]int)
+	for _, correction := range c.corrections {
+		for term := range correction.terms {
+			if terms[term] {
+				votes[correction.kind]++
+				break
+			}
+		}
+	}
+	best, first, second := Unknown, 0, 0
+	for _, kind := range []int{Bug, Feature, Improvement} {
+		switch {
+		case votes[kind] > first:
+			best, first, second = kind, votes[kind], first
+		case votes[kind] > second:
+			second = votes[kind]
+		}
+	}
+	if best == Unknown || first-second < CorrectionMargin {
+		return Unknown, false
+	}
+	return best, true
+}
@@ -0,0 +1,64 @@
+package labelmaker
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+
+	conf "core/pipeline/gateway/conflation"
+)
+
+func labeledIssue(number int, title string, labels ...string) conf.ExpandedIssue {
+	issue := github.Issue{Number: github.Int(number), Title: github.String(title)}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, github.Label{Name: github.String(label)})
+	}
+	return conf.ExpandedIssue{Issue: conf.CRIssue{Issue: issue}}
+}
+
+func TestCorrections(t *testing.T) {
+	lbModel := LBModel{BugLabel: github.String("bug"), FeatureLabel: github.String("enhancement")}
+	lbModel.OnlineLearn([]conf.ExpandedIssue{
+		labeledIssue(1, "Hyperdrive motivator fails after jump", "bug"),
+		labeledIssue(2, "Hyperdrive stalls at lightspeed", "bug", "help wanted"),
+		labeledIssue(3, "Hyperdrive overheats", "bug"),
+		labeledIssue(4, "Hyperdrive leaks coolant", "bug"),
+		labeledIssue(5, "Hyperdrive cup holder", "enhancement"),
+		labeledIssue(6, "Add more droids", "wontfix"),
+	})
+
+	if kind, ok := lbModel.corrected(labeledIssue(7, "Hyperdrive motivator broken")); !ok || kind != Bug {
+		t.Error(
+			"\nCORRECTIONS NOT APPLIED",
+			"\nEXPECTED: ", Bug,
+			"\nACTUAL:   ", kind, ok,
+		)
+	}
+	if kind, ok := lbModel.corrected(labeledIssue(7, "Falcon cup holder")); ok {
+		t.Error(
+			"\nCORRECTIONS APPLIED WITHOUT MARGIN",
+			"\nACTUAL:   ", kind,
+		)
+	}
+
+	lbModel.OnlineLearn([]conf.ExpandedIssue{labeledIssue(4, "Hyperdrive leaks coolant", "enhancement")})
+	if kind, ok := lbModel.corrected(labeledIssue(7, "Hyperdrive motivator broken")); ok {
+		t.Error(
+			"\nRECORRECTED ISSUE COUNTED TWICE",
+			"\nACTUAL:   ", kind,
+		)
+	}
+}
+
+func TestCorrectionsSingleIssue(t *testing.T) {
+	lbModel := LBModel{BugLabel: github.String("bug"), FeatureLabel: github.String("enhancement")}
+	lbModel.OnlineLearn([]conf.ExpandedIssue{
+		labeledIssue(1, "Thermal detonator timer display flickers randomly", "bug"),
+	})
+	if kind, ok := lbModel.corrected(labeledIssue(2, "Thermal detonator timer display flickers again")); ok {
+		t.Error(
+			"\nSINGLE CORRECTION OVERRODE CLASSIFIER",
+			"\nACTUAL:   ", kind,
+		)
+	}
+}
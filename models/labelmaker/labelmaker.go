@@ -7,6 +7,7 @@ import (
 	"golang.org/x/net/context"
 	languagepb "google.golang.org/genproto/googleapis/cloud/language/v1"
 
+	"core/models/preprocess"
 	"core/pipeline/gateway/conflation"
 )
 
@@ -24,6 +25,7 @@ type LBModel struct {
 	FeatureLabel     *string
 	BugLabel         *string
 	ImprovementLabel *string
+	corrections      corrections
 }
 
 func (c *LBModel) IsBootstrapped() bool {
@@ -36,8 +38,12 @@ func (c *LBModel) Learn(labels []string) {
 	c.Classifier.Learn(labels)
 }
 
+// OnlineLearn learns from issues whose type label a maintainer corrected;
+// the input issues carry the corrected labels.
 func (c *LBModel) OnlineLearn(input []conflation.ExpandedIssue) {
-
+	for i := 0; i < len(input); i++ {
+		c.learnCorrection(input[i])
+	}
 }
 
 func (c *LBModel) Predict(input conflation.ExpandedIssue) ([]string, error) {
@@ -48,15 +54,21 @@ func (c *LBModel) Predict(input conflation.ExpandedIssue) ([]string, error) {
 func (c *LBModel) ExperimentalPredict(input conflation.ExpandedIssue) ([]string, error) {
 	results, err := c.Classifier.Predict(*input.Issue.Title, false)
 	if results == nil && input.Issue.Body != nil {
-		results, err = c.Classifier.Predict(*input.Issue.Body, true)
+		// NOTE: Code, stack traces and markdown are stripped so that the
+		// entities come from the prose of the body.
+		results, err = c.Classifier.Predict(preprocess.Parse(*input.Issue.Body).Text, true)
 	}
 	return results, err
 }
 
 func (c *LBModel) BugOrFeature(input conflation.ExpandedIssue) (*string, error) {
-	result, err := c.Classifier.BugOrFeature(input)
-	if err != nil {
-		return nil, err
+	result, ok := c.corrected(input)
+	if !ok {
+		var err error
+		result, err = c.Classifier.BugOrFeature(input)
+		if err != nil {
+			return nil, err
+		}
 	}
 	switch result {
 	case Bug:
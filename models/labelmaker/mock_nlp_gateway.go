@@ -2,57 +2,38 @@ package labelmaker
 
 import languagepb "google.golang.org/genproto/googleapis/cloud/language/v1"
 
+// MockNlpGateway returns canned Natural Language API responses so that the
+// labelmaker can run without Google Cloud credentials.
 type MockNlpGateway struct {
 }
 
 func (gateway *MockNlpGateway) AnalyzeSentiment(input string) (*languagepb.AnalyzeSentimentResponse, error) {
 	// Response as shown at https://cloud.google.com/natural-language/docs/analyzing-sentiment
-	documentSentiment := *languagepb.Sentiment{0.8, 0.8}
+	sentiment := &languagepb.Sentiment{Magnitude: 0.8, Score: 0.8}
 	sentences := []*languagepb.Sentence{
-		*languagepb.Sentence{*languagepb.TextSpan{"Enjoy your vacation!", 0}, *languagepb.Sentiment{0.8, 0.8}}
+		{Text: &languagepb.TextSpan{Content: "Enjoy your vacation!", BeginOffset: 0}, Sentiment: sentiment},
 	}
-	return *languagepb.AnalyzeSentimentResponse{documentSentiment, "en", sentences}
+	return &languagepb.AnalyzeSentimentResponse{DocumentSentiment: sentiment, Language: "en", Sentences: sentences}, nil
 }
 
 func (gateway *MockNlpGateway) AnalyzeSyntax(input string) (syntax *languagepb.AnalyzeSyntaxResponse, err error) {
 	// Response as shown at https://cloud.google.com/natural-language/docs/analyzing-syntax
 	sentences := []*languagepb.Sentence{
-		*languagepb.Sentence{1, *languagepb.TextSpan{0,"Google, headquartered in Mountain View, unveiled the new Android phone at the Consumer Electronic Show."}},
-		*languagepb.Sentence{1, *languagepb.TextSpan{105, "Sundar Pichai said in his keynote that users love their new Android phones."}}
+		{Text: &languagepb.TextSpan{Content: "Google unveiled the new Android phone.", BeginOffset: 0}},
 	}
 	tokens := []*languagepb.Token{
-		*languagepb.Token{*languagepb.TextSpan{0, "Google"}, *languagepb.PartOfSpeech{
-			*languagepb.PartOfSpeech_Tag.PartOfSpeech_NOUN,
-			*languagepb.PartOfSpeech_Aspect.PartOfSpeech_ASPECT_UNKNOWN,
-			*languagepb.PartOfSpeech_Case.PartOfSpeech_CASE_UNKNOWN,
-			*languagepb.PartOfSpeech_Form.PartOfSpeech_FORM_UNKNOWN,
-			*languagepb.PartOfSpeech_Gender.PartOfSpeech_GENDER_UNKNOWN,
-			*languagepb.PartOfSpeech_Mood.PartOfSpeech_MOOD_UNKNOWN,
-			*languagepb.PartOfSpeech_Number.PartOfSpeech_SINGULAR,
-			*languagepb.PartOfSpeech_Person.PartOfSpeech_PERSON_UNKNOWN,
-			*languagepb.PartOfSpeech_Proper.PartOfSpeech_PROPER,
-			*languagepb.PartOfSpeech_Reciprocity.PartOfSpeech_RECIPROCITY_UNKNOWN,
-			*languagepb.PartOfSpeech_Tense.PartOfSpeech_TENSE_UNKNOWN,
-			*languagepb.PartOfSpeech_Voice.PartOfSpeech_VOICE_UNKNOWN,
-		}, *languagepb.DependencyEdge{7, "NSUBJ"}, "Google"},
-		*languagepb.Token{*languagepb.TextSpan{179, "."}, *languagepb.PartOfSpeech{
-			*languagepb.PartOfSpeech_Tag.PartOfSpeech_PUNCT,
-			*languagepb.PartOfSpeech_Aspect.PartOfSpeech_ASPECT_UNKNOWN,
-			*languagepb.PartOfSpeech_Case.PartOfSpeech_CASE_UNKNOWN,
-			*languagepb.PartOfSpeech_Form.PartOfSpeech_FORM_UNKNOWN,
-			*languagepb.PartOfSpeech_Gender.PartOfSpeech_GENDER_UNKNOWN,
-			*languagepb.PartOfSpeech_Mood.PartOfSpeech_MOOD_UNKNOWN,
-			*languagepb.PartOfSpeech_Number.PartOfSpeech_UNKNOWN,
-			*languagepb.PartOfSpeech_Person.PartOfSpeech_PERSON_UNKNOWN,
-			*languagepb.PartOfSpeech_Proper.PartOfSpeech_PROPER_UNKNOWN,
-			*languagepb.PartOfSpeech_Reciprocity.PartOfSpeech_RECIPROCITY_UNKNOWN,
-			*languagepb.PartOfSpeech_Tense.PartOfSpeech_TENSE_UNKNOWN,
-			*languagepb.PartOfSpeech_Voice.PartOfSpeech_VOICE_UNKNOWN,
-		}, *languagepb.DependencyEdge{20, "P"}, "."}
-	}
-	return *languagepb.AnalyzeSyntaxResponse{
-		sentences,
-		tokens,
-		"en",
+		{
+			Text:           &languagepb.TextSpan{Content: "Google", BeginOffset: 0},
+			PartOfSpeech:   &languagepb.PartOfSpeech{Tag: languagepb.PartOfSpeech_NOUN},
+			DependencyEdge: &languagepb.DependencyEdge{HeadTokenIndex: 1},
+			Lemma:          "Google",
+		},
+		{
+			Text:           &languagepb.TextSpan{Content: "unveiled", BeginOffset: 7},
+			PartOfSpeech:   &languagepb.PartOfSpeech{Tag: languagepb.PartOfSpeech_VERB},
+			DependencyEdge: &languagepb.DependencyEdge{HeadTokenIndex: 1, Label: languagepb.DependencyEdge_ROOT},
+			Lemma:          "unveil",
+		},
 	}
+	return &languagepb.AnalyzeSyntaxResponse{Sentences: sentences, Tokens: tokens, Language: "en"}, nil
 }
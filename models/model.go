@@ -15,6 +15,31 @@ type Algorithm interface {
 	RecoverModelFromFile(path string) error
 }
 
+// ConfidenceAlgorithm is implemented by algorithms that can attach a
+// confidence in [0, 1] to each prediction.
+type ConfidenceAlgorithm interface {
+	PredictWithConfidence(input conflation.ExpandedIssue) ([]string, []float64)
+}
+
+// ClassAlgorithm is implemented by algorithms that can list the assignees
+// they currently predict.
+type ClassAlgorithm interface {
+	Classes() []string
+}
+
+// CursorAlgorithm is implemented by algorithms that record the repository
+// and the last event they were trained on in their recovery files.
+type CursorAlgorithm interface {
+	SetCursor(repoID, cursor int64)
+}
+
+// VersionAlgorithm is implemented by algorithms that record the version of
+// the model in their recovery files.
+type VersionAlgorithm interface {
+	SetVersion(version int64)
+	Version() int64
+}
+
 func (m *Model) IsBootstrapped() bool {
 	return m.Algorithm.IsBootstrapped()
 }
@@ -31,6 +56,48 @@ func (m *Model) Predict(input conflation.ExpandedIssue) []string {
 	return m.Algorithm.Predict(input)
 }
 
+// PredictWithConfidence returns the predictions with their confidences, or
+// nil confidences if the algorithm does not provide them.
+func (m *Model) PredictWithConfidence(input conflation.ExpandedIssue) ([]string, []float64) {
+	if algorithm, ok := m.Algorithm.(ConfidenceAlgorithm); ok {
+		return algorithm.PredictWithConfidence(input)
+	}
+	return m.Algorithm.Predict(input), nil
+}
+
+// Classes returns the assignees the model can predict, or nil if the
+// algorithm does not list them.
+func (m *Model) Classes() []string {
+	if algorithm, ok := m.Algorithm.(ClassAlgorithm); ok {
+		return algorithm.Classes()
+	}
+	return nil
+}
+
+// SetCursor records the repository and the id of the last event trained on,
+// if the algorithm keeps them.
+func (m *Model) SetCursor(repoID, cursor int64) {
+	if algorithm, ok := m.Algorithm.(CursorAlgorithm); ok {
+		algorithm.SetCursor(repoID, cursor)
+	}
+}
+
+// SetVersion records the model version, if the algorithm keeps it.
+func (m *Model) SetVersion(version int64) {
+	if algorithm, ok := m.Algorithm.(VersionAlgorithm); ok {
+		algorithm.SetVersion(version)
+	}
+}
+
+// Version returns the recorded model version, or 0 if the algorithm does not
+// keep it.
+func (m *Model) Version() int64 {
+	if algorithm, ok := m.Algorithm.(VersionAlgorithm); ok {
+		return algorithm.Version()
+	}
+	return 0
+}
+
 func (m *Model) GenerateRecoveryFile(path string) error {
 	return m.Algorithm.GenerateRecoveryFile(path)
 }
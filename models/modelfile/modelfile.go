@@ -0,0 +1,169 @@
+package modelfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// Magic is the first line of every versioned model file. Files without it
+// predate the format and are treated as version 1.
+const Magic = "HEUPR-MODEL"
+
+// CurrentVersion is the format version written by Write.
+const CurrentVersion = 2
+
+var (
+	ErrChecksum         = errors.New("model file checksum mismatch")
+	ErrUnknownVersion   = errors.New("model file version is newer than supported")
+	ErrAlgorithm        = errors.New("model file algorithm mismatch")
+	ErrMissingMigration = errors.New("no migration registered for model file version")
+)
+
+// Header describes a model file. It is stored as a single JSON line ahead of
+// the payload so that it can be read without decoding the model itself.
+type Header struct {
+	FormatVersion  int       `json:"format_version"`
+	Algorithm      string    `json:"algorithm"`
+	RepoID         int64     `json:"repo_id,omitempty"`
+	TrainingCursor int64     `json:"training_cursor,omitempty"`
+	ModelVersion   int64     `json:"model_version,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	Checksum       string    `json:"checksum,omitempty"`
+}
+
+// Migration upgrades a payload from one format version to the next.
+type Migration func(payload []byte) ([]byte, error)
+
+var migrations = struct {
+	sync.RWMutex
+	steps map[string]map[int]Migration
+}{steps: make(map[string]map[int]Migration)}
+
+// RegisterMigration registers the upgrade of an algorithm's payload from
+// version from to version from+1. Algorithms register their migrations in an
+// init function.
+func RegisterMigration(algorithm string, from int, m Migration) {
+	migrations.Lock()
+	defer migrations.Unlock()
+	if _, ok := migrations.steps[algorithm]; !ok {
+		migrations.steps[algorithm] = make(map[int]Migration)
+	}
+	migrations.steps[algorithm][from] = m
+}
+
+func migration(algorithm string, from int) (Migration, bool) {
+	migrations.RLock()
+	defer migrations.RUnlock()
+	m, ok := migrations.steps[algorithm][from]
+	return m, ok
+}
+
+// Checksum returns the hex encoded SHA-256 of a payload.
+func Checksum(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Write stores the header and payload. The format version, checksum and (if
+// unset) creation time of the header are filled in.
+func Write(w io.Writer, header Header, payload []byte) error {
+	header.FormatVersion = CurrentVersion
+	header.Checksum = Checksum(payload)
+	if header.CreatedAt.IsZero() {
+		header.CreatedAt = time.Now().UTC()
+	}
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	buffer := bytes.NewBufferString(Magic + "\n")
+	buffer.Write(encoded)
+	buffer.WriteString("\n")
+	buffer.Write(payload)
+	_, err = buffer.WriteTo(w)
+	return err
+}
+
+// Read loads a model file written for the given algorithm, verifies its
+// checksum and migrates the payload up to CurrentVersion. Legacy files
+// without a header are assumed to belong to the algorithm.
+func Read(r io.Reader, algorithm string) (Header, []byte, error) {
+	reader := bufio.NewReader(r)
+	header := Header{}
+	peek, _ := reader.Peek(len(Magic) + 1)
+	if string(peek) != Magic+"\n" {
+		payload, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return header, nil, err
+		}
+		header.FormatVersion = 1
+		header.Algorithm = algorithm
+		return migrate(header, payload)
+	}
+
+	if _, err := reader.Discard(len(Magic) + 1); err != nil {
+		return header, nil, err
+	}
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return header, nil, fmt.Errorf("model file header: %v", err)
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return header, nil, fmt.Errorf("model file header: %v", err)
+	}
+	if header.Algorithm != algorithm {
+		return header, nil, ErrAlgorithm
+	}
+	if header.FormatVersion > CurrentVersion {
+		return header, nil, ErrUnknownVersion
+	}
+	payload, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return header, nil, err
+	}
+	if Checksum(payload) != header.Checksum {
+		return header, nil, ErrChecksum
+	}
+	return migrate(header, payload)
+}
+
+func migrate(header Header, payload []byte) (Header, []byte, error) {
+	for header.FormatVersion < CurrentVersion {
+		m, ok := migration(header.Algorithm, header.FormatVersion)
+		if !ok {
+			return header, nil, ErrMissingMigration
+		}
+		upgraded, err := m(payload)
+		if err != nil {
+			return header, nil, fmt.Errorf("model file migration from version %d: %v", header.FormatVersion, err)
+		}
+		payload = upgraded
+		header.FormatVersion++
+	}
+	header.Checksum = Checksum(payload)
+	return header, payload, nil
+}
+
+// Export writes the header and a JSON payload as a single indented JSON
+// document so that models can be inspected and diffed.
+func Export(w io.Writer, header Header, payload []byte) error {
+	document := struct {
+		Header Header          `json:"header"`
+		Model  json.RawMessage `json:"model"`
+	}{header, json.RawMessage(payload)}
+	encoded, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(encoded, '\n'))
+	return err
+}
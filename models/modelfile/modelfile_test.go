@@ -0,0 +1,82 @@
+package modelfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	created := time.Date(2018, 5, 4, 0, 0, 0, 0, time.UTC)
+	header := Header{Algorithm: "kessel", RepoID: 12, TrainingCursor: 34, CreatedAt: created}
+	payload := []byte(`{"parsecs":12}`)
+
+	buffer := new(bytes.Buffer)
+	if err := Write(buffer, header, payload); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buffer.String(), Magic+"\n") {
+		t.Error("\nMAGIC LINE NOT WRITTEN")
+	}
+
+	readHeader, readPayload, err := Read(buffer, "kessel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readHeader.FormatVersion != CurrentVersion || readHeader.RepoID != 12 || readHeader.TrainingCursor != 34 || !readHeader.CreatedAt.Equal(created) {
+		t.Error(
+			"\nHEADER NOT RECOVERED",
+			"\nACTUAL:   ", readHeader,
+		)
+	}
+	if string(readPayload) != string(payload) {
+		t.Error(
+			"\nPAYLOAD NOT RECOVERED",
+			"\nEXPECTED: ", string(payload),
+			"\nACTUAL:   ", string(readPayload),
+		)
+	}
+}
+
+func TestReadChecksum(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	Write(buffer, Header{Algorithm: "kessel"}, []byte(`{"parsecs":12}`))
+	tampered := strings.Replace(buffer.String(), `"parsecs":12`, `"parsecs":13`, 1)
+	if _, _, err := Read(strings.NewReader(tampered), "kessel"); err != ErrChecksum {
+		t.Error(
+			"\nCHECKSUM NOT VERIFIED",
+			"\nERROR:    ", err,
+		)
+	}
+	if _, _, err := Read(strings.NewReader(buffer.String()), "hoth"); err != ErrAlgorithm {
+		t.Error(
+			"\nALGORITHM NOT VERIFIED",
+			"\nERROR:    ", err,
+		)
+	}
+}
+
+func TestReadLegacyMigration(t *testing.T) {
+	RegisterMigration("endor", 1, func(payload []byte) ([]byte, error) {
+		return append([]byte("migrated:"), payload...), nil
+	})
+	header, payload, err := Read(strings.NewReader("ewoks"), "endor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.FormatVersion != CurrentVersion || string(payload) != "migrated:ewoks" {
+		t.Error(
+			"\nLEGACY FILE NOT MIGRATED",
+			"\nVERSION:  ", header.FormatVersion,
+			"\nPAYLOAD:  ", string(payload),
+		)
+	}
+
+	if _, _, err := Read(strings.NewReader("ewoks"), "tatooine"); err != ErrMissingMigration {
+		t.Error(
+			"\nMISSING MIGRATION NOT REPORTED",
+			"\nERROR:    ", err,
+		)
+	}
+}
@@ -0,0 +1,126 @@
+package ownership
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// CodeOwnersLocations are the paths GitHub checks for a CODEOWNERS file, in
+// order of precedence.
+var CodeOwnersLocations = []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// CodeOwners is a parsed CODEOWNERS file. As on GitHub, the last rule that
+// matches a path determines its owners.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners reads a CODEOWNERS file. Owners are returned without the
+// leading "@"; team owners keep their "org/team" form and email owners are
+// kept as written.
+func ParseCodeOwners(r io.Reader) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		owners := []string{}
+		for _, owner := range fields[1:] {
+			owners = append(owners, strings.TrimPrefix(owner, "@"))
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{
+			pattern: compilePattern(fields[0]),
+			owners:  owners,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return codeOwners, nil
+}
+
+// Owners returns the owners of a repository path or nil if it is unowned.
+func (c *CodeOwners) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// IssueOwners returns the distinct owners of the given paths in the order
+// they are first found. Paths mentioned in issues are often rooted outside
+// of the repository (e.g. absolute stack trace paths), so every suffix of
+// each path is tried until one is owned.
+func (c *CodeOwners) IssueOwners(paths []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, path := range paths {
+		segments := strings.Split(path, "/")
+		for i := range segments {
+			owners := c.Owners(strings.Join(segments[i:], "/"))
+			if owners == nil {
+				continue
+			}
+			for _, owner := range owners {
+				if !seen[owner] {
+					seen[owner] = true
+					result = append(result, owner)
+				}
+			}
+			break
+		}
+	}
+	return result
+}
+
+// compilePattern converts a gitignore style CODEOWNERS pattern into a regular
+// expression over slash separated repository paths.
+func compilePattern(pattern string) *regexp.Regexp {
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expression := "^"
+	if !anchored {
+		expression += "(?:.*/)?"
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expression += "(?:.*/)?"
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expression += ".*"
+			i++
+		case pattern[i] == '*':
+			expression += "[^/]*"
+		case pattern[i] == '?':
+			expression += "[^/]"
+		default:
+			expression += regexp.QuoteMeta(string(pattern[i]))
+		}
+	}
+	if directory {
+		expression += "/.*$"
+	} else {
+		expression += "(?:/.*)?$"
+	}
+	return regexp.MustCompile(expression)
+}
@@ -0,0 +1,56 @@
+package ownership
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var codeOwnersFile = `# Default owners
+*                   @yoda
+
+*.go                @mace @heupr/backend
+/docs/              docs@jedi.org
+pipeline/**/db      @plo
+models/             @kit
+/models/labelmaker/ # unowned
+`
+
+func TestCodeOwners(t *testing.T) {
+	codeOwners, err := ParseCodeOwners(strings.NewReader(codeOwnersFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string][]string{
+		"README.md":                         {"yoda"},
+		"pipeline/backend/server.go":        {"mace", "heupr/backend"},
+		"docs/index.md":                     {"docs@jedi.org"},
+		"pipeline/ingestor/db/schema.sql":   {"plo"},
+		"models/bhattacharya/bayesian.go":   {"kit"},
+		"vendor/models/ownership/README.md": {"kit"},
+		"models/labelmaker/labelmaker.go":   {},
+	}
+	for path, expected := range cases {
+		if actual := codeOwners.Owners(path); !reflect.DeepEqual(actual, expected) {
+			t.Error(
+				"\nCODEOWNERS RULE NOT APPLIED",
+				"\nPATH:     ", path,
+				"\nEXPECTED: ", expected,
+				"\nACTUAL:   ", actual,
+			)
+		}
+	}
+}
+
+func TestIssueOwners(t *testing.T) {
+	codeOwners, _ := ParseCodeOwners(strings.NewReader("/pipeline/ @mace\n/models/ @kit @plo\n"))
+	owners := codeOwners.IssueOwners([]string{"go/src/core/models/ownership/ownership.go", "pipeline/backend/server.go", "models/README.md"})
+	expected := []string{"kit", "plo", "mace"}
+	if !reflect.DeepEqual(owners, expected) {
+		t.Error(
+			"\nISSUE OWNERS NOT RESOLVED",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", owners,
+		)
+	}
+}
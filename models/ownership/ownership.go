@@ -0,0 +1,209 @@
+package ownership
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/models/preprocess"
+	"core/utils"
+)
+
+// Match weights for the different ways an issue can reference a file.
+const (
+	PathWeight      = 1.0
+	BasenameWeight  = 0.5
+	PackageWeight   = 0.5
+	DirectoryWeight = 0.25
+)
+
+// MaxPullFetches bounds the number of pull request file lists fetched by a
+// single AddPulls call; the rest stay queued for the next call.
+const MaxPullFetches = 20
+
+// PullFilesGateway is satisfied by gateway.Gateway and gateway.CachedGateway.
+type PullFilesGateway interface {
+	GetPullFiles(owner, repo string, number int) ([]*github.CommitFile, error)
+}
+
+// Index records which files each developer has touched in merged pull
+// requests and scores issues by how strongly the paths, package names and
+// exception types they mention point at those files.
+type Index struct {
+	touches map[string]map[string]int
+	pulls   map[int]bool
+	pending map[int]*github.PullRequest
+}
+
+func NewIndex() *Index {
+	return &Index{
+		touches: make(map[string]map[string]int),
+		pulls:   make(map[int]bool),
+		pending: make(map[int]*github.PullRequest),
+	}
+}
+
+// Add records that the developer changed the given files in one merged pull
+// request.
+func (i *Index) Add(developer string, files []string) {
+	if _, ok := i.touches[developer]; !ok {
+		i.touches[developer] = make(map[string]int)
+	}
+	for _, file := range files {
+		i.touches[developer][preprocess.NormalizePath(file)]++
+	}
+}
+
+// AddPulls queues merged pull requests that have not been indexed yet and
+// fetches the file lists of at most MaxPullFetches queued pull requests,
+// adding them under the pull request author. A failed fetch is logged and
+// the pull request stays queued so that a later call retries it; the numbers
+// of the pull requests still queued are returned.
+func (i *Index) AddPulls(g PullFilesGateway, owner, repo string, pulls []*github.PullRequest) []int {
+	for _, pull := range pulls {
+		if pull.MergedAt == nil || pull.Number == nil || pull.User == nil || pull.User.Login == nil {
+			continue
+		}
+		if i.pulls[*pull.Number] {
+			continue
+		}
+		i.pending[*pull.Number] = pull
+	}
+	numbers := []int{}
+	for number := range i.pending {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	if len(numbers) > MaxPullFetches {
+		numbers = numbers[:MaxPullFetches]
+	}
+	for _, number := range numbers {
+		files, err := g.GetPullFiles(owner, repo, number)
+		if err != nil {
+			utils.AppLog.Error("AddPulls() pull request files", zap.String("Repo", owner+"/"+repo), zap.Int("Number", number), zap.Error(err))
+			continue
+		}
+		names := []string{}
+		for _, file := range files {
+			if file.Filename != nil {
+				names = append(names, *file.Filename)
+			}
+		}
+		i.Add(*i.pending[number].User.Login, names)
+		i.pulls[number] = true
+		delete(i.pending, number)
+	}
+	queued := []int{}
+	for number := range i.pending {
+		queued = append(queued, number)
+	}
+	sort.Ints(queued)
+	return queued
+}
+
+// Queued returns the pull requests waiting to be fetched by AddPulls.
+func (i *Index) Queued() []*github.PullRequest {
+	pulls := []*github.PullRequest{}
+	for _, pull := range i.pending {
+		pulls = append(pulls, pull)
+	}
+	sort.Slice(pulls, func(a, b int) bool { return *pulls[a].Number < *pulls[b].Number })
+	return pulls
+}
+
+// Developers returns the number of developers in the index.
+func (i *Index) Developers() int {
+	return len(i.touches)
+}
+
+// Scores returns a code ownership score in [0, 1] for every developer with at
+// least one matching file; the strongest owner scores 1.
+func (i *Index) Scores(doc preprocess.Document) map[string]float64 {
+	packages := []string{}
+	for _, frame := range doc.StackTrace {
+		if pkg := goPackage(frame.Symbol); pkg != "" {
+			packages = append(packages, pkg)
+		}
+	}
+	exceptions := []string{}
+	for _, name := range doc.Exceptions {
+		exceptions = append(exceptions, name[strings.LastIndex(name, ".")+1:])
+	}
+
+	scores := make(map[string]float64)
+	max := 0.0
+	for developer, files := range i.touches {
+		score := 0.0
+		for file, count := range files {
+			weight := 0.0
+			for _, mentioned := range doc.Paths {
+				weight += matchPath(mentioned, file)
+			}
+			for _, pkg := range packages {
+				if hasPathSuffix(path.Dir(file), pkg) || hasPathSuffix(pkg, path.Dir(file)) {
+					weight += PackageWeight
+				}
+			}
+			for _, name := range exceptions {
+				if stem(file) == name {
+					weight += BasenameWeight
+				}
+			}
+			score += weight * float64(count)
+		}
+		if score > 0 {
+			scores[developer] = score
+			if score > max {
+				max = score
+			}
+		}
+	}
+	for developer := range scores {
+		scores[developer] /= max
+	}
+	return scores
+}
+
+// matchPath compares a path mentioned in an issue with a repository path.
+// Mentioned paths are frequently absolute or rooted elsewhere (e.g. stack
+// traces from a deployed binary) so matching is done on path suffixes.
+func matchPath(mentioned, file string) float64 {
+	if hasPathSuffix(mentioned, file) || hasPathSuffix(file, mentioned) {
+		return PathWeight
+	}
+	if path.Base(mentioned) == path.Base(file) {
+		return BasenameWeight
+	}
+	dir := path.Dir(file)
+	mentionedDir := path.Dir(mentioned)
+	if dir != "." && mentionedDir != "." && (hasPathSuffix(mentionedDir, dir) || hasPathSuffix(dir, mentionedDir)) {
+		return DirectoryWeight
+	}
+	return 0
+}
+
+func hasPathSuffix(long, short string) bool {
+	return long == short || strings.HasSuffix(long, "/"+short)
+}
+
+func stem(file string) string {
+	base := path.Base(file)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// goPackage extracts the import path from a Go frame symbol such as
+// core/pipeline/backend.(*Server).NewModel.
+func goPackage(symbol string) string {
+	slash := strings.LastIndex(symbol, "/")
+	if slash < 0 {
+		return ""
+	}
+	dot := strings.Index(symbol[slash:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return symbol[:slash+dot]
+}
@@ -0,0 +1,138 @@
+package ownership
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/models/preprocess"
+)
+
+type fakeGateway map[int][]string
+
+func (f fakeGateway) GetPullFiles(owner, repo string, number int) ([]*github.CommitFile, error) {
+	names, ok := f[number]
+	if !ok {
+		return nil, errors.New("unexpected pull request")
+	}
+	files := []*github.CommitFile{}
+	for i := range names {
+		files = append(files, &github.CommitFile{Filename: &names[i]})
+	}
+	return files, nil
+}
+
+func pull(number int, login string, merged bool) *github.PullRequest {
+	p := &github.PullRequest{Number: &number, User: &github.User{Login: &login}}
+	if merged {
+		now := time.Now()
+		p.MergedAt = &now
+	}
+	return p
+}
+
+func TestAddPulls(t *testing.T) {
+	gateway := fakeGateway{
+		1: {"pipeline/backend/server.go", "pipeline/backend/worker.go"},
+		2: {"models/bhattacharya/bayesian.go"},
+	}
+	index := NewIndex()
+	pulls := []*github.PullRequest{pull(1, "mace", true), pull(2, "plo", true), pull(3, "kit", false)}
+	if queued := index.AddPulls(gateway, "jedi", "council", pulls); len(queued) != 0 {
+		t.Error("\nPULL REQUESTS LEFT QUEUED", "\nQUEUED:   ", queued)
+	}
+	if index.Developers() != 2 {
+		t.Error(
+			"\nUNMERGED PULL REQUEST INDEXED",
+			"\nEXPECTED: ", 2,
+			"\nACTUAL:   ", index.Developers(),
+		)
+	}
+	delete(gateway, 1)
+	if queued := index.AddPulls(gateway, "jedi", "council", pulls); len(queued) != 0 {
+		t.Error("\nINDEXED PULL REQUEST FETCHED AGAIN", "\nQUEUED:   ", queued)
+	}
+}
+
+func TestAddPullsRetry(t *testing.T) {
+	gateway := fakeGateway{
+		2: {"models/bhattacharya/bayesian.go"},
+	}
+	index := NewIndex()
+	pulls := []*github.PullRequest{pull(1, "mace", true), pull(2, "plo", true)}
+	queued := index.AddPulls(gateway, "jedi", "council", pulls)
+	if len(queued) != 1 || queued[0] != 1 || index.Developers() != 1 {
+		t.Error(
+			"\nFAILED PULL REQUEST NOT QUEUED",
+			"\nEXPECTED: ", []int{1},
+			"\nACTUAL:   ", queued,
+		)
+	}
+	gateway[1] = []string{"pipeline/backend/server.go"}
+	if queued := index.AddPulls(gateway, "jedi", "council", nil); len(queued) != 0 || index.Developers() != 2 {
+		t.Error(
+			"\nQUEUED PULL REQUEST NOT RETRIED",
+			"\nQUEUED:   ", queued,
+			"\nACTUAL:   ", index.Developers(),
+		)
+	}
+}
+
+func TestAddPullsBound(t *testing.T) {
+	gateway := fakeGateway{}
+	pulls := []*github.PullRequest{}
+	for number := 1; number <= MaxPullFetches+5; number++ {
+		gateway[number] = []string{"pipeline/backend/server.go"}
+		pulls = append(pulls, pull(number, "mace", true))
+	}
+	index := NewIndex()
+	if queued := index.AddPulls(gateway, "jedi", "council", pulls); len(queued) != 5 {
+		t.Error(
+			"\nFETCHES NOT BOUNDED",
+			"\nEXPECTED: ", 5,
+			"\nACTUAL:   ", len(queued),
+		)
+	}
+	if queued := index.AddPulls(gateway, "jedi", "council", index.Queued()); len(queued) != 0 {
+		t.Error("\nBOUNDED PULL REQUESTS NOT FETCHED LATER", "\nQUEUED:   ", queued)
+	}
+}
+
+func TestScores(t *testing.T) {
+	index := NewIndex()
+	index.Add("mace", []string{"pipeline/backend/server.go", "pipeline/backend/worker.go"})
+	index.Add("plo", []string{"models/bhattacharya/bayesian.go"})
+	index.Add("kit", []string{"src/main/java/com/jedi/temple/Archive.java"})
+
+	trace := "panic: runtime error\n\n" +
+		"goroutine 1 [running]:\n" +
+		"core/pipeline/backend.(*Server).Start(0xc42)\n" +
+		"\t/go/src/core/pipeline/backend/server.go:88 +0x1d\n"
+	scores := index.Scores(preprocess.Parse(trace))
+	if scores["mace"] != 1 || scores["plo"] != 0 {
+		t.Error(
+			"\nGO TRACE NOT MATCHED TO OWNER",
+			"\nSCORES:   ", scores,
+		)
+	}
+
+	java := "com.jedi.temple.ArchiveMissingException: gone\n" +
+		"\tat com.jedi.temple.Archive.load(Archive.java:12)\n"
+	scores = index.Scores(preprocess.Parse(java))
+	if scores["kit"] != 1 || len(scores) != 1 {
+		t.Error(
+			"\nJAVA TRACE NOT MATCHED TO OWNER",
+			"\nSCORES:   ", scores,
+		)
+	}
+
+	scores = index.Scores(preprocess.Parse("The typo is in bayesian.go"))
+	if scores["plo"] != 1 {
+		t.Error(
+			"\nBASENAME NOT MATCHED TO OWNER",
+			"\nSCORES:   ", scores,
+		)
+	}
+}
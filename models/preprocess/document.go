@@ -0,0 +1,161 @@
+package preprocess
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Frame is a single stack trace frame. Symbol is the function or method when
+// the trace format includes one and Path is the normalized source file.
+type Frame struct {
+	Symbol string
+	Path   string
+	Line   string
+}
+
+// Document is an issue or pull request body split into separate feature
+// fields. Text holds the prose with markdown, code, stack traces, URLs and
+// paths removed.
+type Document struct {
+	Text       string
+	Code       []string
+	Exceptions []string
+	StackTrace []Frame
+	URLs       []string
+	Paths      []string
+}
+
+var (
+	fencedCode = regexp.MustCompile("(?s)(```|~~~)[^\\n]*\\n(.*?)(```|~~~)")
+	inlineCode = regexp.MustCompile("`([^`\\n]+)`")
+
+	javaFrame   = regexp.MustCompile(`^\s*at\s+([\w$.<>]+)\(([\w$.-]+)?:?(\d+)?[^)]*\)`)
+	jsFrame     = regexp.MustCompile(`^\s*at\s+(?:([\w$.<> ]+?)\s+\()?([^\s()]+?):(\d+):\d+\)?$`)
+	pythonFrame = regexp.MustCompile(`^\s*File "([^"]+)", line (\d+)(?:, in (\S+))?`)
+	goFrame     = regexp.MustCompile(`^\s+(\S+\.go):(\d+)`)
+	goFunction  = regexp.MustCompile(`^([\w./*()-]+)\(.*\)$`)
+	traceHeader = regexp.MustCompile(`^(Traceback \(most recent call last\):|goroutine \d+ \[.*\]:|panic: .*|\s*\.\.\. \d+ more)$`)
+	exception   = regexp.MustCompile(`^(?:Caused by: |Exception in thread "[^"]*" )?([\w$.]*(?:Exception|Error))(?::.*)?$`)
+
+	urlPattern  = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	pathPattern = regexp.MustCompile(`(?:[\w.-]+[/\\])+[\w-]+\.[A-Za-z]\w*(?::\d+)*|\b[\w-]+\.(?:go|java|py|js|jsx|ts|tsx|rb|c|cc|cpp|h|hpp|cs|rs|php|scala|kt|swift|sh|yml|yaml|json|xml|toml)\b`)
+
+	image      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	link       = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+	html       = regexp.MustCompile(`<[^>\n]+>`)
+	heading    = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s*`)
+	blockquote = regexp.MustCompile(`(?m)^\s*>+\s?`)
+	listMarker = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?`)
+	rule       = regexp.MustCompile(`(?m)^\s*(?:[-*_=|:]\s*){3,}$`)
+	emphasis   = regexp.MustCompile(`(\*{1,3}|_{2,3}|~~)`)
+)
+
+// Parse splits a markdown body into a Document. Stack traces are detected in
+// both fenced code blocks and prose because they are frequently pasted
+// without fences.
+func Parse(body string) Document {
+	doc := Document{}
+
+	body = fencedCode.ReplaceAllStringFunc(body, func(block string) string {
+		code := fencedCode.FindStringSubmatch(block)[2]
+		if remaining := doc.extractTrace(code); strings.TrimSpace(remaining) != "" {
+			doc.Code = append(doc.Code, remaining)
+		}
+		return "\n"
+	})
+	body = inlineCode.ReplaceAllStringFunc(body, func(code string) string {
+		doc.Code = append(doc.Code, inlineCode.FindStringSubmatch(code)[1])
+		return " "
+	})
+	body = doc.extractTrace(body)
+
+	body = image.ReplaceAllString(body, "$1")
+	body = link.ReplaceAllStringFunc(body, func(match string) string {
+		parts := link.FindStringSubmatch(match)
+		if urlPattern.MatchString(parts[2]) {
+			doc.URLs = append(doc.URLs, NormalizeURL(parts[2]))
+		}
+		return parts[1]
+	})
+	body = urlPattern.ReplaceAllStringFunc(body, func(match string) string {
+		doc.URLs = append(doc.URLs, NormalizeURL(match))
+		return " "
+	})
+	body = pathPattern.ReplaceAllStringFunc(body, func(match string) string {
+		doc.Paths = append(doc.Paths, NormalizePath(match))
+		return " "
+	})
+
+	body = html.ReplaceAllString(body, " ")
+	body = rule.ReplaceAllString(body, "")
+	body = heading.ReplaceAllString(body, "")
+	body = blockquote.ReplaceAllString(body, "")
+	body = listMarker.ReplaceAllString(body, "")
+	body = emphasis.ReplaceAllString(body, "")
+	doc.Text = strings.Join(strings.Fields(body), " ")
+	return doc
+}
+
+// extractTrace moves stack trace lines from the input into the Document and
+// returns the remaining lines.
+func (doc *Document) extractTrace(input string) string {
+	remaining := []string{}
+	lines := strings.Split(input, "\n")
+	inTrace := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		wasTrace := inTrace
+		inTrace = true
+		if m := javaFrame.FindStringSubmatch(line); m != nil && !jsFrame.MatchString(line) {
+			doc.addFrame(Frame{Symbol: m[1], Path: javaPath(m[1], m[2]), Line: m[3]})
+		} else if m := jsFrame.FindStringSubmatch(line); m != nil {
+			doc.addFrame(Frame{Symbol: strings.TrimSpace(m[1]), Path: NormalizePath(m[2]), Line: m[3]})
+		} else if m := pythonFrame.FindStringSubmatch(line); m != nil {
+			doc.addFrame(Frame{Symbol: m[3], Path: NormalizePath(m[1]), Line: m[2]})
+		} else if m := goFrame.FindStringSubmatch(line); m != nil {
+			frame := Frame{Path: NormalizePath(m[1]), Line: m[2]}
+			if i > 0 {
+				if f := goFunction.FindStringSubmatch(strings.TrimSpace(lines[i-1])); f != nil {
+					frame.Symbol = f[1]
+				}
+			}
+			doc.addFrame(frame)
+		} else if goFunction.MatchString(trimmed) && i+1 < len(lines) && goFrame.MatchString(lines[i+1]) {
+			// NOTE: Go function lines are folded into the following file line.
+		} else if i > 0 && pythonFrame.MatchString(lines[i-1]) && strings.HasPrefix(line, " ") {
+			// NOTE: Python echoes the source line below each frame.
+		} else if traceHeader.MatchString(trimmed) {
+			// NOTE: Headers carry no features of their own.
+		} else if m := exception.FindStringSubmatch(trimmed); m != nil && (strings.Contains(m[1], ".") || wasTrace || i+1 < len(lines) && isFrame(lines[i+1])) {
+			doc.Exceptions = append(doc.Exceptions, m[1])
+		} else {
+			inTrace = false
+			remaining = append(remaining, line)
+		}
+	}
+	return strings.Join(remaining, "\n")
+}
+
+func isFrame(line string) bool {
+	return javaFrame.MatchString(line) || jsFrame.MatchString(line) || pythonFrame.MatchString(line) || goFrame.MatchString(line)
+}
+
+func (doc *Document) addFrame(frame Frame) {
+	doc.StackTrace = append(doc.StackTrace, frame)
+	if frame.Path != "" {
+		doc.Paths = append(doc.Paths, frame.Path)
+	}
+}
+
+// javaPath turns a frame such as com.example.Foo.bar(Foo.java:12) into the
+// conventional source path com/example/Foo.java.
+func javaPath(symbol, file string) string {
+	if file == "" {
+		return ""
+	}
+	parts := strings.Split(symbol, ".")
+	if len(parts) < 3 {
+		return NormalizePath(file)
+	}
+	return NormalizePath(strings.Join(parts[:len(parts)-2], "/") + "/" + file)
+}
@@ -0,0 +1,148 @@
+package preprocess
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Feature prefixes keep the separate Document fields distinct once they are
+// flattened into a single vocabulary.
+const (
+	CodePrefix      = "code:"
+	TracePrefix     = "trace:"
+	ExceptionPrefix = "exception:"
+	URLPrefix       = "url:"
+	PathPrefix      = "path:"
+)
+
+// Preprocessor turns raw issue text into model features. NGrams is the
+// largest prose n-gram emitted (values below 2 emit unigrams only) and
+// Normalize, when set, is applied to every prose and code token; returning
+// an empty string drops the token (e.g. stopwords).
+type Preprocessor struct {
+	NGrams    int
+	Normalize func(token string) string
+}
+
+// Features parses the body and returns the prose tokens, prose n-grams and
+// prefixed code, stack trace, URL and path features.
+func (p Preprocessor) Features(body string) []string {
+	doc := Parse(body)
+
+	prose := p.normalize(Tokenize(doc.Text))
+	features := append([]string{}, prose...)
+	for n := 2; n <= p.NGrams; n++ {
+		features = append(features, NGrams(prose, n)...)
+	}
+	for _, code := range doc.Code {
+		for _, token := range p.normalize(Tokenize(code)) {
+			features = append(features, CodePrefix+token)
+		}
+	}
+	for _, name := range doc.Exceptions {
+		features = append(features, ExceptionPrefix+name)
+	}
+	for _, frame := range doc.StackTrace {
+		if frame.Symbol != "" {
+			features = append(features, TracePrefix+frame.Symbol)
+		}
+	}
+	for _, u := range doc.URLs {
+		features = append(features, URLPrefix+u)
+	}
+	for _, filePath := range doc.Paths {
+		features = append(features, PathPrefix+filePath)
+	}
+	return features
+}
+
+func (p Preprocessor) normalize(tokens []string) []string {
+	if p.Normalize == nil {
+		return tokens
+	}
+	result := []string{}
+	for _, token := range tokens {
+		if normalized := p.Normalize(token); normalized != "" {
+			result = append(result, normalized)
+		}
+	}
+	return result
+}
+
+var separator = regexp.MustCompile(`[^\p{L}\p{N}_]+`)
+
+// Tokenize splits text on punctuation and whitespace, splits identifiers and
+// lowercases the result. Tokens without any letters are dropped.
+func Tokenize(text string) []string {
+	tokens := []string{}
+	for _, word := range separator.Split(text, -1) {
+		for _, part := range SplitIdentifier(word) {
+			if strings.IndexFunc(part, unicode.IsLetter) < 0 {
+				continue
+			}
+			tokens = append(tokens, strings.ToLower(part))
+		}
+	}
+	return tokens
+}
+
+// SplitIdentifier splits snake_case, kebab-case and camelCase identifiers
+// (including acronyms such as parseHTTPResponse) into their words.
+func SplitIdentifier(identifier string) []string {
+	words := []string{}
+	for _, segment := range strings.FieldsFunc(identifier, func(r rune) bool { return r == '_' || r == '-' }) {
+		runes := []rune(segment)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := unicode.IsLower(runes[i-1]) && unicode.IsUpper(runes[i])
+			acronymEnd := unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// NormalizeURL reduces a URL to its lowercased host (without "www.") and path
+// so that links differing only in scheme, query or fragment match.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimRight(raw, ".,;:!?")
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return strings.ToLower(raw)
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	return host + strings.TrimRight(parsed.Path, "/")
+}
+
+var lineSuffix = regexp.MustCompile(`(:\d+)+$`)
+
+// NormalizePath converts a file path to a relative, slash separated path with
+// any trailing line and column numbers removed.
+func NormalizePath(raw string) string {
+	normalized := strings.Replace(raw, "\\", "/", -1)
+	normalized = lineSuffix.ReplaceAllString(normalized, "")
+	if len(normalized) > 2 && normalized[1] == ':' {
+		normalized = normalized[2:]
+	}
+	normalized = path.Clean("/" + normalized)
+	return strings.TrimPrefix(normalized, "/")
+}
+
+// NGrams joins every run of n consecutive tokens with underscores.
+func NGrams(tokens []string, n int) []string {
+	grams := []string{}
+	if n < 1 {
+		return grams
+	}
+	for i := 0; i+n <= len(tokens); i++ {
+		grams = append(grams, strings.Join(tokens[i:i+n], "_"))
+	}
+	return grams
+}
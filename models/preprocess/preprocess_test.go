@@ -0,0 +1,160 @@
+package preprocess
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var javaBody = "## Crash on startup\n\n" +
+	"The **scheduler** fails when `maxRetries` is zero, see [the docs](https://www.example.com/docs/retries/?ref=issue#top).\n\n" +
+	"```\n" +
+	"java.lang.IllegalStateException: retries exhausted\n" +
+	"\tat com.heupr.scheduler.RetryPolicy.next(RetryPolicy.java:42)\n" +
+	"\tat com.heupr.scheduler.Worker.run(Worker.java:17)\n" +
+	"```\n\n" +
+	"Possibly related to src\\main\\resources\\config.yml:3\n"
+
+func TestParse(t *testing.T) {
+	doc := Parse(javaBody)
+
+	if doc.Text != "Crash on startup The scheduler fails when is zero, see the docs. Possibly related to" {
+		t.Error(
+			"\nMARKDOWN NOT STRIPPED",
+			"\nACTUAL:   ", doc.Text,
+		)
+	}
+	if !reflect.DeepEqual(doc.Code, []string{"maxRetries"}) {
+		t.Error(
+			"\nCODE NOT EXTRACTED",
+			"\nACTUAL:   ", doc.Code,
+		)
+	}
+	if !reflect.DeepEqual(doc.Exceptions, []string{"java.lang.IllegalStateException"}) {
+		t.Error(
+			"\nEXCEPTION NOT EXTRACTED",
+			"\nACTUAL:   ", doc.Exceptions,
+		)
+	}
+	expectedFrames := []Frame{
+		{Symbol: "com.heupr.scheduler.RetryPolicy.next", Path: "com/heupr/scheduler/RetryPolicy.java", Line: "42"},
+		{Symbol: "com.heupr.scheduler.Worker.run", Path: "com/heupr/scheduler/Worker.java", Line: "17"},
+	}
+	if !reflect.DeepEqual(doc.StackTrace, expectedFrames) {
+		t.Error(
+			"\nSTACK TRACE NOT EXTRACTED",
+			"\nEXPECTED: ", expectedFrames,
+			"\nACTUAL:   ", doc.StackTrace,
+		)
+	}
+	if !reflect.DeepEqual(doc.URLs, []string{"example.com/docs/retries"}) {
+		t.Error(
+			"\nURL NOT NORMALIZED",
+			"\nACTUAL:   ", doc.URLs,
+		)
+	}
+	expectedPaths := []string{"com/heupr/scheduler/RetryPolicy.java", "com/heupr/scheduler/Worker.java", "src/main/resources/config.yml"}
+	if !reflect.DeepEqual(doc.Paths, expectedPaths) {
+		t.Error(
+			"\nPATHS NOT EXTRACTED",
+			"\nEXPECTED: ", expectedPaths,
+			"\nACTUAL:   ", doc.Paths,
+		)
+	}
+}
+
+func TestParseTraces(t *testing.T) {
+	python := "Traceback (most recent call last):\n" +
+		"  File \"/srv/app/handlers/login.py\", line 12, in authenticate\n" +
+		"    user = lookup(name)\n" +
+		"KeyError: 'name'\n"
+	doc := Parse(python)
+	if len(doc.StackTrace) != 1 || doc.StackTrace[0].Symbol != "authenticate" || doc.StackTrace[0].Path != "srv/app/handlers/login.py" {
+		t.Error(
+			"\nPYTHON TRACE NOT EXTRACTED",
+			"\nACTUAL:   ", doc.StackTrace,
+		)
+	}
+	if !reflect.DeepEqual(doc.Exceptions, []string{"KeyError"}) || doc.Text != "" {
+		t.Error(
+			"\nPYTHON TRACE LEFT IN TEXT",
+			"\nEXCEPTIONS:", doc.Exceptions,
+			"\nTEXT:     ", doc.Text,
+		)
+	}
+
+	golang := "panic: runtime error: index out of range\n\n" +
+		"goroutine 1 [running]:\n" +
+		"main.(*Server).handle(0xc420010000)\n" +
+		"\t/go/src/core/backend/server.go:88 +0x1d\n"
+	doc = Parse(golang)
+	if len(doc.StackTrace) != 1 || doc.StackTrace[0].Symbol != "main.(*Server).handle" || doc.StackTrace[0].Path != "go/src/core/backend/server.go" {
+		t.Error(
+			"\nGO TRACE NOT EXTRACTED",
+			"\nACTUAL:   ", doc.StackTrace,
+		)
+	}
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	cases := map[string][]string{
+		"parseHTTPResponse": {"parse", "HTTP", "Response"},
+		"snake_case_name":   {"snake", "case", "name"},
+		"kebab-case":        {"kebab", "case"},
+		"XMLParser":         {"XML", "Parser"},
+		"utf8":              {"utf8"},
+	}
+	for identifier, expected := range cases {
+		if actual := SplitIdentifier(identifier); !reflect.DeepEqual(actual, expected) {
+			t.Error(
+				"\nIDENTIFIER NOT SPLIT",
+				"\nIDENTIFIER:", identifier,
+				"\nEXPECTED: ", expected,
+				"\nACTUAL:   ", actual,
+			)
+		}
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	cases := map[string]string{
+		"./pkg/server.go:12:4":    "pkg/server.go",
+		"C:\\src\\app\\Main.java": "src/app/Main.java",
+		"/usr/lib/../lib/x.py":    "usr/lib/x.py",
+	}
+	for raw, expected := range cases {
+		if actual := NormalizePath(raw); actual != expected {
+			t.Error(
+				"\nPATH NOT NORMALIZED",
+				"\nEXPECTED: ", expected,
+				"\nACTUAL:   ", actual,
+			)
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	p := Preprocessor{
+		NGrams: 2,
+		Normalize: func(token string) string {
+			if token == "the" {
+				return ""
+			}
+			return token
+		},
+	}
+	features := p.Features("Fix the retryPolicy in `Worker.run` https://github.com/heupr/heupr/issues/7")
+	expected := []string{
+		"fix", "retry", "policy", "in",
+		"fix_retry", "retry_policy", "policy_in",
+		"code:worker", "code:run",
+		"url:github.com/heupr/heupr/issues/7",
+	}
+	if !reflect.DeepEqual(features, expected) {
+		t.Error(
+			"\nFEATURES NOT GENERATED",
+			"\nEXPECTED: ", strings.Join(expected, " "),
+			"\nACTUAL:   ", strings.Join(features, " "),
+		)
+	}
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Kinds of GitHub mutations made by Heupr.
+const (
+	AddAssignees    = "add_assignees"
+	AddLabels       = "add_labels"
+	RemoveLabel     = "remove_label"
+	RemoveAssignees = "remove_assignees"
+	CreateComment   = "create_comment"
+	EditComment     = "edit_comment"
+)
+
+// Action is a GitHub mutation made by Heupr. Targets are the assignees or
+// labels added; Body is the comment text. Actor is the user whose command or
+// acceptance triggered the action, empty when Heupr acted on its own.
+// ModelVersion, Scores and Settings capture why the backend acted. An empty
+// Error means GitHub accepted it.
+type Action struct {
+	ID           int64              `json:"id"`
+	RepoID       int64              `json:"repo_id"`
+	Number       int                `json:"number"`
+	Action       string             `json:"action"`
+	Targets      []string           `json:"targets,omitempty"`
+	Body         string             `json:"body,omitempty"`
+	Actor        string             `json:"actor,omitempty"`
+	ModelVersion string             `json:"model_version,omitempty"`
+	Scores       map[string]float64 `json:"scores,omitempty"`
+	Settings     json.RawMessage    `json:"settings,omitempty"`
+	Error        string             `json:"error,omitempty"`
+	At           time.Time          `json:"at"`
+}
+
+// New returns the action with the outcome of the GitHub call.
+func New(repoID int64, number int, action string, targets []string, err error) Action {
+	a := Action{
+		RepoID:  repoID,
+		Number:  number,
+		Action:  action,
+		Targets: targets,
+		At:      time.Now(),
+	}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	return a
+}
+
+// Snapshot encodes the settings in effect when acting.
+func Snapshot(settings interface{}) json.RawMessage {
+	snapshot, err := json.Marshal(settings)
+	if err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// Insert stores the actions.
+func Insert(db *sql.DB, actions ...Action) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	values := []interface{}{}
+	for _, a := range actions {
+		targets, err := json.Marshal(a.Targets)
+		if err != nil {
+			return err
+		}
+		scores, err := json.Marshal(a.Scores)
+		if err != nil {
+			return err
+		}
+		settings := []byte(a.Settings)
+		if len(settings) == 0 {
+			settings = nil
+		}
+		values = append(values, a.RepoID, a.Number, a.Action, targets, a.Body, a.Actor, a.ModelVersion, scores, settings, a.Error, a.At)
+	}
+	_, err := db.Exec("INSERT INTO actions(repo_id, number, action, targets, body, actor, model_version, scores, settings, error, created_at) VALUES(?,?,?,?,?,?,?,?,?,?,?)"+strings.Repeat(",(?,?,?,?,?,?,?,?,?,?,?)", len(actions)-1), values...)
+	return err
+}
+
+// Filter selects actions; zero fields match everything.
+type Filter struct {
+	RepoID       int64
+	Number       int
+	Action       string
+	ModelVersion string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+func (f Filter) where() (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	if f.RepoID != 0 {
+		conditions = append(conditions, "repo_id = ?")
+		args = append(args, f.RepoID)
+	}
+	if f.Number != 0 {
+		conditions = append(conditions, "number = ?")
+		args = append(args, f.Number)
+	}
+	if f.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, f.Action)
+	}
+	if f.ModelVersion != "" {
+		conditions = append(conditions, "model_version = ?")
+		args = append(args, f.ModelVersion)
+	}
+	if !f.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, f.Since)
+	}
+	if !f.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, f.Until)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// Read returns the actions matching the filter, oldest first.
+func Read(db *sql.DB, f Filter) ([]Action, error) {
+	where, args := f.where()
+	query := "SELECT id, repo_id, number, action, targets, body, actor, model_version, scores, settings, error, created_at FROM actions" + where + " ORDER BY id"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+	results, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	actions := []Action{}
+	for results.Next() {
+		a := Action{}
+		var targets, scores, settings []byte
+		body := sql.NullString{}
+		actor := sql.NullString{}
+		version := sql.NullString{}
+		message := sql.NullString{}
+		if err := results.Scan(&a.ID, &a.RepoID, &a.Number, &a.Action, &targets, &body, &actor, &version, &scores, &settings, &message, &a.At); err != nil {
+			return nil, err
+		}
+		if len(targets) != 0 {
+			if err := json.Unmarshal(targets, &a.Targets); err != nil {
+				return nil, err
+			}
+		}
+		if len(scores) != 0 {
+			if err := json.Unmarshal(scores, &a.Scores); err != nil {
+				return nil, err
+			}
+		}
+		if len(settings) != 0 {
+			a.Settings = json.RawMessage(settings)
+		}
+		a.Body, a.Actor, a.ModelVersion, a.Error = body.String, actor.String, version.String, message.String
+		actions = append(actions, a)
+	}
+	return actions, results.Err()
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	action := New(66, 4, AddAssignees, []string{"hera"}, errors.New("rate limited"))
+	if action.RepoID != 66 || action.Number != 4 || action.Error != "rate limited" || action.At.IsZero() {
+		t.Error("\nACTION NOT BUILT", "\nACTUAL:   ", action)
+	}
+	if action := New(66, 4, AddLabels, []string{"bug"}, nil); action.Error != "" {
+		t.Error("\nSUCCESSFUL ACTION HAS ERROR", "\nACTUAL:   ", action.Error)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	settings := struct {
+		EnableTriager bool
+		TopK          int
+	}{true, 3}
+	if snapshot := string(Snapshot(settings)); snapshot != `{"EnableTriager":true,"TopK":3}` {
+		t.Error("\nSETTINGS NOT SNAPSHOT", "\nACTUAL:   ", snapshot)
+	}
+}
+
+func TestFilterWhere(t *testing.T) {
+	since := time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		filter Filter
+		where  string
+		args   []interface{}
+	}{
+		{Filter{}, "", []interface{}{}},
+		{
+			Filter{RepoID: 66, ModelVersion: "v1", Since: since},
+			" WHERE repo_id = ? AND model_version = ? AND created_at >= ?",
+			[]interface{}{int64(66), "v1", since},
+		},
+		{
+			Filter{Number: 4, Action: AddLabels, Until: since},
+			" WHERE number = ? AND action = ? AND created_at < ?",
+			[]interface{}{4, AddLabels, since},
+		},
+	}
+	for _, c := range cases {
+		where, args := c.filter.where()
+		if where != c.where || !reflect.DeepEqual(args, c.args) {
+			t.Error(
+				"\nFILTER NOT TRANSLATED",
+				"\nEXPECTED: ", c.where, c.args,
+				"\nACTUAL:   ", where, args,
+			)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package backend
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/models/bhattacharya"
+	"core/pipeline/audit"
+	"core/pipeline/gateway/conflation"
+	"core/utils"
+)
+
+// The admin API lets operators inspect and steer the per repo models. Every
+// request must carry "Authorization: Bearer <BackendAdminToken>"; the API is
+// disabled while no token is configured.
+//
+//	GET  /admin/repos                  list active repos
+//	GET  /admin/repos/{id}             model status of a repo
+//	POST /admin/repos/{id}/retrain     retrain the models from scratch
+//	POST /admin/repos/{id}/reset       drop the models and learned state
+//	POST /admin/repos/{id}/predict     dry run a GitHub issue payload
+//	GET  /admin/repos/{id}/caps        view the assignee caps
+//	PUT  /admin/repos/{id}/caps        edit the assignee caps
+//	GET  /admin/repos/{id}/params      view the model hyperparameters
+//	PUT  /admin/repos/{id}/params      replace the model hyperparameters
+//	                                   and retrain (see bhattacharya.Params)
+//	POST /admin/repos/{id}/pause       stop triaging and labeling
+//	POST /admin/repos/{id}/resume      resume triaging and labeling
+//	GET  /admin/repos/{id}/actions     audited GitHub mutations, filtered by
+//	                                   number, action, version, since, until
+//	                                   (RFC 3339) and limit
+//	POST /admin/repos/{id}/rollback    revert assignees and labels Heupr
+//	                                   added (see RollbackRequest)
+const adminPrefix = "/admin/repos"
+
+// RepoStatus is the admin view of an active repo.
+type RepoStatus struct {
+	RepoID       int64          `json:"repo_id"`
+	Paused       bool           `json:"paused"`
+	Shadow       bool           `json:"shadow"`
+	Bootstrapped bool           `json:"bootstrapped"`
+	Classes      []string       `json:"classes,omitempty"`
+	Trained      *time.Time     `json:"trained,omitempty"`
+	Version      string         `json:"version,omitempty"`
+	Issues       int            `json:"issues"`
+	Caps         map[string]int `json:"caps,omitempty"`
+	Params       string         `json:"params,omitempty"`
+	// The override rates measure the predictions against the human outcome,
+	// in shadow mode as well as live.
+	AssignmentOverrideRate float64 `json:"assignment_override_rate"`
+	LabelOverrideRate      float64 `json:"label_override_rate"`
+}
+
+// Prediction is the admin dry run result for an issue.
+type Prediction struct {
+	Assignees    []string                   `json:"assignees"`
+	Confidences  []float64                  `json:"confidences,omitempty"`
+	Explanations []bhattacharya.Explanation `json:"explanations,omitempty"`
+}
+
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// AdminHandler returns the authenticated admin API handler.
+func (bs *Server) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := utils.Config.BackendAdminToken
+		if token == "" {
+			writeAdminError(w, http.StatusNotFound, "admin api disabled")
+			return
+		}
+		header := r.Header.Get("Authorization")
+		given := strings.TrimPrefix(header, "Bearer ")
+		if given == header || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		bs.adminRoute(w, r)
+	})
+}
+
+func (bs *Server) adminRoute(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, adminPrefix) {
+		writeAdminError(w, http.StatusNotFound, "not found")
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, adminPrefix), "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			writeAdminError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		bs.adminList(w)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	repoID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || len(parts) > 2 {
+		writeAdminError(w, http.StatusNotFound, "not found")
+		return
+	}
+	bs.Repos.RLock()
+	repo, ok := bs.Repos.Actives[repoID]
+	bs.Repos.RUnlock()
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, "repo not active")
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		repo.Lock()
+		status := repo.status(repoID, true)
+		repo.Unlock()
+		writeAdminJSON(w, http.StatusOK, status)
+	case action == "retrain" && r.Method == http.MethodPost:
+		repo.Lock()
+		repo.Hive.Blender.Retrain(repo.newModel)
+		status := repo.status(repoID, false)
+		repo.Unlock()
+		utils.AppLog.Info("admin retrain", zap.Int64("RepoID", repoID))
+		writeAdminJSON(w, http.StatusOK, status)
+	case action == "reset" && r.Method == http.MethodPost:
+		repo.Lock()
+		repo.reset()
+		status := repo.status(repoID, false)
+		repo.Unlock()
+		utils.AppLog.Info("admin reset", zap.Int64("RepoID", repoID))
+		writeAdminJSON(w, http.StatusOK, status)
+	case action == "predict" && r.Method == http.MethodPost:
+		var issue github.Issue
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid issue payload")
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, repo.predict(issue))
+	case action == "caps" && r.Method == http.MethodGet:
+		repo.Lock()
+		caps := copyCaps(repo.EligibleAssignees)
+		repo.Unlock()
+		writeAdminJSON(w, http.StatusOK, caps)
+	case action == "caps" && r.Method == http.MethodPut:
+		var caps map[string]int
+		if err := json.NewDecoder(r.Body).Decode(&caps); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid caps payload")
+			return
+		}
+		for assignee, cap := range caps {
+			if assignee == "" || cap < 0 {
+				writeAdminError(w, http.StatusBadRequest, "caps must be non-negative")
+				return
+			}
+		}
+		for assignee, cap := range caps {
+			if err := bs.Database.UpsertAssigneeCap(repoID, assignee, cap); err != nil {
+				utils.AppLog.Error("admin UpsertAssigneeCap()", zap.Error(err))
+				writeAdminError(w, http.StatusInternalServerError, "unable to store caps")
+				return
+			}
+		}
+		repo.Lock()
+		if repo.EligibleAssignees == nil {
+			repo.EligibleAssignees = make(map[string]int)
+		}
+		for assignee, cap := range caps {
+			repo.EligibleAssignees[assignee] = cap
+		}
+		caps = copyCaps(repo.EligibleAssignees)
+		repo.Unlock()
+		writeAdminJSON(w, http.StatusOK, caps)
+	case action == "params" && r.Method == http.MethodGet:
+		repo.Lock()
+		params := repo.ModelParams
+		repo.Unlock()
+		writeAdminJSON(w, http.StatusOK, params)
+	case action == "params" && r.Method == http.MethodPut:
+		params, err := bhattacharya.ReadParams(r.Body)
+		if err != nil || !params.Valid() {
+			writeAdminError(w, http.StatusBadRequest, "invalid params payload")
+			return
+		}
+		if err := bs.Database.UpsertModelParams(repoID, params); err != nil {
+			utils.AppLog.Error("admin UpsertModelParams()", zap.Error(err))
+			writeAdminError(w, http.StatusInternalServerError, "unable to store params")
+			return
+		}
+		repo.Lock()
+		repo.ModelParams = params
+		repo.Hive.Blender.Retrain(repo.newModel)
+		status := repo.status(repoID, true)
+		repo.Unlock()
+		utils.AppLog.Info("admin params", zap.Int64("RepoID", repoID), zap.String("Params", params.String()))
+		writeAdminJSON(w, http.StatusOK, status)
+	case (action == "pause" || action == "resume") && r.Method == http.MethodPost:
+		paused := action == "pause"
+		if err := bs.Database.SetPaused(repoID, paused); err != nil {
+			utils.AppLog.Error("admin SetPaused()", zap.Error(err))
+			writeAdminError(w, http.StatusInternalServerError, "unable to store pause state")
+			return
+		}
+		repo.Lock()
+		repo.Paused = paused
+		status := repo.status(repoID, false)
+		repo.Unlock()
+		utils.AppLog.Info("admin "+action, zap.Int64("RepoID", repoID))
+		writeAdminJSON(w, http.StatusOK, status)
+	case action == "actions" && r.Method == http.MethodGet:
+		filter, err := actionFilter(repoID, r)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		actions, err := bs.Database.ReadActions(filter)
+		if err != nil {
+			utils.AppLog.Error("admin ReadActions()", zap.Error(err))
+			writeAdminError(w, http.StatusInternalServerError, "unable to read actions")
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, actions)
+	case action == "rollback" && r.Method == http.MethodPost:
+		var request RollbackRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid rollback payload")
+			return
+		}
+		repo.Lock()
+		client := repo.Client
+		repo.Unlock()
+		if client == nil {
+			writeAdminError(w, http.StatusConflict, "repo has no GitHub client")
+			return
+		}
+		result, err := bs.Rollback(repoID, client, request)
+		if err == errRollbackScope {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			utils.AppLog.Error("admin Rollback()", zap.Int64("RepoID", repoID), zap.Error(err))
+			writeAdminError(w, http.StatusInternalServerError, "rollback failed")
+			return
+		}
+		utils.AppLog.Info("admin rollback", zap.Int64("RepoID", repoID), zap.Bool("DryRun", request.DryRun), zap.Int("Reverted", len(result.Reverted)), zap.Int("Skipped", len(result.Skipped)), zap.Int("Failed", len(result.Failed)))
+		writeAdminJSON(w, http.StatusOK, result)
+	case action == "" || action == "retrain" || action == "reset" || action == "predict" || action == "caps" || action == "params" || action == "pause" || action == "resume" || action == "actions" || action == "rollback":
+		writeAdminError(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		writeAdminError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (bs *Server) adminList(w http.ResponseWriter) {
+	bs.Repos.RLock()
+	ids := make([]int64, 0, len(bs.Repos.Actives))
+	repos := make(map[int64]*ArchRepo, len(bs.Repos.Actives))
+	for id, repo := range bs.Repos.Actives {
+		ids = append(ids, id)
+		repos[id] = repo
+	}
+	bs.Repos.RUnlock()
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	statuses := make([]RepoStatus, 0, len(ids))
+	for _, id := range ids {
+		repos[id].Lock()
+		statuses = append(statuses, repos[id].status(id, false))
+		repos[id].Unlock()
+	}
+	writeAdminJSON(w, http.StatusOK, statuses)
+}
+
+// status summarizes the repo; the caller must hold the repo lock.
+func (a *ArchRepo) status(repoID int64, detailed bool) RepoStatus {
+	status := RepoStatus{RepoID: repoID, Paused: a.Paused, Shadow: a.Settings.Shadow}
+	if a.Overrides != nil {
+		status.AssignmentOverrideRate = a.Overrides.AssignmentOverrideRate()
+		status.LabelOverrideRate = a.Overrides.LabelOverrideRate()
+	}
+	if a.Hive == nil || a.Hive.Blender == nil {
+		return status
+	}
+	b := a.Hive.Blender
+	status.Bootstrapped = len(b.Models) > 0 && b.AllModelsBootstrapped()
+	if !b.Trained.IsZero() {
+		trained := b.Trained
+		status.Trained = &trained
+	}
+	status.Version = b.Version()
+	if b.Conflator != nil {
+		status.Issues = len(b.Conflator.Context.Issues)
+	}
+	if detailed {
+		for i := 0; i < len(b.Models); i++ {
+			status.Classes = append(status.Classes, b.Models[i].Model.Classes()...)
+		}
+		status.Caps = copyCaps(a.EligibleAssignees)
+		status.Params = a.ModelParams.String()
+	}
+	return status
+}
+
+// reset drops the models and everything learned from bot actions; the next
+// worker tick trains the models from scratch.
+func (a *ArchRepo) reset() {
+	a.Hive.Blender.Reset(a.newModel)
+	a.Workload = NewWorkload()
+	a.Overrides = NewOverrides()
+	a.Suggestions = nil
+	a.CodeOwners = nil
+	a.CodeOwnersFetched = time.Time{}
+}
+
+// predict runs the models on an issue without acting on it. The models need
+// a number and URL, which an ad hoc issue may lack.
+func (a *ArchRepo) predict(issue github.Issue) Prediction {
+	a.Lock()
+	defer a.Unlock()
+	if issue.Number == nil {
+		issue.Number = github.Int(0)
+	}
+	if issue.URL == nil {
+		issue.URL = github.String("")
+	}
+	prediction := Prediction{Assignees: []string{}}
+	b := a.Hive.Blender
+	if len(b.Models) == 0 || !b.AllModelsBootstrapped() {
+		return prediction
+	}
+	input := conflation.ExpandedIssue{Issue: conflation.CRIssue{Issue: issue}}
+	prediction.Assignees, prediction.Confidences = b.PredictWithConfidence(input)
+	prediction.Explanations = b.Explain(input, ExplainTerms)
+	return prediction
+}
+
+// actionFilter reads the audit filter from the query string.
+func actionFilter(repoID int64, r *http.Request) (audit.Filter, error) {
+	query := r.URL.Query()
+	filter := audit.Filter{
+		RepoID:       repoID,
+		Action:       query.Get("action"),
+		ModelVersion: query.Get("version"),
+	}
+	var err error
+	if value := query.Get("number"); value != "" {
+		if filter.Number, err = strconv.Atoi(value); err != nil {
+			return filter, errors.New("invalid number")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			return filter, errors.New("invalid limit")
+		}
+	}
+	if value := query.Get("since"); value != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("invalid since")
+		}
+	}
+	if value := query.Get("until"); value != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("invalid until")
+		}
+	}
+	return filter, nil
+}
+
+func copyCaps(caps map[string]int) map[string]int {
+	copied := make(map[string]int, len(caps))
+	for assignee, cap := range caps {
+		copied[assignee] = cap
+	}
+	return copied
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		utils.AppLog.Error("admin response", zap.Error(err))
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeAdminJSON(w, status, adminError{Error: message})
+}
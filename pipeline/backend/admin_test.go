@@ -0,0 +1,172 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"core/models/bhattacharya"
+	"core/pipeline/gateway/conflation"
+	"core/utils"
+)
+
+func adminServer() *Server {
+	bs := &Server{Repos: &ActiveRepos{Actives: make(map[int64]*ArchRepo)}}
+	bs.NewArchRepo(66, HeuprConfigSettings{})
+	bs.NewArchRepo(7, HeuprConfigSettings{})
+	bs.Repos.Actives[7].Hive.Blender.Models = []*ArchModel{{Model: newAssignmentModel(nil, bhattacharya.Params{})}}
+	bs.Repos.Actives[7].EligibleAssignees = map[string]int{"hera": 10}
+	bs.Repos.Actives[7].Paused = true
+	return bs
+}
+
+func adminRequest(bs *Server, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	bs.AdminHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminAuthentication(t *testing.T) {
+	bs := adminServer()
+	defer func(token string) { utils.Config.BackendAdminToken = token }(utils.Config.BackendAdminToken)
+
+	utils.Config.BackendAdminToken = ""
+	if rec := adminRequest(bs, "GET", "/admin/repos", "", ""); rec.Code != http.StatusNotFound {
+		t.Error("\nADMIN API ENABLED WITHOUT TOKEN", "\nEXPECTED: ", http.StatusNotFound, "\nACTUAL:   ", rec.Code)
+	}
+
+	utils.Config.BackendAdminToken = "chopper"
+	cases := []struct {
+		token string
+		code  int
+	}{
+		{"", http.StatusUnauthorized},
+		{"ezra", http.StatusUnauthorized},
+		{"chopper", http.StatusOK},
+	}
+	for _, c := range cases {
+		if rec := adminRequest(bs, "GET", "/admin/repos", c.token, ""); rec.Code != c.code {
+			t.Error("\nUNEXPECTED ADMIN STATUS", "\nTOKEN:    ", c.token, "\nEXPECTED: ", c.code, "\nACTUAL:   ", rec.Code)
+		}
+	}
+
+	for _, header := range []string{"chopper", "Token chopper"} {
+		req := httptest.NewRequest("GET", "/admin/repos", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		bs.AdminHandler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Error("\nTOKEN ACCEPTED WITHOUT BEARER SCHEME", "\nHEADER:   ", header, "\nEXPECTED: ", http.StatusUnauthorized, "\nACTUAL:   ", rec.Code)
+		}
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	bs := adminServer()
+	defer func(token string) { utils.Config.BackendAdminToken = token }(utils.Config.BackendAdminToken)
+	utils.Config.BackendAdminToken = "chopper"
+
+	rec := adminRequest(bs, "GET", "/admin/repos", "chopper", "")
+	var statuses []RepoStatus
+	if err := json.NewDecoder(rec.Body).Decode(&statuses); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].RepoID != 7 || !statuses[0].Paused || statuses[1].RepoID != 66 {
+		t.Error("\nREPOS NOT LISTED", "\nACTUAL:   ", statuses)
+	}
+
+	rec = adminRequest(bs, "GET", "/admin/repos/7", "chopper", "")
+	var status RepoStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Bootstrapped || status.Trained != nil || status.Caps["hera"] != 10 {
+		t.Error("\nREPO STATUS INCORRECT", "\nACTUAL:   ", status)
+	}
+
+	bs.Repos.Actives[7].ModelParams = bhattacharya.Params{Smoother: 2, DisableTfIdf: true}
+	rec = adminRequest(bs, "GET", "/admin/repos/7/params", "chopper", "")
+	if params, err := bhattacharya.ReadParams(rec.Body); err != nil || params != bs.Repos.Actives[7].ModelParams {
+		t.Error("\nPARAMS NOT RETURNED", "\nACTUAL:   ", params, err)
+	}
+	if model := bs.Repos.Actives[7].newModel().Algorithm.(*bhattacharya.NBModel); model.Params.Smoother != 2 {
+		t.Error("\nPARAMS NOT APPLIED TO NEW MODELS", "\nACTUAL:   ", model.Params)
+	}
+
+	rec = adminRequest(bs, "POST", "/admin/repos/7/predict", "chopper", `{"title":"Hyperdrive motivator broken"}`)
+	var prediction Prediction
+	if err := json.NewDecoder(rec.Body).Decode(&prediction); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || len(prediction.Assignees) != 0 {
+		t.Error("\nUNTRAINED MODEL PREDICTED", "\nACTUAL:   ", rec.Code, prediction)
+	}
+
+	blender := bs.Repos.Actives[7].Hive.Blender
+	blender.Conflator = &conflation.Conflator{Context: &conflation.Context{}}
+	blender.Conflator.Context.Issues = []conflation.ExpandedIssue{
+		trainingIssue(1, "hera", "hyperdrive motivator broken again"),
+		trainingIssue(2, "kanan", "lightsaber crystal cracked"),
+	}
+	blender.TrainModels()
+	rec = adminRequest(bs, "POST", "/admin/repos/7/predict", "chopper", `{"body":"hyperdrive motivator broken"}`)
+	prediction = Prediction{}
+	if err := json.NewDecoder(rec.Body).Decode(&prediction); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || len(prediction.Assignees) == 0 || prediction.Assignees[0] != "hera" {
+		t.Error("\nTRAINED MODEL NOT PREDICTED", "\nACTUAL:   ", rec.Code, prediction)
+	}
+	if rec = adminRequest(bs, "GET", "/admin/repos/7/caps", "chopper", ""); rec.Code != http.StatusOK {
+		t.Error("\nREPO LOCKED AFTER PREDICT", "\nACTUAL:   ", rec.Code)
+	}
+
+	bs.Repos.Actives[7].Workload.Assigned("hera", 3, time.Now())
+	adminRequest(bs, "POST", "/admin/repos/7/reset", "chopper", "")
+	if bs.Repos.Actives[7].Workload.Open("hera") != 0 {
+		t.Error("\nWORKLOAD NOT RESET")
+	}
+
+	routes := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{"GET", "/admin/repos/8", http.StatusNotFound},
+		{"GET", "/admin/repos/sabine", http.StatusNotFound},
+		{"GET", "/admin/repos/7/hyperspace", http.StatusNotFound},
+		{"DELETE", "/admin/repos/7/pause", http.StatusMethodNotAllowed},
+		{"POST", "/admin/repos", http.StatusMethodNotAllowed},
+		{"PUT", "/admin/repos/7/caps", http.StatusBadRequest},
+		{"PUT", "/admin/repos/7/params", http.StatusBadRequest},
+		{"DELETE", "/admin/repos/7/params", http.StatusMethodNotAllowed},
+	}
+	for _, r := range routes {
+		if rec := adminRequest(bs, r.method, r.path, "chopper", ""); rec.Code != r.code {
+			t.Error("\nUNEXPECTED ADMIN STATUS", "\nROUTE:    ", r.method, r.path, "\nEXPECTED: ", r.code, "\nACTUAL:   ", rec.Code)
+		}
+	}
+}
+
+func TestActionFilter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/admin/repos/7/actions?number=4&action=add_labels&since=1977-05-25T00:00:00Z&limit=20", nil)
+	filter, err := actionFilter(7, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC)
+	if filter.RepoID != 7 || filter.Number != 4 || filter.Action != "add_labels" || !filter.Since.Equal(since) || filter.Limit != 20 {
+		t.Error("\nACTION FILTER NOT PARSED", "\nACTUAL:   ", filter)
+	}
+	req = httptest.NewRequest("GET", "/admin/repos/7/actions?until=yesterday", nil)
+	if _, err := actionFilter(7, req); err == nil {
+		t.Error("\nINVALID ACTION FILTER ACCEPTED")
+	}
+}
@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"core/utils"
+	"errors"
 	"net/http"
 	"strings"
 	"sync"
@@ -13,7 +14,12 @@ import (
 	"go.uber.org/zap"
 
 	"core/models"
+	"core/models/bhattacharya"
 	"core/models/labelmaker"
+	"core/models/ownership"
+	"core/pipeline/audit"
+	"core/pipeline/command"
+	"core/pipeline/gateway"
 	"core/pipeline/gateway/conflation"
 )
 
@@ -30,6 +36,20 @@ type Blender struct {
 	Conflator *conflation.Conflator
 	// MVP: Moving the Conflator from ArchModel to Blender. We might just
 	// need to circle back to this.
+	Trained time.Time
+	// RepoID and Cursor, the id of the newest event read for the repo, are
+	// recorded by the models once trained (see models.CursorAlgorithm).
+	RepoID int64
+	Cursor int64
+	// Corrected holds the numbers of the issues the models already learned
+	// from a maintainer's correction, so they are not learned again once
+	// closed.
+	Corrected map[int]bool
+	// ModelVersion is incremented whenever the models change: a training
+	// pass or correction that learned issues, a reset or a retrain (e.g.
+	// after a params change). It is recorded by the models (see
+	// models.VersionAlgorithm) and audited with every action.
+	ModelVersion int64
 }
 type ArchHive struct {
 	Blender *Blender
@@ -37,14 +57,28 @@ type ArchHive struct {
 
 type ArchRepo struct {
 	sync.Mutex
-	Hive                     *ArchHive
-	Labelmaker               *labelmaker.LBModel
-	Labels                   []string
-	Client                   *github.Client
-	Limit                    time.Time
-	AssigneeAllocations      map[string]int
-	EligibleAssignees        map[string]int
-	Settings                 HeuprConfigSettings
+	Hive                *ArchHive
+	Labelmaker          *labelmaker.LBModel
+	Ownership           *ownership.Index
+	CodeOwners          *ownership.CodeOwners
+	CodeOwnersFetched   time.Time
+	Workload            *Workload
+	Policy              AssignmentPolicy
+	Suggestions         map[int]suggestionComment
+	Overrides           *Overrides
+	Paused              bool
+	ModelParams         bhattacharya.Params
+	Shadowed            []ShadowAction
+	Actions             []audit.Action
+	Labels              []string
+	Client              *github.Client
+	Limit               time.Time
+	AssigneeAllocations map[string]int
+	EligibleAssignees   map[string]int
+	Settings            HeuprConfigSettings
+	// Clock returns the current time; time.Now if nil. The replay
+	// simulator sets it to the simulated time.
+	Clock                    func() time.Time
 	TriagedLabelEnabledCheck bool          //TEMPORARY FIX
 	TriagedLabel             *github.Label //TEMPORARY FIX
 	TriagedLabelEnabled      bool          //TEMPORARY FIX
@@ -56,8 +90,10 @@ func (s *Server) NewArchRepo(repoID int64, settings HeuprConfigSettings) {
 
 	s.Repos.Actives[repoID] = new(ArchRepo)
 	s.Repos.Actives[repoID].Hive = new(ArchHive)
-	s.Repos.Actives[repoID].Hive.Blender = new(Blender)
+	s.Repos.Actives[repoID].Hive.Blender = &Blender{RepoID: repoID}
 	s.Repos.Actives[repoID].Settings = settings
+	s.Repos.Actives[repoID].Workload = NewWorkload()
+	s.Repos.Actives[repoID].Overrides = NewOverrides()
 }
 
 func (s *Server) NewClient(repoID int64, appID int, installationID int64) {
@@ -80,11 +116,46 @@ func (s *Server) NewClient(repoID int64, appID int, installationID int64) {
 	s.Repos.Actives[repoID].Client = client
 }
 
+// UpdateOwnership indexes the files changed by newly merged pull requests so
+// that issues mentioning those files favor their authors. Pull requests whose
+// files could not be fetched, or that exceed the per call bound, are retried
+// on the next call.
+func (a *ArchRepo) UpdateOwnership(pulls []*github.PullRequest) {
+	if a.Ownership == nil || a.Client == nil {
+		return
+	}
+	if len(pulls) == 0 {
+		pulls = a.Ownership.Queued()
+	}
+	if len(pulls) == 0 {
+		return
+	}
+	if pulls[0].Base == nil || pulls[0].Base.Repo == nil || pulls[0].Base.Repo.FullName == nil {
+		utils.AppLog.Error("UpdateOwnership() pull request missing base repository")
+		return
+	}
+	r := strings.Split(*pulls[0].Base.Repo.FullName, "/")
+	g := &gateway.Gateway{Client: a.Client}
+	if queued := a.Ownership.AddPulls(g, r[0], r[1], pulls); len(queued) > 0 {
+		utils.AppLog.Info("UpdateOwnership() pull requests queued for retry", zap.String("Repo", *pulls[0].Base.Repo.FullName), zap.Ints("Numbers", queued))
+	}
+}
+
 func (a *ArchRepo) ApplyLabelsOnOpenIssues() {
+	if a.Paused {
+		return
+	}
 	if a.Labelmaker == nil {
 		utils.AppLog.Error("labelmaker not bootstrapped yet.")
 		return
 	}
+	if a.Settings.AssignmentPolicy == SuggestOnlyPolicy {
+		// Labels are only applied once a suggestion is accepted.
+		return
+	}
+	if a.Overrides == nil {
+		a.Overrides = NewOverrides()
+	}
 	openIssues := a.Hive.Blender.GetAllOpenIssues()
 	utils.AppLog.Info("ApplyLabelsOnOpenIssues()", zap.Int("Total", len(openIssues)))
 	if len(openIssues) == 0 {
@@ -101,7 +172,14 @@ func (a *ArchRepo) ApplyLabelsOnOpenIssues() {
 	skip := false
 	if len(a.Settings.DefaultLabels) > 0 {
 		for i := 0; i < len(openIssues); i++ {
+			if a.Settings.Shadow {
+				for _, label := range a.Settings.DefaultLabels {
+					a.shadow(*openIssues[i].Issue.Number, ShadowDefaultLabel, label, 0)
+				}
+				continue
+			}
 			_, _, err := a.Client.Issues.AddLabelsToIssue(context.Background(), repo[0], repo[1], *openIssues[i].Issue.Number, a.Settings.DefaultLabels)
+			a.record(*openIssues[i].Issue.Number, audit.AddLabels, a.Settings.DefaultLabels, nil, err)
 			if err != nil {
 				utils.AppLog.Error("failure adding default issue labels", zap.Error(err))
 			}
@@ -125,10 +203,15 @@ func (a *ArchRepo) ApplyLabelsOnOpenIssues() {
 				skip = false
 				continue
 			}
-			if label != nil && *label != "" {
+			if label != nil && *label != "" && a.Settings.Shadow {
+				a.shadow(*openIssues[i].Issue.Number, ShadowLabel, *label, 0)
+			} else if label != nil && *label != "" {
 				_, _, err := a.Client.Issues.AddLabelsToIssue(context.Background(), repo[0], repo[1], *openIssues[i].Issue.Number, []string{*label})
+				a.record(*openIssues[i].Issue.Number, audit.AddLabels, []string{*label}, nil, err)
 				if err != nil {
 					utils.AppLog.Error("failure adding user-defined issue labels", zap.Error(err))
+				} else {
+					a.Overrides.Labeled(*openIssues[i].Issue.Number, *label)
 				}
 			}
 		}
@@ -136,13 +219,19 @@ func (a *ArchRepo) ApplyLabelsOnOpenIssues() {
 }
 
 func (a *ArchRepo) TriageOpenIssues() {
-	if !a.Settings.EnableTriager {
+	if !a.Settings.EnableTriager || a.Paused {
 		return
 	}
 	if !a.Hive.Blender.AllModelsBootstrapped() {
 		utils.AppLog.Error("!AllModelsBootstrapped()")
 		return
 	}
+	if a.Workload == nil {
+		a.Workload = NewWorkload()
+	}
+	if a.Overrides == nil {
+		a.Overrides = NewOverrides()
+	}
 	openIssues := a.Hive.Blender.GetOpenIssues()
 	utils.AppLog.Info("TriageOpenIssues()", zap.Int("Total", len(openIssues)))
 	if len(openIssues) == 0 {
@@ -155,6 +244,7 @@ func (a *ArchRepo) TriageOpenIssues() {
 		name = *openIssues[0].Issue.Repository.Name
 	}
 	r := strings.Split(name, "/")
+	a.RefreshCodeOwners(r[0], r[1])
 
 	//TEMPORARY FIX
 	var label *github.Label
@@ -167,13 +257,14 @@ func (a *ArchRepo) TriageOpenIssues() {
 		a.TriagedLabel = lbl
 	}
 	label = a.TriagedLabel
+	policy := a.policy()
 
 	for i := 0; i < len(openIssues); i++ {
 		if openIssues[i].Issue.CreatedAt.After(a.Settings.StartTime) {
 			labelValid := true
 			labels := openIssues[i].Issue.Labels
 			for j := 0; j < len(labels); j++ {
-				if _, ok := a.Settings.IgnoreLabels[*labels[j].Name]; ok {
+				if _, ok := a.Settings.IgnoreLabels[*labels[j].Name]; ok || *labels[j].Name == command.IgnoreLabel {
 					labelValid = false
 					break
 				}
@@ -181,93 +272,149 @@ func (a *ArchRepo) TriageOpenIssues() {
 			if !labelValid {
 				continue
 			}
-			*openIssues[i].Issue.Triaged = true
-			assignees := a.Hive.Blender.Predict(openIssues[i])
+			predictions, scores := a.Hive.Blender.PredictWithConfidence(openIssues[i])
+			confidences := make(map[string]float64)
+			for j := 0; j < len(predictions) && j < len(scores); j++ {
+				confidences[predictions[j]] = scores[j]
+			}
+			predictions, owners := applyCodeOwners(a.Settings.CodeOwners, predictions, a.issueOwners(openIssues[i]))
+			decision := policy.Decide(AssignmentRequest{
+				Predictions:       predictions,
+				Owners:            owners,
+				Allocations:       a.AssigneeAllocations,
+				EligibleAssignees: a.EligibleAssignees,
+				Settings:          a.Settings,
+				Workload:          a.Workload,
+				Now:               a.now(),
+			})
 			number := *openIssues[i].Issue.Number
-			fallbackAssignee := ""
+			if a.Settings.Shadow {
+				if decision.SuggestOnly {
+					a.shadow(number, ShadowSuggestion, strings.Join(decision.Candidates, ","), 0)
+				} else if !a.shadowAssign(number, decision, confidences, label != nil && *label.Name == "triaged") {
+					utils.AppLog.Error("Shadow assignment failed. Fallback assignee not found.", zap.String("URL", *openIssues[i].Issue.URL), zap.Int64("IssueID", *openIssues[i].Issue.ID))
+				}
+				*openIssues[i].Issue.Triaged = true
+				continue
+			}
+			if decision.SuggestOnly {
+				utils.AppLog.Info("TriageOpenIssues() suggestions", zap.Int("Number", number), zap.Strings("Assignees", decision.Candidates))
+				a.suggest(r[0], r[1], openIssues[i], decision.Candidates, confidences)
+				*openIssues[i].Issue.Triaged = true
+				continue
+			}
+			// The issue is only marked triaged once an assignee was applied so
+			// that issues nobody could be assigned are retried on the next run.
 			assigned := false
-			for i := 0; i < len(assignees); i++ {
-				assignee := assignees[i]
-				if _, ok := a.Settings.IgnoreUsers[assignee]; ok {
-					continue
+			for _, assignee := range decision.Candidates {
+				ok, err := a.assign(r[0], r[1], number, assignee, label, confidences)
+				if err != nil {
+					break
 				}
-				if assignmentsCap, ok := a.EligibleAssignees[assignee]; ok {
-					if fallbackAssignee == "" {
-						fallbackAssignee = assignee
-					}
-					if _, ok := a.AssigneeAllocations[assignee]; !ok {
-						a.AssigneeAllocations[assignee] = 0
-					}
-					if assignmentsCount, ok := a.AssigneeAllocations[assignee]; ok {
-						if assignmentsCount < assignmentsCap {
-							issue, _, err := a.Client.Issues.AddAssignees(context.Background(), r[0], r[1], number, []string{assignee})
-							if err != nil {
-								utils.AppLog.Error("AddAssignees Failed", zap.Error(err))
-								if _, ok := err.(*github.RateLimitError); ok {
-									time.Sleep(15 * time.Minute)
-									limits, _, _ := a.Client.RateLimits(context.Background())
-									if limits != nil {
-										limit := limits.Core.Limit
-										remaining := limits.Core.Remaining
-										utils.AppLog.Info("RateLimits()", zap.Int("Limit", limit), zap.Int("Remaining", remaining))
-									}
-									issue, _, err = a.Client.Issues.AddAssignees(context.Background(), r[0], r[1], number, []string{assignee})
-									if err != nil {
-										break
-									}
-								} else {
-									break
-								}
-							}
-
-							if issue.Assignees == nil || len(issue.Assignees) == 0 {
-								if fallbackAssignee == assignee {
-									fallbackAssignee = ""
-								}
-								continue
-							}
-
-							if label != nil {
-								if *label.Name == "triaged" {
-									_, _, err := a.Client.Issues.AddLabelsToIssue(context.Background(), r[0], r[1], number, []string{*label.Name})
-									if err != nil {
-										utils.AppLog.Error("AddLabelsToIssue failed for primary assignee", zap.Error(err))
-									}
-								}
-							}
-
-							assigned = true
-							assignmentsCount++
-							a.AssigneeAllocations[assignee] = assignmentsCount
-							break
-						}
-					}
+				if ok {
+					assigned = true
+					break
+				}
+				if decision.Fallback == assignee {
+					decision.Fallback = ""
 				}
 			}
 			if !assigned {
-				if fallbackAssignee == "" {
+				if decision.Fallback == "" {
 					utils.AppLog.Error("AddAssignees Failed. Fallback assignee not found.", zap.String("URL", *openIssues[i].Issue.URL), zap.Int64("IssueID", *openIssues[i].Issue.ID))
-					break
+					continue
 				}
-				_, _, err := a.Client.Issues.AddAssignees(context.Background(), r[0], r[1], number, []string{fallbackAssignee})
+				ok, err := a.assign(r[0], r[1], number, decision.Fallback, label, confidences)
 				if err != nil {
-					utils.AppLog.Error("AddAssignees Failed", zap.Error(err))
 					break
 				}
-				if label != nil {
-					if *label.Name == "triaged" {
-						_, _, err := a.Client.Issues.AddLabelsToIssue(context.Background(), r[0], r[1], number, []string{*label.Name})
-						if err != nil {
-							utils.AppLog.Error("AddLabelsToIssue failed for fallback assignee", zap.Error(err))
-						}
-					}
+				if !ok {
+					utils.AppLog.Error("AddAssignees Failed. Fallback assignee not accepted.", zap.String("URL", *openIssues[i].Issue.URL), zap.Int64("IssueID", *openIssues[i].Issue.ID))
+					continue
 				}
-				assigned = true
-				a.AssigneeAllocations[fallbackAssignee]++
 				utils.AppLog.Info("AddAssignees Success. Fallback assignee found.", zap.String("URL", *openIssues[i].Issue.URL), zap.Int64("IssueID", *openIssues[i].Issue.ID))
 			}
+			*openIssues[i].Issue.Triaged = true
+		}
+	}
+}
+
+// allocate counts an assignment against the developer's cap, both in the
+// allocations checked by the greedy policy and in the Workload.
+func (a *ArchRepo) allocate(assignee string, number int) {
+	if a.AssigneeAllocations == nil {
+		a.AssigneeAllocations = make(map[string]int)
+	}
+	a.AssigneeAllocations[assignee]++
+	a.Workload.Assigned(assignee, number, a.now())
+}
+
+// now returns the current time of the repo's Clock.
+func (a *ArchRepo) now() time.Time {
+	if a.Clock != nil {
+		return a.Clock()
+	}
+	return time.Now()
+}
+
+// policy returns the assignment policy selected in the repo settings,
+// replacing the current one when the setting changes.
+func (a *ArchRepo) policy() AssignmentPolicy {
+	selected := NewAssignmentPolicy(a.Settings.AssignmentPolicy)
+	if a.Policy == nil || a.Policy.Name() != selected.Name() {
+		a.Policy = selected
+	}
+	return a.Policy
+}
+
+// errNotAccepted audits an assignee GitHub silently dropped (e.g. a user
+// without push access).
+var errNotAccepted = errors.New("assignee not accepted")
+
+// assign adds the assignee to the issue (retrying once after a rate limit)
+// and applies the triaged label. It reports whether GitHub accepted the
+// assignee; an error means no further assignment should be attempted.
+func (a *ArchRepo) assign(owner, repo string, number int, assignee string, label *github.Label, scores map[string]float64) (bool, error) {
+	issue, _, err := a.Client.Issues.AddAssignees(context.Background(), owner, repo, number, []string{assignee})
+	if err != nil {
+		a.record(number, audit.AddAssignees, []string{assignee}, scores, err)
+		utils.AppLog.Error("AddAssignees Failed", zap.Error(err))
+		if _, ok := err.(*github.RateLimitError); !ok {
+			return false, err
+		}
+		time.Sleep(15 * time.Minute)
+		limits, _, _ := a.Client.RateLimits(context.Background())
+		if limits != nil {
+			limit := limits.Core.Limit
+			remaining := limits.Core.Remaining
+			utils.AppLog.Info("RateLimits()", zap.Int("Limit", limit), zap.Int("Remaining", remaining))
+		}
+		issue, _, err = a.Client.Issues.AddAssignees(context.Background(), owner, repo, number, []string{assignee})
+		if err != nil {
+			a.record(number, audit.AddAssignees, []string{assignee}, scores, err)
+			return false, err
+		}
+	}
+
+	if issue.Assignees == nil || len(issue.Assignees) == 0 {
+		a.record(number, audit.AddAssignees, []string{assignee}, scores, errNotAccepted)
+		return false, nil
+	}
+	a.record(number, audit.AddAssignees, []string{assignee}, scores, nil)
+
+	if label != nil {
+		if *label.Name == "triaged" {
+			_, _, err := a.Client.Issues.AddLabelsToIssue(context.Background(), owner, repo, number, []string{*label.Name})
+			a.record(number, audit.AddLabels, []string{*label.Name}, nil, err)
+			if err != nil {
+				utils.AppLog.Error("AddLabelsToIssue failed", zap.String("Assignee", assignee), zap.Error(err))
+			}
 		}
 	}
+
+	a.allocate(assignee, number)
+	a.Overrides.Assigned(number, assignee)
+	return true, nil
 }
 
 func (b *Blender) Predict(issue conflation.ExpandedIssue) []string {
@@ -278,6 +425,23 @@ func (b *Blender) Predict(issue conflation.ExpandedIssue) []string {
 	return assignees
 }
 
+func (b *Blender) PredictWithConfidence(issue conflation.ExpandedIssue) ([]string, []float64) {
+	var assignees []string
+	var confidences []float64
+	for i := 0; i < len(b.Models); i++ {
+		assignees, confidences = b.Models[i].Model.PredictWithConfidence(issue)
+	}
+	return assignees, confidences
+}
+
+func (b *Blender) Explain(issue conflation.ExpandedIssue, top int) []bhattacharya.Explanation {
+	var explanations []bhattacharya.Explanation
+	for i := 0; i < len(b.Models); i++ {
+		explanations = b.Models[i].Model.Explain(issue, top)
+	}
+	return explanations
+}
+
 func (b *Blender) GetOpenIssues() []conflation.ExpandedIssue {
 	openIssues := []conflation.ExpandedIssue{}
 	issues := b.Conflator.Context.Issues
@@ -307,19 +471,30 @@ func (b *Blender) GetClosedIssues() []conflation.ExpandedIssue {
 	issues := b.Conflator.Context.Issues
 	for i := 0; i < len(issues); i++ {
 		if issues[i].Issue.ClosedAt != nil && issues[i].Conflate && !issues[i].IsTrained && *issues[i].Issue.User.Login != "heupr" {
-			closedIssues = append(closedIssues, issues[i])
 			issues[i].IsTrained = true
+			if issues[i].Issue.Number != nil && b.Corrected[*issues[i].Issue.Number] {
+				continue
+			}
+			closedIssues = append(closedIssues, issues[i])
 		}
 	}
 	return closedIssues
 }
 
 func (b *Blender) TrainModels() {
+	if b.train() {
+		b.bump()
+	}
+}
+
+// train learns the closed issues not learned yet and reports whether there
+// were any.
+func (b *Blender) train() bool {
 	closedIssues := b.GetClosedIssues()
 	utils.AppLog.Info("TrainModels() ", zap.Int("Total", len(closedIssues)))
 	if len(closedIssues) == 0 {
 		//TODO: Add Logging
-		return
+		return false
 	}
 	for i := 0; i < len(b.Models); i++ {
 		if b.Models[i].Model.IsBootstrapped() {
@@ -327,7 +502,45 @@ func (b *Blender) TrainModels() {
 		} else {
 			b.Models[i].Model.Learn(closedIssues)
 		}
+		b.Models[i].Model.SetCursor(b.RepoID, b.Cursor)
 	}
+	b.Trained = time.Now()
+	return true
+}
+
+// bump increments the model version and records it in the models.
+func (b *Blender) bump() {
+	b.ModelVersion++
+	for i := 0; i < len(b.Models); i++ {
+		b.Models[i].Model.SetVersion(b.ModelVersion)
+	}
+}
+
+// Reset replaces every model with an untrained one and marks every issue as
+// untrained so the next training pass learns all closed issues from scratch.
+func (b *Blender) Reset(newModel func() *models.Model) {
+	b.reset(newModel)
+	b.bump()
+}
+
+func (b *Blender) reset(newModel func() *models.Model) {
+	for i := 0; i < len(b.Models); i++ {
+		b.Models[i].Model = newModel()
+	}
+	if b.Conflator != nil {
+		for i := 0; i < len(b.Conflator.Context.Issues); i++ {
+			b.Conflator.Context.Issues[i].IsTrained = false
+		}
+	}
+	b.Corrected = nil
+	b.Trained = time.Time{}
+}
+
+// Retrain resets the models and trains them again immediately.
+func (b *Blender) Retrain(newModel func() *models.Model) {
+	b.reset(newModel)
+	b.train()
+	b.bump()
 }
 
 func (b *Blender) AllModelsBootstrapped() bool {
@@ -1,7 +1,15 @@
 package backend
 
 import (
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/models"
+	"core/models/bhattacharya"
+	"core/pipeline/gateway/conflation"
 )
 
 const repoID = 66
@@ -23,3 +31,86 @@ func TestNewArchRepo(t *testing.T) {
 func TestApplyLabelsOnOpenIssues(t *testing.T) {
 
 }
+
+func trainingIssue(number int, assignee, body string) conflation.ExpandedIssue {
+	closed := time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC).AddDate(0, 0, number)
+	triaged, labeled := true, false
+	return conflation.ExpandedIssue{
+		Issue: conflation.CRIssue{
+			Issue: github.Issue{
+				Number:    github.Int(number),
+				URL:       github.String("https://api.github.com/repos/rebellion/x-wing/issues/" + strconv.Itoa(number)),
+				Body:      github.String(body),
+				ClosedAt:  &closed,
+				User:      &github.User{Login: github.String("mon-mothma")},
+				Assignees: []*github.User{{Login: github.String(assignee)}},
+			},
+			Triaged: &triaged,
+			Labeled: &labeled,
+		},
+		Conflate: true,
+	}
+}
+
+func TestTrainModelsCursor(t *testing.T) {
+	blender := &Blender{
+		Models:    []*ArchModel{{Model: newAssignmentModel(nil, bhattacharya.Params{})}},
+		Conflator: &conflation.Conflator{Context: &conflation.Context{}},
+		RepoID:    repoID,
+		Cursor:    1138,
+	}
+	blender.Conflator.Context.Issues = []conflation.ExpandedIssue{
+		trainingIssue(1, "luke", "targeting computer"),
+		trainingIssue(2, "wedge", "stabilizer broken"),
+	}
+	blender.TrainModels()
+	model := blender.Models[0].Model.Algorithm.(*bhattacharya.NBModel)
+	if model.RepoID != repoID || model.TrainingCursor != 1138 {
+		t.Error(
+			"\nRECOVERY HEADER NOT SET",
+			"\nEXPECTED: ", repoID, 1138,
+			"\nACTUAL:   ", model.RepoID, model.TrainingCursor,
+		)
+	}
+}
+
+func TestModelVersion(t *testing.T) {
+	newModel := func() *models.Model { return newAssignmentModel(nil, bhattacharya.Params{}) }
+	blender := &Blender{
+		Models:    []*ArchModel{{Model: newModel()}},
+		Conflator: &conflation.Conflator{Context: &conflation.Context{}},
+	}
+	blender.Conflator.Context.Issues = []conflation.ExpandedIssue{
+		trainingIssue(1, "luke", "targeting computer"),
+		trainingIssue(2, "wedge", "stabilizer broken"),
+	}
+	steps := []struct {
+		name    string
+		change  func()
+		version string
+	}{
+		{"TRAIN", blender.TrainModels, "1"},
+		{"NOTHING NEW TO TRAIN", blender.TrainModels, "1"},
+		{"CORRECTION", func() { blender.LearnCorrections([]conflation.ExpandedIssue{trainingIssue(3, "biggs", "torpedo jammed")}) }, "2"},
+		{"RETRAIN", func() { blender.Retrain(newModel) }, "3"},
+		{"RESET", func() { blender.Reset(newModel) }, "4"},
+	}
+	for _, step := range steps {
+		step.change()
+		if blender.Version() != step.version {
+			t.Error(
+				"\nMODEL VERSION NOT INCREMENTED ON CHANGE",
+				"\nSTEP:     ", step.name,
+				"\nEXPECTED: ", step.version,
+				"\nACTUAL:   ", blender.Version(),
+			)
+		}
+	}
+	if version := blender.Models[0].Model.Version(); version != 4 {
+		t.Error(
+			"\nMODEL VERSION NOT RECORDED IN MODEL",
+			"\nEXPECTED: ", 4,
+			"\nACTUAL:   ", version,
+		)
+	}
+}
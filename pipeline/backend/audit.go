@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"strconv"
+
+	"core/pipeline/audit"
+	"core/pipeline/suggestion"
+)
+
+// Version identifies the models by their ModelVersion; it is empty until
+// they first change.
+func (b *Blender) Version() string {
+	if b.ModelVersion == 0 {
+		return ""
+	}
+	return strconv.FormatInt(b.ModelVersion, 10)
+}
+
+// record audits a GitHub mutation for the worker to store, along with the
+// model version and settings it was made under.
+func (a *ArchRepo) record(number int, action string, targets []string, scores map[string]float64, err error) {
+	entry := audit.New(0, number, action, targets, err)
+	entry.Scores = scores
+	entry.Settings = audit.Snapshot(a.Settings)
+	if a.Hive != nil && a.Hive.Blender != nil {
+		entry.ModelVersion = a.Hive.Blender.Version()
+	}
+	a.Actions = append(a.Actions, entry)
+}
+
+// recordComment audits a comment along with its body.
+func (a *ArchRepo) recordComment(number int, action, body string, scores map[string]float64, err error) {
+	a.record(number, action, nil, scores, err)
+	a.Actions[len(a.Actions)-1].Body = body
+}
+
+// recordAccepted audits the assignee and label applied when a maintainer
+// accepted a suggestion.
+func (a *ArchRepo) recordAccepted(number int, acceptedBy, assignee string, s suggestion.Suggestion, err error) {
+	a.record(number, audit.AddAssignees, []string{assignee}, s.Confidences(), err)
+	a.Actions[len(a.Actions)-1].Actor = acceptedBy
+	if s.Label != "" {
+		a.record(number, audit.AddLabels, []string{s.Label}, nil, err)
+		a.Actions[len(a.Actions)-1].Actor = acceptedBy
+	}
+}
+
+// FlushActions returns and clears the audited actions.
+func (a *ArchRepo) FlushActions() []audit.Action {
+	actions := a.Actions
+	a.Actions = nil
+	return actions
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"core/pipeline/backend"
+)
+
+// This program reverts the assignees and labels Heupr added to a repo through
+// the backend admin API, skipping anything a human has changed since.
+// Example: ./rollback -Repo 66 -Since 2018-06-01T00:00:00Z -DryRun
+func main() {
+	server := flag.String("Server", "http://10.142.1.0:8030", "backend server address")
+	token := flag.String("Token", os.Getenv("HEUPR_ADMIN_TOKEN"), "admin API token (defaults to $HEUPR_ADMIN_TOKEN)")
+	repoID := flag.Int64("Repo", 0, "repo ID to roll back")
+	since := flag.String("Since", "", "revert actions at or after this RFC 3339 time")
+	until := flag.String("Until", "", "revert actions before this RFC 3339 time")
+	version := flag.String("Version", "", "revert actions made by this model version")
+	dryRun := flag.Bool("DryRun", false, "report what would be reverted without changing anything")
+	flag.Parse()
+
+	if *repoID == 0 || (*since == "" && *until == "" && *version == "") {
+		log.Fatal("Please specify a Repo and a Since/Until range or a Version. Example ./rollback -Repo 66 -Since 2018-06-01T00:00:00Z -DryRun")
+	}
+	request := backend.RollbackRequest{Version: *version, DryRun: *dryRun}
+	var err error
+	if *since != "" {
+		if request.Since, err = time.Parse(time.RFC3339, *since); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *until != "" {
+		if request.Until, err = time.Parse(time.RFC3339, *until); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		log.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%v/admin/repos/%v/rollback", *server, *repoID), bytes.NewReader(body))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("rollback failed: %v %s", resp.Status, payload)
+	}
+
+	var result backend.RollbackResult
+	if err := json.Unmarshal(payload, &result); err != nil {
+		log.Fatal(err)
+	}
+	verb := "Reverted"
+	if result.DryRun {
+		verb = "Would revert"
+	}
+	for _, step := range result.Reverted {
+		fmt.Printf("%v #%v %v %v\n", verb, step.Number, step.Kind, step.Target)
+	}
+	for _, step := range result.Skipped {
+		fmt.Printf("Skipped #%v %v %v: %v\n", step.Number, step.Kind, step.Target, step.Reason)
+	}
+	for _, step := range result.Failed {
+		fmt.Printf("Failed #%v %v %v: %v\n", step.Number, step.Kind, step.Target, step.Reason)
+	}
+	fmt.Printf("%v %v, skipped %v, failed %v\n", verb, len(result.Reverted), len(result.Skipped), len(result.Failed))
+	if len(result.Failed) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,119 @@
+package backend
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"core/models/ownership"
+	"core/models/preprocess"
+	"core/pipeline/gateway"
+	"core/pipeline/gateway/conflation"
+	"core/utils"
+)
+
+// CodeOwnersMode controls how the owners (per the repository CODEOWNERS
+// file) of the paths mentioned in an issue affect assignment.
+type CodeOwnersMode string
+
+const (
+	// CodeOwnersOff ignores the CODEOWNERS file.
+	CodeOwnersOff CodeOwnersMode = ""
+	// CodeOwnersFilter only assigns owners; predicted owners are tried first.
+	// Issues mentioning no owned path (or only team and email owners) are
+	// assigned from the predictions as usual. Issues whose owners are all
+	// ignored or not eligible are left unassigned and untriaged, so they are
+	// retried once an owner becomes eligible.
+	CodeOwnersFilter CodeOwnersMode = "filter"
+	// CodeOwnersBoost moves predicted owners ahead of the other predictions.
+	CodeOwnersBoost CodeOwnersMode = "boost"
+	// CodeOwnersFallback assigns the first owner when no prediction is under
+	// its assignment cap.
+	CodeOwnersFallback CodeOwnersMode = "fallback"
+)
+
+// CodeOwnersRefresh is how long a fetched CODEOWNERS file is reused.
+const CodeOwnersRefresh = 24 * time.Hour
+
+func (a *ArchRepo) RefreshCodeOwners(owner, repo string) {
+	if a.Settings.CodeOwners == CodeOwnersOff || a.Client == nil {
+		return
+	}
+	if a.CodeOwners != nil && a.now().Sub(a.CodeOwnersFetched) < CodeOwnersRefresh {
+		return
+	}
+	a.CodeOwnersFetched = a.now()
+	g := &gateway.Gateway{Client: a.Client}
+	content, err := g.GetCodeOwners(owner, repo, ownership.CodeOwnersLocations)
+	if err != nil {
+		utils.AppLog.Error("RefreshCodeOwners() fetch", zap.String("RepoName", owner+"/"+repo), zap.Error(err))
+		return
+	}
+	codeOwners, err := ownership.ParseCodeOwners(bytes.NewReader(content))
+	if err != nil {
+		utils.AppLog.Error("RefreshCodeOwners() parse", zap.String("RepoName", owner+"/"+repo), zap.Error(err))
+		return
+	}
+	a.CodeOwners = codeOwners
+}
+
+// issueOwners returns the individual owners of the paths mentioned in the
+// issue. Team and email owners cannot be assigned and are skipped.
+func (a *ArchRepo) issueOwners(issue conflation.ExpandedIssue) []string {
+	if a.CodeOwners == nil || issue.Issue.Body == nil {
+		return nil
+	}
+	owners := []string{}
+	paths := preprocess.Parse(*issue.Issue.Body).Paths
+	for _, owner := range a.CodeOwners.IssueOwners(paths) {
+		if strings.ContainsAny(owner, "/@") {
+			continue
+		}
+		if _, ok := a.Settings.IgnoreUsers[owner]; ok {
+			continue
+		}
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+// applyCodeOwners reorders the predicted assignees according to the mode and
+// returns the owners to fall back on when every prediction is at its cap;
+// the policy only falls back on those that are eligible and under their cap.
+func applyCodeOwners(mode CodeOwnersMode, assignees, owners []string) ([]string, []string) {
+	if mode == CodeOwnersOff || len(owners) == 0 {
+		return assignees, nil
+	}
+	isOwner := make(map[string]bool)
+	for _, owner := range owners {
+		isOwner[owner] = true
+	}
+	predictedOwners := []string{}
+	others := []string{}
+	predicted := make(map[string]bool)
+	for _, assignee := range assignees {
+		predicted[assignee] = true
+		if isOwner[assignee] {
+			predictedOwners = append(predictedOwners, assignee)
+		} else {
+			others = append(others, assignee)
+		}
+	}
+
+	switch mode {
+	case CodeOwnersFilter:
+		for _, owner := range owners {
+			if !predicted[owner] {
+				predictedOwners = append(predictedOwners, owner)
+			}
+		}
+		return predictedOwners, predictedOwners
+	case CodeOwnersBoost:
+		return append(predictedOwners, others...), nil
+	case CodeOwnersFallback:
+		return assignees, owners
+	}
+	return assignees, nil
+}
@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/models/bhattacharya"
+	"core/models/ownership"
+	"core/pipeline/gateway/conflation"
+	"core/testutil/fakegithub"
+)
+
+func TestApplyCodeOwners(t *testing.T) {
+	predicted := []string{"ahsoka", "rex", "cody"}
+	owners := []string{"cody", "wolffe"}
+	cases := []struct {
+		mode      CodeOwnersMode
+		owners    []string
+		assignees []string
+		fallback  []string
+	}{
+		{CodeOwnersOff, owners, predicted, nil},
+		{CodeOwnersFilter, nil, predicted, nil},
+		{CodeOwnersFilter, owners, []string{"cody", "wolffe"}, []string{"cody", "wolffe"}},
+		{CodeOwnersBoost, owners, []string{"cody", "ahsoka", "rex"}, nil},
+		{CodeOwnersFallback, owners, predicted, owners},
+	}
+	for _, c := range cases {
+		assignees, fallback := applyCodeOwners(c.mode, predicted, c.owners)
+		if !reflect.DeepEqual(assignees, c.assignees) || !reflect.DeepEqual(fallback, c.fallback) {
+			t.Error(
+				"\nCODEOWNERS MODE NOT APPLIED",
+				"\nMODE:     ", c.mode,
+				"\nEXPECTED: ", c.assignees, c.fallback,
+				"\nACTUAL:   ", assignees, fallback,
+			)
+		}
+	}
+}
+
+func openIssue(gh *fakegithub.Server, body string) conflation.ExpandedIssue {
+	created := time.Now()
+	issue := gh.AddIssue("republic", "kamino", github.Issue{
+		Body:       github.String(body),
+		CreatedAt:  &created,
+		User:       &github.User{Login: github.String("shaak-ti")},
+		Repository: &github.Repository{FullName: github.String("republic/kamino")},
+	})
+	triaged, labeled := false, false
+	return conflation.ExpandedIssue{Issue: conflation.CRIssue{Issue: *issue, Triaged: &triaged, Labeled: &labeled}}
+}
+
+func TestTriageFilterNoEligibleOwner(t *testing.T) {
+	gh := fakegithub.New()
+	defer gh.Close()
+	gh.AddRepo("republic", "kamino")
+
+	codeOwners, err := ownership.ParseCodeOwners(strings.NewReader("/clones/ @cody\n/armory/ @wolffe\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blender := &Blender{
+		Models:    []*ArchModel{{Model: newAssignmentModel(nil, bhattacharya.Params{})}},
+		Conflator: &conflation.Conflator{Context: &conflation.Context{}},
+	}
+	blender.Conflator.Context.Issues = []conflation.ExpandedIssue{
+		trainingIssue(1, "cody", "clone trooper armor"),
+		trainingIssue(2, "rex", "blaster calibration"),
+	}
+	blender.TrainModels()
+	unowned := openIssue(gh, "armory/blasters.go is out of stock")
+	owned := openIssue(gh, "clones/armor.go does not fit")
+	blender.Conflator.Context.Issues = []conflation.ExpandedIssue{unowned, owned}
+
+	repo := &ArchRepo{
+		Client:                   gh.Client(),
+		Hive:                     &ArchHive{Blender: blender},
+		Settings:                 HeuprConfigSettings{EnableTriager: true, CodeOwners: CodeOwnersFilter},
+		CodeOwners:               codeOwners,
+		CodeOwnersFetched:        time.Now(),
+		EligibleAssignees:        map[string]int{"cody": 10, "rex": 10},
+		TriagedLabelEnabledCheck: true,
+	}
+	repo.TriageOpenIssues()
+
+	if assignees := gh.Assignees("republic", "kamino", *owned.Issue.Number); !reflect.DeepEqual(assignees, []string{"cody"}) {
+		t.Error(
+			"\nISSUE AFTER INELIGIBLE OWNER NOT TRIAGED",
+			"\nEXPECTED: ", []string{"cody"},
+			"\nACTUAL:   ", assignees,
+		)
+	}
+	if *unowned.Issue.Triaged || !*owned.Issue.Triaged {
+		t.Error(
+			"\nTRIAGED FLAG NOT SET ONLY ON ASSIGNMENT",
+			"\nEXPECTED: ", false, true,
+			"\nACTUAL:   ", *unowned.Issue.Triaged, *owned.Issue.Triaged,
+		)
+	}
+	if len(repo.Hive.Blender.GetOpenIssues()) != 1 {
+		t.Error("\nUNASSIGNED ISSUE NOT LEFT FOR RETRY")
+	}
+}
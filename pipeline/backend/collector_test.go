@@ -4,7 +4,7 @@ import (
 	"testing"
 )
 
-func Testcollector(t *testing.T) {
+func TestCollector(t *testing.T) {
 	repodataMap := map[int64]*RepoData{
 		1: &RepoData{
 			RepoID: 66,
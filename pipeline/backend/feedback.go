@@ -0,0 +1,228 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/pipeline/gateway/conflation"
+	"core/utils"
+)
+
+// Feedback is a human adding or removing an assignee or label on an issue,
+// as recorded by the ingestor.
+type Feedback struct {
+	Action   string
+	Actor    string
+	Assignee string
+	Label    string
+	Issue    *github.Issue
+	At       time.Time
+}
+
+// botAction is an assignee or label applied by Heupr; removed is set once a
+// human took it off the issue.
+type botAction struct {
+	value      string
+	overridden bool
+	removed    bool
+}
+
+// Overrides tracks the assignments and type labels Heupr made and counts how
+// often maintainers overrode them. It is kept in memory only and starts over
+// when the backend restarts; the counts are persisted with InsertModelQuality.
+type Overrides struct {
+	assignees           map[int]*botAction
+	labels              map[int]*botAction
+	Assignments         int
+	AssignmentOverrides int
+	Labels              int
+	LabelOverrides      int
+}
+
+func NewOverrides() *Overrides {
+	return &Overrides{
+		assignees: make(map[int]*botAction),
+		labels:    make(map[int]*botAction),
+	}
+}
+
+// Assigned records an assignment made by Heupr.
+func (o *Overrides) Assigned(number int, assignee string) {
+	o.assignees[number] = &botAction{value: assignee}
+	o.Assignments++
+}
+
+// Labeled records a type label applied by Heupr.
+func (o *Overrides) Labeled(number int, label string) {
+	o.labels[number] = &botAction{value: label}
+	o.Labels++
+}
+
+// Observe classifies human feedback against Heupr's actions on the issue.
+// It reports whether the feedback is a corrected assignment or a corrected
+// type label worth learning from; typeLabels are the configured issue type
+// labels. Assigning someone else only corrects Heupr's assignee once that
+// assignee was removed, so adding a co-assignee is not a correction. Each
+// bot action is counted as overridden at most once.
+func (o *Overrides) Observe(f Feedback, typeLabels map[string]bool) (assignment, label bool) {
+	if f.Issue == nil || f.Issue.Number == nil {
+		return false, false
+	}
+	number := *f.Issue.Number
+	switch f.Action {
+	case "assigned", "unassigned":
+		action, ok := o.assignees[number]
+		if !ok || f.Assignee == action.value {
+			if ok && f.Action == "unassigned" {
+				action.removed = true
+				if !action.overridden {
+					action.overridden = true
+					o.AssignmentOverrides++
+				}
+			}
+			return false, false
+		}
+		if f.Action == "assigned" && (action.removed || !hasAssignee(f.Issue, action.value)) {
+			if !action.overridden {
+				action.overridden = true
+				o.AssignmentOverrides++
+			}
+			return true, false
+		}
+	case "labeled", "unlabeled":
+		action, ok := o.labels[number]
+		if !ok {
+			return false, false
+		}
+		if f.Label == action.value {
+			if f.Action == "unlabeled" && !action.overridden {
+				action.overridden = true
+				o.LabelOverrides++
+			}
+			return false, false
+		}
+		if f.Action == "labeled" && typeLabels[f.Label] {
+			if !action.overridden {
+				action.overridden = true
+				o.LabelOverrides++
+			}
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// hasAssignee reports whether the login is among the issue assignees.
+func hasAssignee(issue *github.Issue, login string) bool {
+	for _, assignee := range issue.Assignees {
+		if assignee.GetLogin() == login {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignmentOverrideRate is the fraction of Heupr's assignments that were
+// overridden.
+func (o *Overrides) AssignmentOverrideRate() float64 {
+	if o.Assignments == 0 {
+		return 0
+	}
+	return float64(o.AssignmentOverrides) / float64(o.Assignments)
+}
+
+// LabelOverrideRate is the fraction of Heupr's type labels that were
+// overridden.
+func (o *Overrides) LabelOverrideRate() float64 {
+	if o.Labels == 0 {
+		return 0
+	}
+	return float64(o.LabelOverrides) / float64(o.Labels)
+}
+
+// correction returns the issue as a training example for the corrected
+// assignee or label. The feedback time stands in for the resolution time.
+func correction(f Feedback, assignee string, labels []string) conflation.ExpandedIssue {
+	issue := *f.Issue
+	at := f.At
+	issue.ClosedAt = &at
+	if assignee != "" {
+		issue.Assignees = []*github.User{{Login: &assignee}}
+		issue.Assignee = issue.Assignees[0]
+	}
+	if labels != nil {
+		issue.Labels = nil
+		for i := range labels {
+			issue.Labels = append(issue.Labels, github.Label{Name: &labels[i]})
+		}
+	}
+	labeled, triaged := true, true
+	return conflation.ExpandedIssue{Issue: conflation.CRIssue{Issue: issue, Labeled: &labeled, Triaged: &triaged}}
+}
+
+func (a *ArchRepo) typeLabels() map[string]bool {
+	labels := make(map[string]bool)
+	for _, label := range []*string{a.Settings.Bug, a.Settings.Feature, a.Settings.Improvement} {
+		if label != nil && *label != "" {
+			labels[*label] = true
+		}
+	}
+	return labels
+}
+
+// ApplyFeedback feeds corrected assignments to the assignment models and
+// corrected type labels to the labelmaker. It reports whether any of the
+// feedback overrode Heupr.
+func (a *ArchRepo) ApplyFeedback(feedback []Feedback) bool {
+	if a.Overrides == nil {
+		a.Overrides = NewOverrides()
+	}
+	typeLabels := a.typeLabels()
+	assignments := []conflation.ExpandedIssue{}
+	labels := []conflation.ExpandedIssue{}
+	before := a.Overrides.AssignmentOverrides + a.Overrides.LabelOverrides
+	for _, f := range feedback {
+		if a.Workload != nil {
+			a.Workload.Observe(f.Issue)
+		}
+		assignment, label := a.Overrides.Observe(f, typeLabels)
+		if assignment {
+			assignments = append(assignments, correction(f, f.Assignee, nil))
+		}
+		if label {
+			labels = append(labels, correction(f, "", []string{f.Label}))
+		}
+	}
+	if len(assignments) > 0 && a.Hive.Blender.AllModelsBootstrapped() {
+		utils.AppLog.Info("ApplyFeedback() assignment corrections", zap.Int("Total", len(assignments)))
+		a.Hive.Blender.LearnCorrections(assignments)
+	}
+	if len(labels) > 0 && a.Labelmaker != nil {
+		utils.AppLog.Info("ApplyFeedback() label corrections", zap.Int("Total", len(labels)))
+		a.Labelmaker.OnlineLearn(labels)
+	}
+	return a.Overrides.AssignmentOverrides+a.Overrides.LabelOverrides > before
+}
+
+// LearnCorrections trains the bootstrapped models on corrected issues and
+// marks them as corrected so that TrainModels skips them once closed.
+func (b *Blender) LearnCorrections(issues []conflation.ExpandedIssue) {
+	learned := false
+	for i := 0; i < len(b.Models); i++ {
+		if b.Models[i].Model.IsBootstrapped() {
+			b.Models[i].Model.OnlineLearn(issues)
+			learned = true
+		}
+	}
+	if learned {
+		b.bump()
+	}
+	if b.Corrected == nil {
+		b.Corrected = make(map[int]bool)
+	}
+	for _, issue := range issues {
+		b.Corrected[issue.Issue.GetNumber()] = true
+	}
+}
@@ -0,0 +1,132 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"core/models"
+	"core/models/bhattacharya"
+	"core/pipeline/gateway/conflation"
+)
+
+func TestOverridesObserve(t *testing.T) {
+	o := NewOverrides()
+	o.Assigned(1, "hera")
+	o.Assigned(2, "kanan")
+	o.Labeled(1, "bug")
+	o.Labeled(2, "bug")
+	typeLabels := map[string]bool{"bug": true, "enhancement": true}
+
+	cases := []struct {
+		feedback   Feedback
+		assignment bool
+		label      bool
+	}{
+		{Feedback{Action: "unassigned", Assignee: "hera", Issue: workloadIssue(1, false)}, false, false},
+		{Feedback{Action: "assigned", Assignee: "sabine", Issue: workloadIssue(1, false, "sabine")}, true, false},
+		{Feedback{Action: "assigned", Assignee: "kanan", Issue: workloadIssue(2, false, "kanan")}, false, false},
+		{Feedback{Action: "assigned", Assignee: "zeb", Issue: workloadIssue(3, false, "zeb")}, false, false},
+		{Feedback{Action: "labeled", Label: "help wanted", Issue: workloadIssue(1, false)}, false, false},
+		{Feedback{Action: "labeled", Label: "enhancement", Issue: workloadIssue(1, false)}, false, true},
+		{Feedback{Action: "unlabeled", Label: "bug", Issue: workloadIssue(1, false)}, false, false},
+	}
+	for i, c := range cases {
+		assignment, label := o.Observe(c.feedback, typeLabels)
+		if assignment != c.assignment || label != c.label {
+			t.Error(
+				"\nFEEDBACK NOT CLASSIFIED",
+				"\nCASE:     ", i,
+				"\nEXPECTED: ", c.assignment, c.label,
+				"\nACTUAL:   ", assignment, label,
+			)
+		}
+	}
+	if o.AssignmentOverrideRate() != 0.5 || o.LabelOverrideRate() != 0.5 {
+		t.Error(
+			"\nOVERRIDE RATES NOT COUNTED ONCE PER ACTION",
+			"\nEXPECTED: ", 0.5, 0.5,
+			"\nACTUAL:   ", o.AssignmentOverrideRate(), o.LabelOverrideRate(),
+		)
+	}
+}
+
+func TestOverridesCoAssign(t *testing.T) {
+	o := NewOverrides()
+	o.Assigned(1, "hera")
+	o.Assigned(2, "kanan")
+
+	cases := []struct {
+		feedback   Feedback
+		assignment bool
+	}{
+		{Feedback{Action: "assigned", Assignee: "chopper", Issue: workloadIssue(1, false, "hera", "chopper")}, false},
+		{Feedback{Action: "unassigned", Assignee: "hera", Issue: workloadIssue(1, false, "chopper")}, false},
+		{Feedback{Action: "assigned", Assignee: "ezra", Issue: workloadIssue(1, false, "chopper", "ezra")}, true},
+		{Feedback{Action: "assigned", Assignee: "ezra", Issue: workloadIssue(2, false, "ezra")}, true},
+	}
+	for i, c := range cases {
+		if assignment, _ := o.Observe(c.feedback, nil); assignment != c.assignment {
+			t.Error(
+				"\nCO-ASSIGNEE NOT TOLD APART FROM CORRECTION",
+				"\nCASE:     ", i,
+				"\nEXPECTED: ", c.assignment,
+				"\nACTUAL:   ", assignment,
+			)
+		}
+	}
+	if o.AssignmentOverrides != 2 {
+		t.Error(
+			"\nCO-ASSIGNEE COUNTED AS OVERRIDE",
+			"\nEXPECTED: ", 2,
+			"\nACTUAL:   ", o.AssignmentOverrides,
+		)
+	}
+}
+
+func TestCorrection(t *testing.T) {
+	at := time.Now()
+	f := Feedback{Action: "assigned", Assignee: "sabine", Issue: workloadIssue(1, false, "hera", "sabine"), At: at}
+	example := correction(f, f.Assignee, nil)
+	if len(example.Issue.Assignees) != 1 || *example.Issue.Assignees[0].Login != "sabine" || !example.Issue.ClosedAt.Equal(at) {
+		t.Error(
+			"\nCORRECTIVE EXAMPLE NOT BUILT",
+			"\nACTUAL:   ", example.Issue.Assignees, example.Issue.ClosedAt,
+		)
+	}
+	if len(f.Issue.Assignees) != 2 || f.Issue.ClosedAt != nil {
+		t.Error("\nFEEDBACK ISSUE MODIFIED")
+	}
+}
+
+func TestLearnCorrectionsOnce(t *testing.T) {
+	blender := &Blender{
+		Models:    []*ArchModel{{Model: newAssignmentModel(nil, bhattacharya.Params{})}},
+		Conflator: &conflation.Conflator{Context: &conflation.Context{}},
+	}
+	blender.Conflator.Context.Issues = []conflation.ExpandedIssue{
+		trainingIssue(1, "hera", "ghost engine stalls"),
+		trainingIssue(2, "kanan", "lightsaber training"),
+	}
+	blender.TrainModels()
+
+	corrected := trainingIssue(3, "sabine", "paint the ghost")
+	blender.LearnCorrections([]conflation.ExpandedIssue{corrected})
+	blender.Conflator.Context.Issues = append(blender.Conflator.Context.Issues, corrected, trainingIssue(4, "zeb", "cargo hold"))
+	closed := blender.GetClosedIssues()
+	if len(closed) != 1 || *closed[0].Issue.Number != 4 {
+		t.Error(
+			"\nCORRECTED ISSUE LEARNED AGAIN",
+			"\nEXPECTED: ", 4,
+			"\nACTUAL:   ", closed,
+		)
+	}
+
+	blender.Reset(func() *models.Model { return newAssignmentModel(nil, bhattacharya.Params{}) })
+	if closed := blender.GetClosedIssues(); len(closed) != 4 {
+		t.Error(
+			"\nCORRECTED ISSUE NOT RELEARNED AFTER RESET",
+			"\nEXPECTED: ", 4,
+			"\nACTUAL:   ", len(closed),
+		)
+	}
+}
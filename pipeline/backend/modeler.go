@@ -8,16 +8,35 @@ import (
 	"core/models"
 	"core/models/bhattacharya"
 	"core/models/labelmaker"
+	"core/models/ownership"
 	"core/pipeline/gateway/conflation"
 	"core/utils"
 
 	"go.uber.org/zap"
 )
 
+// OwnershipWeight is the log score bonus given to the strongest code owner
+// of the files an issue mentions.
+const OwnershipWeight = 2.0
+
 var NewLanguageClient = func(ctx context.Context) (*language.Client, error) {
 	return language.NewClient(ctx)
 }
 
+// newAssignmentModel returns an untrained assignee model.
+func newAssignmentModel(index *ownership.Index, params bhattacharya.Params) *models.Model {
+	model := params.Model()
+	model.Ownership = index
+	model.OwnershipWeight = OwnershipWeight
+	return &models.Model{Algorithm: model}
+}
+
+// newModel returns an untrained assignee model with the repo's parameters;
+// the caller must hold the repo lock.
+func (a *ArchRepo) newModel() *models.Model {
+	return newAssignmentModel(a.Ownership, a.ModelParams)
+}
+
 func (s *Server) NewModel(repoID int64) {
 	s.Repos.Lock()
 	defer s.Repos.Unlock()
@@ -34,10 +53,10 @@ func (s *Server) NewModel(repoID int64) {
 		Context:              confCxt,
 	}
 	s.Repos.Actives[repoID].Hive.Blender.Conflator = &conflator
-	model := models.Model{Algorithm: &bhattacharya.NBModel{}}
+	s.Repos.Actives[repoID].Ownership = ownership.NewIndex()
 	s.Repos.Actives[repoID].Hive.Blender.Models = append(
 		s.Repos.Actives[repoID].Hive.Blender.Models,
-		&ArchModel{Model: &model},
+		&ArchModel{Model: s.Repos.Actives[repoID].newModel()},
 	)
 
 	ctx := context.Background()
@@ -49,10 +68,11 @@ func (s *Server) NewModel(repoID int64) {
 	s.Repos.Actives[repoID].Labelmaker = &labelmaker.LBModel{
 		Classifier: &labelmaker.LBClassifier{
 			Client: client,
-			Gateway: labelmaker.CachedNlpGateway{
+			Gateway: &labelmaker.CachedNlpGateway{
 				NlpGateway: &labelmaker.NlpGateway{
 					Client: client,
 				},
+				DiskCache: &labelmaker.HashedDiskCache{},
 			},
 			Ctx: ctx,
 		},
@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"time"
+)
+
+// Assignment policy names as stored in HeuprConfigSettings.AssignmentPolicy.
+const (
+	GreedyPolicy      = "greedy"
+	RoundRobinPolicy  = "round-robin"
+	LeastLoadedPolicy = "least-loaded"
+	SuggestOnlyPolicy = "suggest-only"
+)
+
+// AssignmentRequest is everything a policy needs to decide who should be
+// assigned an issue; it deliberately holds no GitHub client. Owners are the
+// code owners to fall back on, ahead of the predictions (see
+// applyCodeOwners). Allocations are the open issues assigned to each
+// developer as of the last database read plus the assignments made since
+// (ArchRepo.AssigneeAllocations).
+type AssignmentRequest struct {
+	Predictions       []string
+	Owners            []string
+	Allocations       map[string]int
+	EligibleAssignees map[string]int
+	Settings          HeuprConfigSettings
+	Workload          *Workload
+	Now               time.Time
+}
+
+// Decision lists the candidates to try in order and the assignee to fall back
+// on when none of them can be assigned. SuggestOnly decisions must not be
+// applied; Candidates then holds the suggestions.
+type Decision struct {
+	Candidates  []string
+	Fallback    string
+	SuggestOnly bool
+}
+
+type AssignmentPolicy interface {
+	Name() string
+	Decide(request AssignmentRequest) Decision
+}
+
+// NewAssignmentPolicy returns the named policy, defaulting to greedy.
+func NewAssignmentPolicy(name string) AssignmentPolicy {
+	switch name {
+	case LeastLoadedPolicy:
+		return &LeastLoaded{}
+	case RoundRobinPolicy:
+		return &RoundRobin{}
+	case SuggestOnlyPolicy:
+		return &SuggestOnly{}
+	}
+	return &Greedy{}
+}
+
+// eligible returns the predictions that are neither ignored nor missing from
+// the eligible assignees, in prediction order.
+func (r AssignmentRequest) eligible() []string {
+	return r.eligibleOf(r.Predictions)
+}
+
+func (r AssignmentRequest) eligibleOf(assignees []string) []string {
+	result := []string{}
+	for _, assignee := range assignees {
+		if _, ok := r.Settings.IgnoreUsers[assignee]; ok {
+			continue
+		}
+		if _, ok := r.EligibleAssignees[assignee]; ok {
+			result = append(result, assignee)
+		}
+	}
+	return result
+}
+
+// available filters eligible predictions down to those under their caps.
+func (r AssignmentRequest) available() []string {
+	return r.availableOf(r.eligible())
+}
+
+func (r AssignmentRequest) availableOf(assignees []string) []string {
+	result := []string{}
+	for _, assignee := range assignees {
+		if r.Workload.Available(assignee, r.EligibleAssignees[assignee], r.Settings, r.Now) {
+			result = append(result, assignee)
+		}
+	}
+	return result
+}
+
+// allocatedOf filters assignees down to those allocated fewer issues than
+// their cap.
+func (r AssignmentRequest) allocatedOf(assignees []string) []string {
+	result := []string{}
+	for _, assignee := range assignees {
+		if r.Allocations[assignee] < r.EligibleAssignees[assignee] {
+			result = append(result, assignee)
+		}
+	}
+	return result
+}
+
+func (r AssignmentRequest) topK() int {
+	if r.Settings.TopK > 0 {
+		return r.Settings.TopK
+	}
+	return DefaultTopK
+}
+
+// fallback returns the first eligible owner under its cap, as checked by
+// under, or failing that the highest ranked eligible prediction regardless
+// of its cap.
+func (r AssignmentRequest) fallback(under func([]string) []string) string {
+	if owners := under(r.eligibleOf(r.Owners)); len(owners) > 0 {
+		return owners[0]
+	}
+	if eligible := r.eligible(); len(eligible) > 0 {
+		return eligible[0]
+	}
+	return ""
+}
+
+// Greedy assigns the highest ranked prediction that is under its cap. Unlike
+// the other policies it checks the caps against the allocations, ignoring
+// the capacity weights and window limits of the Workload and top-K, as
+// triage always has; the ingestor rejects those settings for greedy.
+type Greedy struct{}
+
+func (g *Greedy) Name() string {
+	return GreedyPolicy
+}
+
+func (g *Greedy) Decide(request AssignmentRequest) Decision {
+	candidates := request.allocatedOf(request.eligible())
+	return Decision{Candidates: candidates, Fallback: request.fallback(request.allocatedOf)}
+}
+
+// LeastLoaded assigns the least loaded of the top-K predictions that are
+// under their caps.
+type LeastLoaded struct{}
+
+func (l *LeastLoaded) Name() string {
+	return LeastLoadedPolicy
+}
+
+func (l *LeastLoaded) Decide(request AssignmentRequest) Decision {
+	settings := request.Settings
+	settings.TopK = request.topK()
+	candidates := request.Workload.Rank(request.available(), request.EligibleAssignees, settings, request.Now)
+	return Decision{Candidates: candidates, Fallback: request.fallback(request.availableOf)}
+}
+
+// RoundRobin rotates through the top-K predictions that are under their caps
+// so that consecutive issues go to different developers.
+type RoundRobin struct {
+	next int
+}
+
+func (r *RoundRobin) Name() string {
+	return RoundRobinPolicy
+}
+
+func (r *RoundRobin) Decide(request AssignmentRequest) Decision {
+	available := request.available()
+	if len(available) > request.topK() {
+		available = available[:request.topK()]
+	}
+	candidates := available
+	if len(available) > 0 {
+		start := r.next % len(available)
+		candidates = append(append([]string{}, available[start:]...), available[:start]...)
+		r.next++
+	}
+	return Decision{Candidates: candidates, Fallback: request.fallback(request.availableOf)}
+}
+
+// SuggestOnly never assigns; it returns the top-K eligible predictions as
+// suggestions.
+type SuggestOnly struct{}
+
+func (s *SuggestOnly) Name() string {
+	return SuggestOnlyPolicy
+}
+
+func (s *SuggestOnly) Decide(request AssignmentRequest) Decision {
+	suggestions := request.eligible()
+	if len(suggestions) > request.topK() {
+		suggestions = suggestions[:request.topK()]
+	}
+	return Decision{Candidates: suggestions, SuggestOnly: true}
+}
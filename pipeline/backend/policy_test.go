@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func policyRequest(w *Workload) AssignmentRequest {
+	return AssignmentRequest{
+		Predictions:       []string{"vader", "tarkin", "krennic", "veers", "piett"},
+		Allocations:       map[string]int{"vader": 2},
+		EligibleAssignees: map[string]int{"vader": 2, "tarkin": 10, "krennic": 10, "veers": 10},
+		Settings: HeuprConfigSettings{
+			TopK:        2,
+			IgnoreUsers: map[string]bool{"tarkin": true},
+		},
+		Workload: w,
+		Now:      time.Now(),
+	}
+}
+
+func TestNewAssignmentPolicy(t *testing.T) {
+	for _, name := range []string{GreedyPolicy, RoundRobinPolicy, LeastLoadedPolicy, SuggestOnlyPolicy} {
+		if policy := NewAssignmentPolicy(name); policy.Name() != name {
+			t.Error(
+				"\nPOLICY NOT SELECTED",
+				"\nEXPECTED: ", name,
+				"\nACTUAL:   ", policy.Name(),
+			)
+		}
+	}
+	if NewAssignmentPolicy("").Name() != GreedyPolicy || NewAssignmentPolicy("hyperspace").Name() != GreedyPolicy {
+		t.Error("\nDEFAULT POLICY NOT GREEDY")
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	w := NewWorkload()
+	w.Assigned("vader", 1, time.Now())
+	w.Assigned("vader", 2, time.Now())
+	w.Assigned("krennic", 3, time.Now())
+	w.Assigned("krennic", 4, time.Now())
+
+	cases := []struct {
+		policy   AssignmentPolicy
+		expected Decision
+	}{
+		{&Greedy{}, Decision{Candidates: []string{"krennic", "veers"}, Fallback: "vader"}},
+		{&LeastLoaded{}, Decision{Candidates: []string{"veers", "krennic"}, Fallback: "vader"}},
+		{&SuggestOnly{}, Decision{Candidates: []string{"vader", "krennic"}, SuggestOnly: true}},
+	}
+	for _, c := range cases {
+		decision := c.policy.Decide(policyRequest(w))
+		if !reflect.DeepEqual(decision, c.expected) {
+			t.Error(
+				"\nUNEXPECTED POLICY DECISION",
+				"\nPOLICY:   ", c.policy.Name(),
+				"\nEXPECTED: ", c.expected,
+				"\nACTUAL:   ", decision,
+			)
+		}
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	policy := &RoundRobin{}
+	first := policy.Decide(policyRequest(NewWorkload()))
+	second := policy.Decide(policyRequest(NewWorkload()))
+	third := policy.Decide(policyRequest(NewWorkload()))
+	if first.Candidates[0] != "vader" || second.Candidates[0] != "krennic" || third.Candidates[0] != "vader" {
+		t.Error(
+			"\nROUND ROBIN NOT ROTATING",
+			"\nFIRST:    ", first.Candidates,
+			"\nSECOND:   ", second.Candidates,
+			"\nTHIRD:    ", third.Candidates,
+		)
+	}
+	if len(first.Candidates) != 2 {
+		t.Error(
+			"\nROUND ROBIN NOT LIMITED TO TOP-K",
+			"\nACTUAL:   ", first.Candidates,
+		)
+	}
+}
+
+func TestOwnerFallback(t *testing.T) {
+	w := NewWorkload()
+	w.Assigned("vader", 1, time.Now())
+	w.Assigned("vader", 2, time.Now())
+	w.Assigned("krennic", 3, time.Now())
+	cases := []struct {
+		owners   []string
+		fallback string
+	}{
+		{[]string{"veers"}, "veers"},
+		{[]string{"galen", "veers"}, "veers"},
+		{[]string{"tarkin", "vader", "krennic"}, "krennic"},
+		{[]string{"galen", "vader"}, "vader"},
+	}
+	for _, c := range cases {
+		request := policyRequest(w)
+		request.Predictions = []string{"vader"}
+		request.Owners = c.owners
+		if decision := (&Greedy{}).Decide(request); decision.Fallback != c.fallback {
+			t.Error(
+				"\nUNEXPECTED OWNER FALLBACK",
+				"\nOWNERS:   ", c.owners,
+				"\nEXPECTED: ", c.fallback,
+				"\nACTUAL:   ", decision.Fallback,
+			)
+		}
+	}
+}
+
+func TestGreedyAllocations(t *testing.T) {
+	request := policyRequest(NewWorkload())
+	request.Allocations = map[string]int{"krennic": 10}
+	request.Settings.WindowLimit = 1
+	request.Settings.Window = time.Hour
+	request.Workload.Assigned("veers", 1, request.Now)
+	decision := (&Greedy{}).Decide(request)
+	if !reflect.DeepEqual(decision.Candidates, []string{"vader", "veers"}) {
+		t.Error(
+			"\nGREEDY CAPS NOT CHECKED AGAINST ALLOCATIONS",
+			"\nEXPECTED: ", []string{"vader", "veers"},
+			"\nACTUAL:   ", decision.Candidates,
+		)
+	}
+}
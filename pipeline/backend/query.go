@@ -10,15 +10,23 @@ import (
 	"time"
 
 	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/models/bhattacharya"
+	"core/pipeline/audit"
 )
 
 var maxID = 0
+var maxFeedbackID = 0
 
 type RepoData struct {
-	RepoID              int64
+	RepoID int64
+	// Cursor is the id of the newest event read for the repo.
+	Cursor              int64
 	Open                []*github.Issue
 	Closed              []*github.Issue
 	Pulls               []*github.PullRequest
+	Feedback            []Feedback
 	AssigneeAllocations map[string]int
 	EligibleAssignees   map[string]int
 	Settings            HeuprConfigSettings
@@ -37,6 +45,19 @@ type HeuprConfigSettings struct {
 	IgnoreLabels  map[string]bool
 	Email         string
 	Twitter       string
+	CodeOwners    CodeOwnersMode
+	// TopK, Window, WindowLimit and CapacityWeights configure the Workload
+	// load balancing (e.g. WindowLimit issues per seven day Window).
+	TopK            int
+	Window          time.Duration
+	WindowLimit     int
+	CapacityWeights map[string]float64
+	// AssignmentPolicy selects the policy used by TriageOpenIssues (see
+	// NewAssignmentPolicy); empty selects greedy.
+	AssignmentPolicy string
+	// Shadow records every action in the shadow_actions table instead of
+	// applying it on GitHub (see ShadowAction).
+	Shadow bool
 }
 
 func (m *MemSQL) Read() (map[int64]*RepoData, error) {
@@ -79,6 +100,9 @@ func (m *MemSQL) Read() (map[int64]*RepoData, error) {
 			repodata[*repo_id].Closed = []*github.Issue{}
 			repodata[*repo_id].Pulls = []*github.PullRequest{}
 		}
+		if int64(*id) > repodata[*repo_id].Cursor {
+			repodata[*repo_id].Cursor = int64(*id)
+		}
 
 		if *is_pull {
 			var pr github.PullRequest
@@ -102,6 +126,22 @@ func (m *MemSQL) Read() (map[int64]*RepoData, error) {
 			}
 		}
 	}
+	feedback, err := m.ReadFeedback()
+	if err != nil {
+		utils.AppLog.Error("database read failure - ReadFeedback()")
+		return nil, err
+	}
+	for repoID, f := range feedback {
+		if _, ok := repodata[repoID]; !ok {
+			repodata[repoID] = new(RepoData)
+			repodata[repoID].RepoID = repoID
+			repodata[repoID].Open = []*github.Issue{}
+			repodata[repoID].Closed = []*github.Issue{}
+			repodata[repoID].Pulls = []*github.PullRequest{}
+		}
+		repodata[repoID].Feedback = f
+	}
+
 	keys := reflect.ValueOf(repodata).MapKeys()
 	interfaceKeys := make([]interface{}, len(keys))
 	intKeys := make([]int64, len(keys))
@@ -124,6 +164,11 @@ func (m *MemSQL) Read() (map[int64]*RepoData, error) {
 		utils.AppLog.Error("database read failure - ReadHeuprConfigSettings()")
 		return nil, err
 	}
+	caps, err := m.ReadAssigneeCaps(interfaceKeys)
+	if err != nil {
+		utils.AppLog.Error("database read failure - ReadAssigneeCaps()")
+		return nil, err
+	}
 	for i := 0; i < len(intKeys); i++ {
 		repoID := intKeys[i]
 		if _, ok := allocations[repoID]; !ok {
@@ -136,12 +181,233 @@ func (m *MemSQL) Read() (map[int64]*RepoData, error) {
 			settings[repoID] = HeuprConfigSettings{StartTime: time.Now(), IgnoreLabels: make(map[string]bool), IgnoreUsers: make(map[string]bool)}
 		}
 		repodata[repoID].AssigneeAllocations = allocations[repoID]
+		for assignee, cap := range caps[repoID] {
+			eligibleAssignees[repoID][assignee] = cap
+		}
 		repodata[repoID].EligibleAssignees = eligibleAssignees[repoID]
 		repodata[repoID].Settings = settings[repoID]
 	}
 	return repodata, nil
 }
 
+// ReadFeedback returns the human assignee and label changes recorded since
+// the last read, in order, by repo.
+func (m *MemSQL) ReadFeedback() (map[int64][]Feedback, error) {
+	results, err := m.db.Query("SELECT id, repo_id, action, actor, assignee, label, payload, created_at FROM issue_feedback WHERE id > ? ORDER BY id", maxFeedbackID)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	feedback := make(map[int64][]Feedback)
+	for results.Next() {
+		var id int
+		var repoID int64
+		var action, actor, assignee, label sql.NullString
+		var payload []byte
+		var at time.Time
+		if err := results.Scan(&id, &repoID, &action, &actor, &assignee, &label, &payload, &at); err != nil {
+			return nil, err
+		}
+		if id > maxFeedbackID {
+			maxFeedbackID = id
+		}
+		issue := new(github.Issue)
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.UseNumber()
+		if err := decoder.Decode(issue); err != nil {
+			return nil, err
+		}
+		feedback[repoID] = append(feedback[repoID], Feedback{
+			Action:   action.String,
+			Actor:    actor.String,
+			Assignee: assignee.String,
+			Label:    label.String,
+			Issue:    issue,
+			At:       at,
+		})
+	}
+	return feedback, results.Err()
+}
+
+// ReadHumanChanges returns the human assignee and label changes on a repo
+// since the given time, in order. Only the issue numbers are set.
+func (m *MemSQL) ReadHumanChanges(repoID int64, since time.Time) ([]Feedback, error) {
+	results, err := m.db.Query("SELECT number, action, actor, assignee, label, created_at FROM issue_feedback WHERE repo_id = ? AND created_at >= ? ORDER BY id", repoID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	changes := []Feedback{}
+	for results.Next() {
+		var number int
+		var action, actor, assignee, label sql.NullString
+		var at time.Time
+		if err := results.Scan(&number, &action, &actor, &assignee, &label, &at); err != nil {
+			return nil, err
+		}
+		changes = append(changes, Feedback{
+			Action:   action.String,
+			Actor:    actor.String,
+			Assignee: assignee.String,
+			Label:    label.String,
+			Issue:    &github.Issue{Number: &number},
+			At:       at,
+		})
+	}
+	return changes, results.Err()
+}
+
+// InsertModelQuality records the override counts of a repo.
+func (m *MemSQL) InsertModelQuality(repoID int64, overrides *Overrides) error {
+	_, err := m.db.Exec("INSERT INTO model_quality(repo_id, assignments, assignment_overrides, labels, label_overrides) VALUES(?,?,?,?,?)", repoID, overrides.Assignments, overrides.AssignmentOverrides, overrides.Labels, overrides.LabelOverrides)
+	return err
+}
+
+// ReadAssigneeCaps returns the assignee caps set through the admin API by
+// repo. They take precedence over the default eligible assignee caps.
+func (m *MemSQL) ReadAssigneeCaps(repos []interface{}) (map[int64]map[string]int, error) {
+	if len(repos) == 0 {
+		return nil, nil
+	}
+
+	results, err := m.db.Query("SELECT repo_id, assignee, cap FROM integrations_assignee_caps WHERE repo_id IN (?"+strings.Repeat(",?", len(repos)-1)+")", repos...)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	caps := make(map[int64]map[string]int)
+	for results.Next() {
+		var repoID int64
+		var assignee string
+		var cap int
+		if err := results.Scan(&repoID, &assignee, &cap); err != nil {
+			return nil, err
+		}
+		if _, ok := caps[repoID]; !ok {
+			caps[repoID] = make(map[string]int)
+		}
+		caps[repoID][assignee] = cap
+	}
+	return caps, results.Err()
+}
+
+// UpsertAssigneeCap sets the cap of an assignee on a repo.
+func (m *MemSQL) UpsertAssigneeCap(repoID int64, assignee string, cap int) error {
+	_, err := m.db.Exec("INSERT INTO integrations_assignee_caps(repo_id, assignee, cap) VALUES(?,?,?) ON DUPLICATE KEY UPDATE cap = VALUES(cap)", repoID, assignee, cap)
+	return err
+}
+
+// ReadPausedRepos returns the repos whose triage is paused.
+func (m *MemSQL) ReadPausedRepos() (map[int64]bool, error) {
+	results, err := m.db.Query("SELECT repo_id FROM integrations_paused WHERE paused = true")
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	paused := make(map[int64]bool)
+	for results.Next() {
+		var repoID int64
+		if err := results.Scan(&repoID); err != nil {
+			return nil, err
+		}
+		paused[repoID] = true
+	}
+	return paused, results.Err()
+}
+
+// SetPaused pauses or resumes triage on a repo.
+func (m *MemSQL) SetPaused(repoID int64, paused bool) error {
+	_, err := m.db.Exec("INSERT INTO integrations_paused(repo_id, paused) VALUES(?,?) ON DUPLICATE KEY UPDATE paused = VALUES(paused)", repoID, paused)
+	return err
+}
+
+// ReadModelVersions returns the latest model version audited for each repo,
+// so that versions keep increasing across restarts.
+func (m *MemSQL) ReadModelVersions() (map[int64]int64, error) {
+	results, err := m.db.Query("SELECT repo_id, MAX(CAST(model_version AS UNSIGNED)) FROM actions WHERE model_version RLIKE '^[0-9]+$' GROUP BY repo_id")
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	versions := make(map[int64]int64)
+	for results.Next() {
+		var repoID, version int64
+		if err := results.Scan(&repoID, &version); err != nil {
+			return nil, err
+		}
+		versions[repoID] = version
+	}
+	return versions, results.Err()
+}
+
+// ReadModelParams returns the model hyperparameters set through the admin
+// API by repo.
+func (m *MemSQL) ReadModelParams() (map[int64]bhattacharya.Params, error) {
+	results, err := m.db.Query("SELECT repo_id, params FROM integrations_model_params")
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	params := make(map[int64]bhattacharya.Params)
+	for results.Next() {
+		var repoID int64
+		var encoded []byte
+		if err := results.Scan(&repoID, &encoded); err != nil {
+			return nil, err
+		}
+		p, err := bhattacharya.ReadParams(bytes.NewReader(encoded))
+		if err != nil {
+			utils.AppLog.Error("ReadModelParams() decode", zap.Int64("RepoID", repoID), zap.Error(err))
+			continue
+		}
+		params[repoID] = p
+	}
+	return params, results.Err()
+}
+
+// UpsertModelParams sets the model hyperparameters of a repo.
+func (m *MemSQL) UpsertModelParams(repoID int64, params bhattacharya.Params) error {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.Exec("INSERT INTO integrations_model_params(repo_id, params) VALUES(?,?) ON DUPLICATE KEY UPDATE params = VALUES(params)", repoID, encoded)
+	return err
+}
+
+// InsertShadowActions records the actions a repo in shadow mode would have
+// taken.
+func (m *MemSQL) InsertShadowActions(repoID int64, actions []ShadowAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	values := []interface{}{}
+	for _, action := range actions {
+		values = append(values, repoID, action.Number, action.Action, action.Value, action.Confidence, action.At)
+	}
+	_, err := m.db.Exec("INSERT INTO shadow_actions(repo_id, number, action, value, confidence, created_at) VALUES(?,?,?,?,?,?)"+strings.Repeat(",(?,?,?,?,?,?)", len(actions)-1), values...)
+	return err
+}
+
+// InsertActions audits the GitHub mutations made on a repo.
+func (m *MemSQL) InsertActions(repoID int64, actions []audit.Action) error {
+	for i := range actions {
+		actions[i].RepoID = repoID
+	}
+	return audit.Insert(m.db, actions...)
+}
+
+// ReadActions returns the audited GitHub mutations matching the filter.
+func (m *MemSQL) ReadActions(filter audit.Filter) ([]audit.Action, error) {
+	return audit.Read(m.db, filter)
+}
+
 func (m *MemSQL) ReadHeuprConfigSettingsByRepoID(repoID int64) (HeuprConfigSettings, error) {
 	settingsMap, err := m.ReadHeuprConfigSettings([]interface{}{repoID})
 	if err != nil {
@@ -166,7 +432,7 @@ func (m *MemSQL) ReadHeuprConfigSettings(repos []interface{}) (map[int64]HeuprCo
 	settings := make(map[int64]HeuprConfigSettings)
 
 	integrationSettingsQuery := `
-	SELECT g.repo_id, g.start_time, g.email, g.twitter, g.enable_triager, g.enable_labeler
+	SELECT g.repo_id, g.start_time, g.email, g.twitter, g.enable_triager, g.enable_labeler, g.codeowners_mode, g.top_k, g.window_days, g.window_limit, g.assignment_policy, g.shadow_mode
 	FROM integrations_settings g
 	JOIN (
 		SELECT MAX(id) id
@@ -184,13 +450,34 @@ func (m *MemSQL) ReadHeuprConfigSettings(repos []interface{}) (map[int64]HeuprCo
 
 	for results.Next() {
 		config := HeuprConfigSettings{
-			IgnoreLabels: make(map[string]bool),
-			IgnoreUsers:  make(map[string]bool),
+			IgnoreLabels:    make(map[string]bool),
+			IgnoreUsers:     make(map[string]bool),
+			CapacityWeights: make(map[string]float64),
 		}
 		repo_id := new(int64)
-		if err := results.Scan(repo_id, &config.StartTime, &config.Email, &config.Twitter, &config.EnableTriager, &config.EnableLabeler); err != nil {
+		codeOwners := sql.NullString{}
+		topK := sql.NullInt64{}
+		windowDays := sql.NullInt64{}
+		windowLimit := sql.NullInt64{}
+		policy := sql.NullString{}
+		if err := results.Scan(repo_id, &config.StartTime, &config.Email, &config.Twitter, &config.EnableTriager, &config.EnableLabeler, &codeOwners, &topK, &windowDays, &windowLimit, &policy, &config.Shadow); err != nil {
 			return nil, err
 		}
+		if codeOwners.Valid {
+			config.CodeOwners = CodeOwnersMode(codeOwners.String)
+		}
+		if topK.Valid {
+			config.TopK = int(topK.Int64)
+		}
+		if windowDays.Valid {
+			config.Window = time.Duration(windowDays.Int64) * 24 * time.Hour
+		}
+		if windowLimit.Valid {
+			config.WindowLimit = int(windowLimit.Int64)
+		}
+		if policy.Valid {
+			config.AssignmentPolicy = policy.String
+		}
 		settings[*repo_id] = config
 	}
 	time.Sleep(1 * time.Second)
@@ -251,6 +538,35 @@ func (m *MemSQL) ReadHeuprConfigSettings(repos []interface{}) (map[int64]HeuprCo
 		settings[*repo_id].IgnoreLabels[*label] = true
 	}
 
+	integrationSettingsCapacityQuery := `
+	SELECT g.repo_id, lk.user, lk.weight
+	FROM integrations_settings g
+	JOIN (
+		SELECT MAX(id) id
+		from integrations_settings
+		WHERE repo_id IN (?` + strings.Repeat(",?", len(repos)-1) + `)
+	) T
+	on T.id = g.id
+	JOIN integrations_settings_capacity_lk lk
+	on lk.integrations_settings_fk = g.id
+	`
+
+	results, err = m.db.Query(integrationSettingsCapacityQuery, repos...)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	for results.Next() {
+		repo_id := new(int64)
+		user := new(string)
+		weight := new(float64)
+		if err := results.Scan(repo_id, user, weight); err != nil {
+			return nil, err
+		}
+		settings[*repo_id].CapacityWeights[*user] = *weight
+	}
+
 	//We need to pull in the latest integrations_settings_labels_bif_lk key because of a 1:M relationship (due to insert ordering)
 	integrationSettingsLabelsQuery := `
     SELECT settings.repo_id, bif.bug, bif.improvement, bif.feature
@@ -0,0 +1,190 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/pipeline/audit"
+	"core/utils"
+)
+
+// RollbackRequest selects the actions Heupr took on its own to revert: those
+// in the time range, those made by the model version, or both.
+type RollbackRequest struct {
+	Since   time.Time `json:"since"`
+	Until   time.Time `json:"until"`
+	Version string    `json:"version"`
+	DryRun  bool      `json:"dry_run"`
+}
+
+// RollbackStep is an assignee or label to remove, and the action that added
+// it.
+type RollbackStep struct {
+	Number int          `json:"number"`
+	Kind   string       `json:"kind"`
+	Target string       `json:"target"`
+	Action audit.Action `json:"action"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+// RollbackResult lists the reverted, skipped and failed steps.
+type RollbackResult struct {
+	DryRun   bool           `json:"dry_run"`
+	Reverted []RollbackStep `json:"reverted"`
+	Skipped  []RollbackStep `json:"skipped"`
+	Failed   []RollbackStep `json:"failed"`
+}
+
+// Reasons a rollback step is skipped.
+const (
+	RollbackHumanChanged   = "changed by a human since"
+	RollbackAlreadyRemoved = "no longer on the issue"
+)
+
+var errRollbackScope = errors.New("rollback needs a time range or a model version")
+
+// planRollback expands the audited actions into one step per assignee or
+// label and skips those a human added or removed afterwards. Actions
+// triggered by a human command or acceptance and failed actions are not
+// rolled back.
+func planRollback(actions []audit.Action, changes []Feedback) (steps []RollbackStep, skipped []RollbackStep) {
+	for _, action := range actions {
+		if action.Actor != "" || action.Error != "" {
+			continue
+		}
+		var kind string
+		switch action.Action {
+		case audit.AddAssignees:
+			kind = audit.RemoveAssignees
+		case audit.AddLabels:
+			kind = audit.RemoveLabel
+		default:
+			continue
+		}
+		for _, target := range action.Targets {
+			step := RollbackStep{Number: action.Number, Kind: kind, Target: target, Action: action}
+			if humanChanged(step, changes) {
+				step.Reason = RollbackHumanChanged
+				skipped = append(skipped, step)
+				continue
+			}
+			steps = append(steps, step)
+		}
+	}
+	return steps, skipped
+}
+
+// humanChanged reports whether a human changed the assignees of the issue,
+// or the same label, after the step's action.
+func humanChanged(step RollbackStep, changes []Feedback) bool {
+	for _, f := range changes {
+		if f.Issue == nil || f.Issue.GetNumber() != step.Number || !f.At.After(step.Action.At) {
+			continue
+		}
+		switch f.Action {
+		case "assigned", "unassigned":
+			if step.Kind == audit.RemoveAssignees {
+				return true
+			}
+		case "labeled", "unlabeled":
+			if step.Kind == audit.RemoveLabel && f.Label == step.Target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Rollback reverts the assignees and labels Heupr added to a repo, skipping
+// anything a human has since changed, and audits each removal.
+func (bs *Server) Rollback(repoID int64, client *github.Client, request RollbackRequest) (RollbackResult, error) {
+	result := RollbackResult{DryRun: request.DryRun, Reverted: []RollbackStep{}, Skipped: []RollbackStep{}, Failed: []RollbackStep{}}
+	if request.Since.IsZero() && request.Until.IsZero() && request.Version == "" {
+		return result, errRollbackScope
+	}
+	actions, err := bs.Database.ReadActions(audit.Filter{RepoID: repoID, Since: request.Since, Until: request.Until, ModelVersion: request.Version})
+	if err != nil {
+		return result, err
+	}
+	if len(actions) == 0 {
+		return result, nil
+	}
+	changes, err := bs.Database.ReadHumanChanges(repoID, actions[0].At)
+	if err != nil {
+		return result, err
+	}
+	steps, skipped := planRollback(actions, changes)
+	result.Skipped = append(result.Skipped, skipped...)
+	if len(steps) == 0 {
+		return result, nil
+	}
+
+	repo, _, err := client.Repositories.GetByID(context.Background(), repoID)
+	if err != nil {
+		return result, err
+	}
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+	issues := make(map[int]*github.Issue)
+	reverts := []audit.Action{}
+	for _, step := range steps {
+		issue, ok := issues[step.Number]
+		if !ok {
+			issue, _, err = client.Issues.Get(context.Background(), owner, name, step.Number)
+			if err != nil {
+				step.Reason = err.Error()
+				result.Failed = append(result.Failed, step)
+				continue
+			}
+			issues[step.Number] = issue
+		}
+		if !onIssue(issue, step) {
+			step.Reason = RollbackAlreadyRemoved
+			result.Skipped = append(result.Skipped, step)
+			continue
+		}
+		if request.DryRun {
+			result.Reverted = append(result.Reverted, step)
+			continue
+		}
+		if step.Kind == audit.RemoveAssignees {
+			_, _, err = client.Issues.RemoveAssignees(context.Background(), owner, name, step.Number, []string{step.Target})
+		} else {
+			_, err = client.Issues.RemoveLabelForIssue(context.Background(), owner, name, step.Number, step.Target)
+		}
+		revert := audit.New(repoID, step.Number, step.Kind, []string{step.Target}, err)
+		revert.ModelVersion = step.Action.ModelVersion
+		reverts = append(reverts, revert)
+		if err != nil {
+			step.Reason = err.Error()
+			result.Failed = append(result.Failed, step)
+			continue
+		}
+		result.Reverted = append(result.Reverted, step)
+	}
+	if err := bs.Database.InsertActions(repoID, reverts); err != nil {
+		utils.AppLog.Error("Rollback() InsertActions", zap.Int64("RepoID", repoID), zap.Error(err))
+	}
+	return result, nil
+}
+
+// onIssue reports whether the step's assignee or label is still on the issue.
+func onIssue(issue *github.Issue, step RollbackStep) bool {
+	if step.Kind == audit.RemoveAssignees {
+		for _, assignee := range issue.Assignees {
+			if assignee.GetLogin() == step.Target {
+				return true
+			}
+		}
+		return false
+	}
+	for _, label := range issue.Labels {
+		if label.GetName() == step.Target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/audit"
+)
+
+func TestPlanRollback(t *testing.T) {
+	at := time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC)
+	actions := []audit.Action{
+		{Number: 1, Action: audit.AddAssignees, Targets: []string{"hera"}, At: at},
+		{Number: 1, Action: audit.AddLabels, Targets: []string{"triaged"}, At: at},
+		{Number: 2, Action: audit.AddLabels, Targets: []string{"bug", "help wanted"}, At: at},
+		{Number: 3, Action: audit.AddAssignees, Targets: []string{"kanan"}, At: at},
+		{Number: 4, Action: audit.AddAssignees, Targets: []string{"zeb"}, Actor: "sabine", At: at},
+		{Number: 5, Action: audit.AddAssignees, Targets: []string{"ezra"}, Error: "rate limited", At: at},
+		{Number: 6, Action: audit.CreateComment, Body: "Suggested assignees", At: at},
+	}
+	issue := func(number int) *github.Issue { return &github.Issue{Number: &number} }
+	changes := []Feedback{
+		{Action: "unassigned", Assignee: "hera", Issue: issue(1), At: at.Add(time.Hour)},
+		{Action: "unlabeled", Label: "bug", Issue: issue(2), At: at.Add(time.Hour)},
+		{Action: "assigned", Assignee: "chopper", Issue: issue(3), At: at.Add(-time.Hour)},
+	}
+
+	steps, skipped := planRollback(actions, changes)
+	summarize := func(steps []RollbackStep) []string {
+		summary := []string{}
+		for _, step := range steps {
+			summary = append(summary, step.Kind+" "+step.Target)
+		}
+		return summary
+	}
+	expected := []string{"remove_label triaged", "remove_label help wanted", "remove_assignees kanan"}
+	if actual := summarize(steps); !reflect.DeepEqual(actual, expected) {
+		t.Error("\nROLLBACK STEPS INCORRECT", "\nEXPECTED: ", expected, "\nACTUAL:   ", actual)
+	}
+	expected = []string{"remove_assignees hera", "remove_label bug"}
+	if actual := summarize(skipped); !reflect.DeepEqual(actual, expected) {
+		t.Error("\nROLLBACK SKIPS INCORRECT", "\nEXPECTED: ", expected, "\nACTUAL:   ", actual)
+	}
+	for _, step := range skipped {
+		if step.Reason != RollbackHumanChanged {
+			t.Error("\nSKIP REASON MISSING", "\nACTUAL:   ", step)
+		}
+	}
+}
+
+func TestOnIssue(t *testing.T) {
+	hera, bug := "hera", "bug"
+	issue := &github.Issue{Assignees: []*github.User{{Login: &hera}}, Labels: []github.Label{{Name: &bug}}}
+	cases := []struct {
+		step     RollbackStep
+		expected bool
+	}{
+		{RollbackStep{Kind: audit.RemoveAssignees, Target: "hera"}, true},
+		{RollbackStep{Kind: audit.RemoveAssignees, Target: "kanan"}, false},
+		{RollbackStep{Kind: audit.RemoveLabel, Target: "bug"}, true},
+		{RollbackStep{Kind: audit.RemoveLabel, Target: "triaged"}, false},
+	}
+	for _, c := range cases {
+		if actual := onIssue(issue, c.step); actual != c.expected {
+			t.Error("\nPRESENCE CHECK INCORRECT", "\nSTEP:     ", c.step.Kind, c.step.Target, "\nEXPECTED: ", c.expected, "\nACTUAL:   ", actual)
+		}
+	}
+}
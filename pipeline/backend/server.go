@@ -9,6 +9,7 @@ import (
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
 
+	"core/pipeline/audit"
 	"core/utils"
 )
 
@@ -73,6 +74,7 @@ func (bs *Server) activateHandler(w http.ResponseWriter, r *http.Request) {
 func (bs *Server) Start() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/activate-ingestor-backend", bs.activateHandler)
+	mux.Handle("/admin/", bs.AdminHandler())
 	bs.Server = http.Server{
 		Addr:    utils.Config.BackendServerAddress,
 		Handler: mux,
@@ -102,6 +104,47 @@ func (bs *Server) Start() {
 		}
 	}
 
+	paused, err := bs.Database.ReadPausedRepos()
+	if err != nil {
+		utils.AppLog.Error("retrieve paused repos on backend restart", zap.Error(err))
+	}
+	for repoID := range paused {
+		if repo, ok := bs.Repos.Actives[repoID]; ok {
+			repo.Paused = true
+		}
+	}
+
+	versions, err := bs.Database.ReadModelVersions()
+	if err != nil {
+		utils.AppLog.Error("retrieve model versions on backend restart", zap.Error(err))
+	}
+	for repoID, version := range versions {
+		if repo, ok := bs.Repos.Actives[repoID]; ok {
+			repo.Hive.Blender.ModelVersion = version
+		}
+	}
+
+	params, err := bs.Database.ReadModelParams()
+	if err != nil {
+		utils.AppLog.Error("retrieve model params on backend restart", zap.Error(err))
+	}
+	for repoID, p := range params {
+		if repo, ok := bs.Repos.Actives[repoID]; ok {
+			repo.ModelParams = p
+			repo.Hive.Blender.Reset(repo.newModel)
+		}
+	}
+
+	suggested, err := bs.Database.ReadActions(audit.Filter{Action: audit.CreateComment})
+	if err != nil {
+		utils.AppLog.Error("retrieve suggestions on backend restart", zap.Error(err))
+	}
+	for _, action := range suggested {
+		if repo, ok := bs.Repos.Actives[action.RepoID]; ok {
+			repo.RestoreSuggestions([]audit.Action{action})
+		}
+	}
+
 	// Keeping this channel to implement graceful shutdowns if needed.
 	wiggin := make(chan bool)
 	bs.Timer(wiggin)
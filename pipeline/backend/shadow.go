@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"time"
+)
+
+// Shadow actions are the actions the backend would have taken on GitHub for
+// a repo in shadow mode.
+const (
+	ShadowAssignee     = "assignee"
+	ShadowLabel        = "label"
+	ShadowDefaultLabel = "default_label"
+	ShadowTriagedLabel = "triaged_label"
+	ShadowSuggestion   = "suggestion"
+)
+
+// ShadowAction is an action recorded instead of applied. Confidence is the
+// model confidence of an assignee, if known.
+type ShadowAction struct {
+	Number     int
+	Action     string
+	Value      string
+	Confidence float64
+	At         time.Time
+}
+
+// shadow records an action for the worker to store. Shadowed assignments and
+// type labels are tracked as bot actions so that the human outcome is
+// measured by the override rates exactly as in live mode.
+func (a *ArchRepo) shadow(number int, action, value string, confidence float64) {
+	a.Shadowed = append(a.Shadowed, ShadowAction{
+		Number:     number,
+		Action:     action,
+		Value:      value,
+		Confidence: confidence,
+		At:         a.now(),
+	})
+	switch action {
+	case ShadowAssignee:
+		a.Overrides.Assigned(number, value)
+	case ShadowLabel:
+		a.Overrides.Labeled(number, value)
+	}
+}
+
+// shadowAssign records the assignment the policy decision would have made.
+// Without GitHub to reject an assignee, the first candidate always wins.
+func (a *ArchRepo) shadowAssign(number int, decision Decision, confidences map[string]float64, triaged bool) bool {
+	assignee := decision.Fallback
+	if len(decision.Candidates) > 0 {
+		assignee = decision.Candidates[0]
+	}
+	if assignee == "" {
+		return false
+	}
+	a.shadow(number, ShadowAssignee, assignee, confidences[assignee])
+	if triaged {
+		a.shadow(number, ShadowTriagedLabel, "triaged", 0)
+	}
+	a.allocate(assignee, number)
+	return true
+}
+
+// FlushShadowed returns and clears the recorded shadow actions.
+func (a *ArchRepo) FlushShadowed() []ShadowAction {
+	shadowed := a.Shadowed
+	a.Shadowed = nil
+	return shadowed
+}
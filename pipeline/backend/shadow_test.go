@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestShadowAssign(t *testing.T) {
+	a := &ArchRepo{Workload: NewWorkload(), Overrides: NewOverrides()}
+	confidences := map[string]float64{"hera": 0.75}
+
+	if !a.shadowAssign(1, Decision{Candidates: []string{"hera", "kanan"}}, confidences, true) {
+		t.Error("\nCANDIDATE NOT SHADOW ASSIGNED")
+	}
+	if !a.shadowAssign(2, Decision{Fallback: "sabine"}, confidences, false) {
+		t.Error("\nFALLBACK NOT SHADOW ASSIGNED")
+	}
+	if a.shadowAssign(3, Decision{}, confidences, true) {
+		t.Error("\nEMPTY DECISION SHADOW ASSIGNED")
+	}
+
+	shadowed := a.FlushShadowed()
+	expected := []ShadowAction{
+		{Number: 1, Action: ShadowAssignee, Value: "hera", Confidence: 0.75},
+		{Number: 1, Action: ShadowTriagedLabel, Value: "triaged"},
+		{Number: 2, Action: ShadowAssignee, Value: "sabine"},
+	}
+	if len(shadowed) != len(expected) {
+		t.Fatal("\nSHADOW ACTIONS NOT RECORDED", "\nEXPECTED: ", expected, "\nACTUAL:   ", shadowed)
+	}
+	for i := range expected {
+		shadowed[i].At = expected[i].At
+		if shadowed[i] != expected[i] {
+			t.Error("\nSHADOW ACTION MISMATCH", "\nEXPECTED: ", expected[i], "\nACTUAL:   ", shadowed[i])
+		}
+	}
+	if len(a.FlushShadowed()) != 0 {
+		t.Error("\nSHADOW ACTIONS NOT FLUSHED")
+	}
+	if a.Workload.Open("hera") != 1 || a.Overrides.Assignments != 2 {
+		t.Error("\nSHADOW ASSIGNMENTS NOT TRACKED", "\nACTUAL:   ", a.Workload.Open("hera"), a.Overrides.Assignments)
+	}
+
+	assignment, _ := a.Overrides.Observe(Feedback{Action: "assigned", Assignee: "kanan", Issue: workloadIssue(1, false, "kanan")}, nil)
+	if !assignment || a.Overrides.AssignmentOverrideRate() != 0.5 {
+		t.Error("\nSHADOW ASSIGNMENT NOT COMPARED", "\nACTUAL:   ", a.Overrides.AssignmentOverrideRate())
+	}
+}
@@ -0,0 +1,193 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/pipeline/audit"
+	"core/pipeline/gateway/conflation"
+	"core/pipeline/suggestion"
+	"core/utils"
+)
+
+// ExplainTerms is the number of terms shown per suggested assignee.
+const ExplainTerms = 3
+
+// suggestionComment locates the suggestion comment posted on an issue.
+type suggestionComment struct {
+	Owner string
+	Repo  string
+	ID    int64
+}
+
+// suggest posts, or updates, the single suggestion comment on an issue
+// instead of assigning it.
+func (a *ArchRepo) suggest(owner, repo string, issue conflation.ExpandedIssue, candidates []string, confidences map[string]float64) {
+	because := make(map[string][]string)
+	for _, explanation := range a.Hive.Blender.Explain(issue, ExplainTerms) {
+		because[explanation.Assignee] = explanation.Because()
+	}
+	s := suggestion.Suggestion{}
+	for _, login := range candidates {
+		s.Candidates = append(s.Candidates, suggestion.Candidate{Login: login, Confidence: confidences[login], Because: because[login]})
+	}
+	if a.Labelmaker != nil {
+		label, err := a.Labelmaker.BugOrFeature(issue)
+		if err != nil {
+			utils.AppLog.Error("suggest() issue type label", zap.Error(err))
+		} else if label != nil {
+			s.Label = *label
+		}
+	}
+
+	if a.Suggestions == nil {
+		a.Suggestions = make(map[int]suggestionComment)
+	}
+	number := *issue.Issue.Number
+	body := s.Render()
+	existing, ok := a.Suggestions[number]
+	commentID := existing.ID
+	if !ok {
+		comment, _, found, err := suggestion.Find(a.Client, owner, repo, number)
+		if err != nil {
+			utils.AppLog.Error("suggest() find comment", zap.Error(err))
+			return
+		}
+		commentID, ok = comment.GetID(), found
+	}
+	if ok {
+		_, _, err := a.Client.Issues.EditComment(context.Background(), owner, repo, commentID, &github.IssueComment{Body: &body})
+		a.recordComment(number, audit.EditComment, body, confidences, err)
+		if err != nil {
+			utils.AppLog.Error("suggest() EditComment", zap.Error(err))
+			return
+		}
+	} else {
+		comment, _, err := a.Client.Issues.CreateComment(context.Background(), owner, repo, number, &github.IssueComment{Body: &body})
+		a.recordComment(number, audit.CreateComment, body, confidences, err)
+		if err != nil {
+			utils.AppLog.Error("suggest() CreateComment", zap.Error(err))
+			return
+		}
+		commentID = comment.GetID()
+	}
+	a.Suggestions[number] = suggestionComment{Owner: owner, Repo: repo, ID: commentID}
+}
+
+// RestoreSuggestions queues the suggestions posted before a restart, as
+// audited by the create_comment actions, so that their reactions are polled
+// again. Their comments are looked up by AcceptSuggestions.
+func (a *ArchRepo) RestoreSuggestions(actions []audit.Action) {
+	for _, action := range actions {
+		if action.Action != audit.CreateComment || action.Error != "" {
+			continue
+		}
+		if s, ok := suggestion.Parse(action.Body); !ok || s.AcceptedBy != "" {
+			continue
+		}
+		if a.Suggestions == nil {
+			a.Suggestions = make(map[int]suggestionComment)
+		}
+		if _, ok := a.Suggestions[action.Number]; !ok {
+			a.Suggestions[action.Number] = suggestionComment{}
+		}
+	}
+}
+
+// findSuggestion looks up a restored suggestion comment. Suggestions that no
+// longer exist or were already accepted are dropped; on errors they are kept
+// for the next call.
+func (a *ArchRepo) findSuggestion(number int) (suggestionComment, bool) {
+	repository, _, err := a.Client.Repositories.GetByID(context.Background(), a.Hive.Blender.RepoID)
+	if err != nil {
+		utils.AppLog.Error("AcceptSuggestions() GetByID", zap.Int64("RepoID", a.Hive.Blender.RepoID), zap.Error(err))
+		return suggestionComment{}, false
+	}
+	owner, repo := repository.GetOwner().GetLogin(), repository.GetName()
+	comment, s, found, err := suggestion.Find(a.Client, owner, repo, number)
+	if err != nil {
+		utils.AppLog.Error("AcceptSuggestions() find comment", zap.Int("Number", number), zap.Error(err))
+		return suggestionComment{}, false
+	}
+	if !found || s.AcceptedBy != "" {
+		delete(a.Suggestions, number)
+		return suggestionComment{}, false
+	}
+	pending := suggestionComment{Owner: owner, Repo: repo, ID: comment.GetID()}
+	a.Suggestions[number] = pending
+	return pending, true
+}
+
+// AcceptSuggestions applies suggestions that a maintainer has accepted with a
+// reaction. Reactions do not trigger webhooks so they are polled; slash
+// command acceptance is handled by the ingestor. A suggestion stays pending
+// until it is applied, so a failed acceptance is retried on the next call.
+func (a *ArchRepo) AcceptSuggestions() {
+	if a.Client == nil {
+		return
+	}
+	for number, pending := range a.Suggestions {
+		if pending.ID == 0 {
+			restored, ok := a.findSuggestion(number)
+			if !ok {
+				continue
+			}
+			pending = restored
+		}
+		owner, repo, commentID := pending.Owner, pending.Repo, pending.ID
+		reactions, _, err := a.Client.Reactions.ListIssueCommentReactions(context.Background(), owner, repo, commentID, &github.ListOptions{PerPage: 100})
+		if err != nil {
+			utils.AppLog.Error("AcceptSuggestions() reactions", zap.Int("Number", number), zap.Error(err))
+			continue
+		}
+		acceptedBy := ""
+		for _, reaction := range reactions {
+			if reaction.GetContent() != suggestion.AcceptReaction {
+				continue
+			}
+			login := reaction.GetUser().GetLogin()
+			if ok, err := suggestion.CanAccept(a.Client, owner, repo, login); err == nil && ok {
+				acceptedBy = login
+				break
+			}
+		}
+		if acceptedBy == "" {
+			continue
+		}
+
+		comment, _, err := a.Client.Issues.GetComment(context.Background(), owner, repo, commentID)
+		if err != nil {
+			utils.AppLog.Error("AcceptSuggestions() GetComment", zap.Int("Number", number), zap.Error(err))
+			continue
+		}
+		s, ok := suggestion.Parse(comment.GetBody())
+		if !ok || s.AcceptedBy != "" {
+			delete(a.Suggestions, number)
+			continue
+		}
+		assignee, ok := s.Choose("")
+		if !ok {
+			delete(a.Suggestions, number)
+			continue
+		}
+		err = suggestion.Accept(a.Client, owner, repo, number, comment, s, acceptedBy, assignee)
+		a.recordAccepted(number, acceptedBy, assignee, s, err)
+		if err != nil {
+			utils.AppLog.Error("AcceptSuggestions() Accept", zap.Int("Number", number), zap.Error(err))
+			continue
+		}
+		delete(a.Suggestions, number)
+		if a.Workload != nil {
+			a.Workload.Assigned(assignee, number, a.now())
+		}
+		if a.Overrides != nil {
+			a.Overrides.Assigned(number, assignee)
+			if s.Label != "" {
+				a.Overrides.Labeled(number, s.Label)
+			}
+		}
+		utils.AppLog.Info("AcceptSuggestions() accepted", zap.Int("Number", number), zap.String("Assignee", assignee), zap.String("AcceptedBy", acceptedBy))
+	}
+}
@@ -0,0 +1,61 @@
+package backend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/audit"
+	"core/pipeline/suggestion"
+	"core/testutil/fakegithub"
+)
+
+func TestAcceptRestoredSuggestion(t *testing.T) {
+	gh := fakegithub.New()
+	defer gh.Close()
+	gh.AddRepoWithID(1138, "republic", "jedi-temple")
+	issue := gh.AddIssue("republic", "jedi-temple", github.Issue{Title: github.String("Archives missing Kamino")})
+	number := issue.GetNumber()
+	client := gh.Client()
+
+	body := suggestion.Suggestion{Candidates: []suggestion.Candidate{{Login: "obi-wan", Confidence: 0.8}}, Label: "bug"}.Render()
+	comment, _, err := client.Issues.CreateComment(context.Background(), "republic", "jedi-temple", number, &github.IssueComment{Body: &body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gh.AddReaction("republic", "jedi-temple", comment.GetID(), "yoda", suggestion.AcceptReaction)
+	gh.SetPermission("republic", "jedi-temple", "yoda", "admin")
+
+	repo := &ArchRepo{Client: client, Hive: &ArchHive{Blender: &Blender{RepoID: 1138}}}
+	repo.RestoreSuggestions([]audit.Action{{Number: number, Action: audit.CreateComment, Body: body}})
+
+	// Looking up the restored comment, its reactions, the permission and the
+	// comment itself takes five requests; assigning then hits the limit. The
+	// reset is already past so that the client does not hold back the next
+	// requests.
+	gh.SetRateLimit(fakegithub.DefaultRateLimit, 5, time.Now().Add(-time.Minute))
+	repo.AcceptSuggestions()
+	if pending, ok := repo.Suggestions[number]; !ok || pending.ID != comment.GetID() {
+		t.Error(
+			"\nFAILED ACCEPTANCE NOT KEPT PENDING",
+			"\nEXPECTED: ", comment.GetID(),
+			"\nACTUAL:   ", pending.ID,
+		)
+	}
+
+	gh.SetRateLimit(fakegithub.DefaultRateLimit, fakegithub.DefaultRateLimit, time.Now().Add(time.Hour))
+	repo.AcceptSuggestions()
+	if assignees := gh.Assignees("republic", "jedi-temple", number); !reflect.DeepEqual(assignees, []string{"obi-wan"}) {
+		t.Error(
+			"\nRESTORED SUGGESTION NOT ACCEPTED",
+			"\nEXPECTED: ", []string{"obi-wan"},
+			"\nACTUAL:   ", assignees,
+		)
+	}
+	if _, ok := repo.Suggestions[number]; ok {
+		t.Error("\nACCEPTED SUGGESTION STILL PENDING")
+	}
+}
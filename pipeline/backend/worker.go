@@ -7,20 +7,22 @@ import (
 )
 
 type Worker struct {
-	ID    int
-	Work  chan *RepoData
-	Queue chan chan *RepoData
-	Repos *ActiveRepos
-	Quit  chan bool
+	ID       int
+	Work     chan *RepoData
+	Queue    chan chan *RepoData
+	Repos    *ActiveRepos
+	Database *MemSQL
+	Quit     chan bool
 }
 
 func (s *Server) NewWorker(workerID int, queue chan chan *RepoData) Worker {
 	return Worker{
-		ID:    workerID,
-		Work:  make(chan *RepoData),
-		Queue: queue,
-		Repos: s.Repos,
-		Quit:  make(chan bool),
+		ID:       workerID,
+		Work:     make(chan *RepoData),
+		Queue:    queue,
+		Repos:    s.Repos,
+		Database: &s.Database,
+		Quit:     make(chan bool),
 	}
 }
 
@@ -30,57 +32,7 @@ func (w *Worker) Start() {
 			w.Queue <- w.Work
 			select {
 			case repodata := <-w.Work:
-				if w.Repos.Actives[repodata.RepoID] == nil {
-					utils.AppLog.Error("repo not initialized before worker start", zap.Int64("RepoID", repodata.RepoID))
-					continue
-				}
-
-				w.Repos.RLock()
-				repo := w.Repos.Actives[repodata.RepoID]
-				w.Repos.RUnlock()
-
-				repo.Lock()
-				if len(repodata.Open) != 0 {
-					repo.Hive.Blender.Conflator.SetIssueRequests(repodata.Open)
-					issues := repo.Hive.Blender.Conflator.Context.Issues
-					utils.AppLog.Info("Events", zap.Int("Open", len(repodata.Open)), zap.Int("Total", len(issues)), zap.Int64("RepoID", repodata.RepoID))
-				}
-				if len(repodata.Closed) != 0 {
-					repo.Hive.Blender.Conflator.SetIssueRequests(repodata.Closed)
-					issues := repo.Hive.Blender.Conflator.Context.Issues
-					utils.AppLog.Info("Events", zap.Int("Closed", len(repodata.Closed)), zap.Int("Total", len(issues)), zap.Int64("RepoID", repodata.RepoID))
-				}
-				if len(repodata.Pulls) != 0 {
-					repo.Hive.Blender.Conflator.SetPullRequests(repodata.Pulls)
-					issues := repo.Hive.Blender.Conflator.Context.Issues
-					utils.AppLog.Info("Events", zap.Int("Pulls", len(repodata.Pulls)), zap.Int("Total", len(issues)), zap.Int64("RepoID", repodata.RepoID))
-				}
-				utils.AppLog.Info("Conflator.Conflate() ", zap.Int64("RepoID", repodata.RepoID))
-				repo.Hive.Blender.Conflator.Conflate()
-
-				utils.AppLog.Info("Blender.TrainModels() ", zap.Int64("RepoID", repodata.RepoID))
-				repo.Hive.Blender.TrainModels()
-
-				repo.AssigneeAllocations = repodata.AssigneeAllocations
-				repo.EligibleAssignees = repodata.EligibleAssignees
-				repo.Settings = repodata.Settings
-
-				//Keep these fields live/updated
-				if repo.Labelmaker != nil {
-					repo.Labelmaker.BugLabel = repo.Settings.Bug
-					repo.Labelmaker.ImprovementLabel = repo.Settings.Improvement
-					repo.Labelmaker.FeatureLabel = repo.Settings.Feature
-				}
-
-				utils.AppLog.Info("TriageOpenIssues() - Begin ", zap.Int64("RepoID", repodata.RepoID))
-				repo.TriageOpenIssues()
-				utils.AppLog.Info("TriageOpenIssues() - Complete ", zap.Int64("RepoID", repodata.RepoID))
-
-				utils.AppLog.Info("ApplyLabelsOnOpenIssues() - Begin ", zap.Int64("RepoID", repodata.RepoID))
-				repo.ApplyLabelsOnOpenIssues()
-				utils.AppLog.Info("ApplyLabelsOnOpenIssues() - Complete ", zap.Int64("RepoID", repodata.RepoID))
-				repo.Unlock()
-				continue
+				w.Process(repodata)
 			case <-w.Quit:
 				return
 			}
@@ -88,6 +40,95 @@ func (w *Worker) Start() {
 	}()
 }
 
+// Process trains the repo models on the new data and triages its open
+// issues. With a nil Database nothing is persisted (see the replay
+// simulator).
+func (w *Worker) Process(repodata *RepoData) {
+	if w.Repos.Actives[repodata.RepoID] == nil {
+		utils.AppLog.Error("repo not initialized before worker start", zap.Int64("RepoID", repodata.RepoID))
+		return
+	}
+
+	w.Repos.RLock()
+	repo := w.Repos.Actives[repodata.RepoID]
+	w.Repos.RUnlock()
+
+	repo.Lock()
+	defer repo.Unlock()
+	if repo.Workload == nil {
+		repo.Workload = NewWorkload()
+	}
+	repo.Workload.Observe(repodata.Open...)
+	repo.Workload.Observe(repodata.Closed...)
+	if len(repodata.Open) != 0 {
+		repo.Hive.Blender.Conflator.SetIssueRequests(repodata.Open)
+		issues := repo.Hive.Blender.Conflator.Context.Issues
+		utils.AppLog.Info("Events", zap.Int("Open", len(repodata.Open)), zap.Int("Total", len(issues)), zap.Int64("RepoID", repodata.RepoID))
+	}
+	if len(repodata.Closed) != 0 {
+		repo.Hive.Blender.Conflator.SetIssueRequests(repodata.Closed)
+		issues := repo.Hive.Blender.Conflator.Context.Issues
+		utils.AppLog.Info("Events", zap.Int("Closed", len(repodata.Closed)), zap.Int("Total", len(issues)), zap.Int64("RepoID", repodata.RepoID))
+	}
+	if len(repodata.Pulls) != 0 {
+		repo.Hive.Blender.Conflator.SetPullRequests(repodata.Pulls)
+		issues := repo.Hive.Blender.Conflator.Context.Issues
+		utils.AppLog.Info("Events", zap.Int("Pulls", len(repodata.Pulls)), zap.Int("Total", len(issues)), zap.Int64("RepoID", repodata.RepoID))
+	}
+	repo.UpdateOwnership(repodata.Pulls)
+	utils.AppLog.Info("Conflator.Conflate() ", zap.Int64("RepoID", repodata.RepoID))
+	repo.Hive.Blender.Conflator.Conflate()
+
+	repo.Hive.Blender.RepoID = repodata.RepoID
+	if repodata.Cursor > repo.Hive.Blender.Cursor {
+		repo.Hive.Blender.Cursor = repodata.Cursor
+	}
+	utils.AppLog.Info("Blender.TrainModels() ", zap.Int64("RepoID", repodata.RepoID))
+	repo.Hive.Blender.TrainModels()
+
+	repo.AssigneeAllocations = repodata.AssigneeAllocations
+	repo.EligibleAssignees = repodata.EligibleAssignees
+	repo.Settings = repodata.Settings
+
+	if len(repodata.Feedback) != 0 && repo.ApplyFeedback(repodata.Feedback) {
+		overrides := repo.Overrides
+		utils.AppLog.Info("Overrides", zap.Int64("RepoID", repodata.RepoID), zap.Float64("AssignmentOverrideRate", overrides.AssignmentOverrideRate()), zap.Float64("LabelOverrideRate", overrides.LabelOverrideRate()))
+		if w.Database != nil {
+			if err := w.Database.InsertModelQuality(repodata.RepoID, overrides); err != nil {
+				utils.AppLog.Error("InsertModelQuality()", zap.Int64("RepoID", repodata.RepoID), zap.Error(err))
+			}
+		}
+	}
+
+	//Keep these fields live/updated
+	if repo.Labelmaker != nil {
+		repo.Labelmaker.BugLabel = repo.Settings.Bug
+		repo.Labelmaker.ImprovementLabel = repo.Settings.Improvement
+		repo.Labelmaker.FeatureLabel = repo.Settings.Feature
+	}
+
+	repo.AcceptSuggestions()
+
+	utils.AppLog.Info("TriageOpenIssues() - Begin ", zap.Int64("RepoID", repodata.RepoID))
+	repo.TriageOpenIssues()
+	utils.AppLog.Info("TriageOpenIssues() - Complete ", zap.Int64("RepoID", repodata.RepoID))
+
+	utils.AppLog.Info("ApplyLabelsOnOpenIssues() - Begin ", zap.Int64("RepoID", repodata.RepoID))
+	repo.ApplyLabelsOnOpenIssues()
+	utils.AppLog.Info("ApplyLabelsOnOpenIssues() - Complete ", zap.Int64("RepoID", repodata.RepoID))
+
+	if actions := repo.FlushActions(); len(actions) != 0 && w.Database != nil {
+		if err := w.Database.InsertActions(repodata.RepoID, actions); err != nil {
+			utils.AppLog.Error("InsertActions()", zap.Int64("RepoID", repodata.RepoID), zap.Error(err))
+		}
+	}
+	if shadowed := repo.FlushShadowed(); len(shadowed) != 0 && w.Database != nil {
+		if err := w.Database.InsertShadowActions(repodata.RepoID, shadowed); err != nil {
+			utils.AppLog.Error("InsertShadowActions()", zap.Int64("RepoID", repodata.RepoID), zap.Error(err))
+		}
+	}
+}
+
 func (w *Worker) Stop() {
 	go func() {
 		w.Quit <- true
@@ -2,34 +2,27 @@ package backend
 
 import (
 	"context"
-	"net/url"
 	"testing"
 	"time"
 
-	language "cloud.google.com/go/language/apiv1"
 	"github.com/google/go-github/github"
 
 	"core/models"
 	"core/models/bhattacharya"
 	"core/models/labelmaker"
 	"core/pipeline/gateway/conflation"
-
-	"github.com/google/go-github/github"
+	"core/testutil/fakegithub"
 )
 
 func TestWorker(t *testing.T) {
 	repoID := int64(23)
 
 	ctx := context.Background()
-	lngClient, err := language.NewClient(ctx)
-	if err != nil {
-		t.Error("error creating language client", err)
-	}
 
-	client := github.NewClient(nil)
-	url, _ := url.Parse("http://localhost:8000/")
-	client.BaseURL = url
-	client.UploadURL = url
+	gh := fakegithub.New()
+	defer gh.Close()
+	client := gh.Client()
+	gh.AddRepoWithID(repoID, "skywalker", "t-16")
 
 	bs := new(Server)
 	bs.Repos = new(ActiveRepos)
@@ -50,13 +43,6 @@ func TestWorker(t *testing.T) {
 				},
 			},
 		},
-		Labelmaker: &labelmaker.LBModel{
-			Classifier: &labelmaker.LBClassifier{
-				Client:  lngClient,
-				Gateway: labelmaker.Gateway,
-				Ctx:     ctx,
-			},
-		},
 		Settings: HeuprConfigSettings{
 			StartTime: started,
 		},
@@ -89,17 +75,12 @@ func TestWorker(t *testing.T) {
 	)
 	bs.Repos.Actives[repoID].Labelmaker = &labelmaker.LBModel{
 		Classifier: &labelmaker.LBClassifier{
-			Client: lngClient,
-			Gateway: labelmaker.CachedNlpGateway{
-				NlpGateway: &labelmaker.MockNlpGateway{
-					Client: lngClient,
-				},
-			},
-			Ctx: ctx,
+			Gateway: &labelmaker.MockNlpGateway{},
+			Ctx:     ctx,
 		},
-		BugLabel:         s.Repos.Actives[repoID].Settings.Bug,
-		ImprovementLabel: s.Repos.Actives[repoID].Settings.Improvement,
-		FeatureLabel:     s.Repos.Actives[repoID].Settings.Feature,
+		BugLabel:         bs.Repos.Actives[repoID].Settings.Bug,
+		ImprovementLabel: bs.Repos.Actives[repoID].Settings.Improvement,
+		FeatureLabel:     bs.Repos.Actives[repoID].Settings.Feature,
 	}
 
 	work := &RepoData{
@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// DefaultTopK is the number of top predictions considered when balancing
+// load if the repository settings do not specify one.
+const DefaultTopK = 3
+
+// Workload tracks the open issues currently assigned to each developer and
+// when they were assigned, as observed from the event stream and from the
+// assignments made during triage. Unlike AssigneeAllocations, closing or
+// unassigning an issue frees capacity.
+type Workload struct {
+	open    map[string]map[int]time.Time
+	history map[string][]time.Time
+}
+
+func NewWorkload() *Workload {
+	return &Workload{
+		open:    make(map[string]map[int]time.Time),
+		history: make(map[string][]time.Time),
+	}
+}
+
+// Observe updates the workload from the latest state of an issue.
+func (w *Workload) Observe(issues ...*github.Issue) {
+	for _, issue := range issues {
+		if issue == nil || issue.Number == nil {
+			continue
+		}
+		number := *issue.Number
+		current := make(map[string]bool)
+		if issue.ClosedAt == nil {
+			for _, assignee := range issue.Assignees {
+				if assignee != nil && assignee.Login != nil {
+					current[*assignee.Login] = true
+				}
+			}
+			if issue.Assignee != nil && issue.Assignee.Login != nil {
+				current[*issue.Assignee.Login] = true
+			}
+		}
+		for assignee, numbers := range w.open {
+			if _, ok := numbers[number]; ok && !current[assignee] {
+				delete(numbers, number)
+			}
+		}
+		at := time.Now()
+		if issue.UpdatedAt != nil {
+			at = *issue.UpdatedAt
+		}
+		for assignee := range current {
+			w.Assigned(assignee, number, at)
+		}
+	}
+}
+
+// Assigned records an assignment. Repeated assignments of the same issue to
+// the same developer are ignored.
+func (w *Workload) Assigned(assignee string, number int, at time.Time) {
+	if _, ok := w.open[assignee]; !ok {
+		w.open[assignee] = make(map[int]time.Time)
+	}
+	if _, ok := w.open[assignee][number]; ok {
+		return
+	}
+	w.open[assignee][number] = at
+	w.history[assignee] = append(w.history[assignee], at)
+}
+
+// Open returns the number of open issues assigned to the developer.
+func (w *Workload) Open(assignee string) int {
+	return len(w.open[assignee])
+}
+
+// Allocations returns the number of open issues assigned to each developer,
+// as ReadAssigneeAllocations does from the database.
+func (w *Workload) Allocations() map[string]int {
+	allocations := make(map[string]int)
+	for assignee, numbers := range w.open {
+		if len(numbers) > 0 {
+			allocations[assignee] = len(numbers)
+		}
+	}
+	return allocations
+}
+
+// InWindow returns the number of assignments the developer received in the
+// window ending at now. Older history is discarded.
+func (w *Workload) InWindow(assignee string, now time.Time, window time.Duration) int {
+	start := now.Add(-window)
+	kept := []time.Time{}
+	for _, at := range w.history[assignee] {
+		if at.After(start) {
+			kept = append(kept, at)
+		}
+	}
+	w.history[assignee] = kept
+	return len(kept)
+}
+
+// Capacity is the number of open issues a developer may hold: the eligible
+// assignee cap scaled by the developer's weight (1 when unset).
+func (s HeuprConfigSettings) Capacity(assignee string, cap int) float64 {
+	weight := 1.0
+	if w, ok := s.CapacityWeights[assignee]; ok {
+		weight = w
+	}
+	return float64(cap) * weight
+}
+
+// Available reports whether the developer is below both the weighted open
+// issue capacity and the time window limit.
+func (w *Workload) Available(assignee string, cap int, settings HeuprConfigSettings, now time.Time) bool {
+	if float64(w.Open(assignee)) >= settings.Capacity(assignee, cap) {
+		return false
+	}
+	if settings.WindowLimit > 0 && settings.Window > 0 && w.InWindow(assignee, now, settings.Window) >= settings.WindowLimit {
+		return false
+	}
+	return true
+}
+
+// Load is the fraction of the developer's weighted capacity in use.
+func (w *Workload) Load(assignee string, cap int, settings HeuprConfigSettings) float64 {
+	capacity := settings.Capacity(assignee, cap)
+	if capacity <= 0 {
+		return 1
+	}
+	return float64(w.Open(assignee)) / capacity
+}
+
+// Rank reorders the predictions so that the first topK available eligible
+// developers come first, least loaded first (ties keep the prediction order),
+// followed by the remaining predictions unchanged.
+func (w *Workload) Rank(predictions []string, caps map[string]int, settings HeuprConfigSettings, now time.Time) []string {
+	topK := settings.TopK
+	if topK <= 0 {
+		topK = DefaultTopK
+	}
+	best := []string{}
+	rest := []string{}
+	for _, assignee := range predictions {
+		cap, eligible := caps[assignee]
+		if len(best) < topK && eligible && w.Available(assignee, cap, settings, now) {
+			best = append(best, assignee)
+		} else {
+			rest = append(rest, assignee)
+		}
+	}
+	sort.SliceStable(best, func(i, j int) bool {
+		return w.Load(best[i], caps[best[i]], settings) < w.Load(best[j], caps[best[j]], settings)
+	})
+	return append(best, rest...)
+}
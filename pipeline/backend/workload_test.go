@@ -0,0 +1,95 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func workloadIssue(number int, closed bool, assignees ...string) *github.Issue {
+	issue := &github.Issue{Number: &number}
+	for i := range assignees {
+		issue.Assignees = append(issue.Assignees, &github.User{Login: &assignees[i]})
+	}
+	if closed {
+		now := time.Now()
+		issue.ClosedAt = &now
+	}
+	return issue
+}
+
+func TestWorkloadObserve(t *testing.T) {
+	w := NewWorkload()
+	w.Observe(workloadIssue(1, false, "hera"), workloadIssue(2, false, "hera", "kanan"))
+	if w.Open("hera") != 2 || w.Open("kanan") != 1 {
+		t.Error(
+			"\nOPEN ASSIGNMENTS NOT TRACKED",
+			"\nHERA:     ", w.Open("hera"),
+			"\nKANAN:    ", w.Open("kanan"),
+		)
+	}
+
+	w.Observe(workloadIssue(1, true, "hera"), workloadIssue(2, false, "kanan"))
+	if w.Open("hera") != 0 || w.Open("kanan") != 1 {
+		t.Error(
+			"\nCLOSED OR UNASSIGNED ISSUES NOT RELEASED",
+			"\nHERA:     ", w.Open("hera"),
+			"\nKANAN:    ", w.Open("kanan"),
+		)
+	}
+}
+
+func TestWorkloadAvailable(t *testing.T) {
+	now := time.Now()
+	w := NewWorkload()
+	w.Assigned("sabine", 1, now.AddDate(0, 0, -10))
+	w.Assigned("sabine", 2, now.AddDate(0, 0, -1))
+
+	settings := HeuprConfigSettings{CapacityWeights: map[string]float64{"sabine": 0.2}}
+	if w.Available("sabine", 10, settings, now) {
+		t.Error("\nWEIGHTED CAPACITY NOT APPLIED")
+	}
+
+	settings = HeuprConfigSettings{Window: 7 * 24 * time.Hour, WindowLimit: 1}
+	if w.Available("sabine", 10, settings, now) {
+		t.Error("\nTIME WINDOW LIMIT NOT APPLIED")
+	}
+	if !w.Available("sabine", 10, settings, now.AddDate(0, 0, 7)) {
+		t.Error("\nTIME WINDOW NOT EXPIRED")
+	}
+}
+
+func TestWorkloadRank(t *testing.T) {
+	now := time.Now()
+	w := NewWorkload()
+	for i := 0; i < 5; i++ {
+		w.Assigned("ezra", i, now)
+	}
+	w.Assigned("zeb", 10, now)
+	caps := map[string]int{"ezra": 10, "zeb": 10, "chopper": 10}
+	settings := HeuprConfigSettings{TopK: 2}
+
+	ranked := w.Rank([]string{"ezra", "zeb", "chopper", "thrawn"}, caps, settings, now)
+	expected := []string{"zeb", "ezra", "chopper", "thrawn"}
+	if !reflect.DeepEqual(ranked, expected) {
+		t.Error(
+			"\nLEAST LOADED TOP-K CANDIDATE NOT PREFERRED",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", ranked,
+		)
+	}
+}
+
+func TestWorkloadAllocations(t *testing.T) {
+	w := NewWorkload()
+	w.Observe(workloadIssue(1, false, "hera"), workloadIssue(2, false, "hera", "kanan"), workloadIssue(2, true, "kanan"))
+	if allocations := w.Allocations(); !reflect.DeepEqual(allocations, map[string]int{"hera": 1}) {
+		t.Error(
+			"\nUNEXPECTED ALLOCATIONS",
+			"\nEXPECTED: ", map[string]int{"hera": 1},
+			"\nACTUAL:   ", allocations,
+		)
+	}
+}
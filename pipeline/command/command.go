@@ -0,0 +1,156 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Prefix starts every command; it must begin a line of the comment.
+const Prefix = "/heupr"
+
+const (
+	Assign   = "assign"
+	Relabel  = "relabel"
+	Ignore   = "ignore"
+	Explain  = "explain"
+	Settings = "settings"
+	Accept   = "accept"
+)
+
+// IgnoreLabel is applied by "/heupr ignore"; issues carrying it are never
+// triaged or labeled.
+const IgnoreLabel = "heupr:ignore"
+
+// Repository permission levels as reported by GitHub, lowest first.
+const (
+	NonePermission  = "none"
+	ReadPermission  = "read"
+	WritePermission = "write"
+	AdminPermission = "admin"
+)
+
+var ranks = map[string]int{
+	NonePermission:  0,
+	ReadPermission:  1,
+	WritePermission: 2,
+	AdminPermission: 3,
+}
+
+// Required is the minimum permission needed to run each command.
+var Required = map[string]string{
+	Assign:   WritePermission,
+	Relabel:  WritePermission,
+	Ignore:   WritePermission,
+	Accept:   WritePermission,
+	Explain:  ReadPermission,
+	Settings: AdminPermission,
+}
+
+// Usage documents the arguments of each command.
+var Usage = map[string]string{
+	Assign:   "/heupr assign @login [@login...]",
+	Relabel:  "/heupr relabel <label> [<label>...]",
+	Ignore:   "/heupr ignore",
+	Explain:  "/heupr explain",
+	Settings: "/heupr settings <key>=<value> [<key>=<value>...]",
+	Accept:   "/heupr accept [@login]",
+}
+
+type Command struct {
+	Name string
+	Args []string
+}
+
+// Parse returns the first command in a comment body. Names are case
+// insensitive; an unknown name is still returned so that it can be answered
+// with the usage.
+func Parse(body string) (Command, bool) {
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != Prefix {
+			continue
+		}
+		if len(fields) == 1 {
+			return Command{}, true
+		}
+		return Command{Name: strings.ToLower(fields[1]), Args: fields[2:]}, true
+	}
+	return Command{}, false
+}
+
+// Known reports whether the command name is supported.
+func (c Command) Known() bool {
+	_, ok := Required[c.Name]
+	return ok
+}
+
+// Logins returns the arguments with any leading "@" removed.
+func (c Command) Logins() []string {
+	logins := []string{}
+	for _, arg := range c.Args {
+		if login := strings.TrimPrefix(arg, "@"); login != "" {
+			logins = append(logins, login)
+		}
+	}
+	return logins
+}
+
+// Pairs parses key=value arguments. Keys are lower cased.
+func (c Command) Pairs() (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, arg := range c.Args {
+		i := strings.Index(arg, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("expected key=value, got %q", arg)
+		}
+		pairs[strings.ToLower(arg[:i])] = arg[i+1:]
+	}
+	return pairs, nil
+}
+
+// Permitted reports whether a user with the given permission level may run
+// the command.
+func (c Command) Permitted(permission string) bool {
+	required, ok := Required[c.Name]
+	if !ok {
+		return false
+	}
+	return ranks[permission] >= ranks[required]
+}
+
+// Reply is the structured response posted for a command.
+type Reply struct {
+	User    string
+	Command Command
+	OK      bool
+	Message string
+	Details []string
+}
+
+// Render returns the markdown comment body.
+func (r Reply) Render() string {
+	buffer := new(bytes.Buffer)
+	status := ":white_check_mark:"
+	if !r.OK {
+		status = ":x:"
+	}
+	invocation := strings.TrimSpace(strings.Join(append([]string{Prefix, r.Command.Name}, r.Command.Args...), " "))
+	fmt.Fprintf(buffer, "@%v %v `%v`\n\n%v\n", r.User, status, invocation, r.Message)
+	if len(r.Details) > 0 {
+		buffer.WriteString("\n")
+		for _, detail := range r.Details {
+			fmt.Fprintf(buffer, "- %v\n", detail)
+		}
+	}
+	return buffer.String()
+}
+
+// UsageReply answers an unknown or malformed command.
+func UsageReply(user string, c Command, message string) Reply {
+	details := []string{}
+	for _, name := range []string{Assign, Relabel, Ignore, Explain, Settings, Accept} {
+		details = append(details, "`"+Usage[name]+"`")
+	}
+	return Reply{User: user, Command: c, Message: message, Details: details}
+}
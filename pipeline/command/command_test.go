@@ -0,0 +1,87 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected Command
+		ok       bool
+	}{
+		{"/heupr assign @luke @leia", Command{Name: Assign, Args: []string{"@luke", "@leia"}}, true},
+		{"Thanks!\n  /heupr RELABEL bug\nmore", Command{Name: Relabel, Args: []string{"bug"}}, true},
+		{"/heupr explain", Command{Name: Explain, Args: []string{}}, true},
+		{"/heupr", Command{}, true},
+		{"no, I am your father /heupr assign", Command{}, false},
+		{"/heuprassign", Command{}, false},
+	}
+	for _, c := range cases {
+		actual, ok := Parse(c.body)
+		if ok != c.ok || !reflect.DeepEqual(actual, c.expected) {
+			t.Error(
+				"\nCOMMAND NOT PARSED",
+				"\nBODY:     ", c.body,
+				"\nEXPECTED: ", c.expected, c.ok,
+				"\nACTUAL:   ", actual, ok,
+			)
+		}
+	}
+}
+
+func TestPermitted(t *testing.T) {
+	cases := []struct {
+		name       string
+		permission string
+		expected   bool
+	}{
+		{Assign, WritePermission, true},
+		{Assign, ReadPermission, false},
+		{Explain, ReadPermission, true},
+		{Explain, NonePermission, false},
+		{Settings, WritePermission, false},
+		{Settings, AdminPermission, true},
+		{"deathstar", AdminPermission, false},
+	}
+	for _, c := range cases {
+		if actual := (Command{Name: c.name}).Permitted(c.permission); actual != c.expected {
+			t.Error(
+				"\nPERMISSION NOT CHECKED",
+				"\nCOMMAND:  ", c.name, c.permission,
+				"\nEXPECTED: ", c.expected,
+				"\nACTUAL:   ", actual,
+			)
+		}
+	}
+}
+
+func TestPairs(t *testing.T) {
+	pairs, err := Command{Name: Settings, Args: []string{"Triager=off", "ignore-users=han,chewie"}}.Pairs()
+	expected := map[string]string{"triager": "off", "ignore-users": "han,chewie"}
+	if err != nil || !reflect.DeepEqual(pairs, expected) {
+		t.Error(
+			"\nSETTINGS NOT PARSED",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", pairs, err,
+		)
+	}
+	if _, err := (Command{Name: Settings, Args: []string{"triager"}}).Pairs(); err == nil {
+		t.Error("\nMALFORMED PAIR ACCEPTED")
+	}
+}
+
+func TestReplyRender(t *testing.T) {
+	body := Reply{User: "yoda", Command: Command{Name: Assign, Args: []string{"@luke"}}, OK: true, Message: "Assigned @luke.", Details: []string{"one"}}.Render()
+	for _, expected := range []string{"@yoda :white_check_mark: `/heupr assign @luke`", "Assigned @luke.", "- one"} {
+		if !strings.Contains(body, expected) {
+			t.Error(
+				"\nREPLY NOT RENDERED",
+				"\nEXPECTED: ", expected,
+				"\nACTUAL:   ", body,
+			)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package dataset stores training data as JSON Lines so backtests can be
+// reproduced exactly without network access.
+//
+// A dataset file holds one Record per line. Each record is a conflated
+// ExpandedIssue, encoded with the field names of the conflation and go-github
+// types, together with its Provenance: where and when it was exported and the
+// format version. Records are written in issue (then pull request) number
+// order, so exporting the same data twice gives identical files.
+//
+//	{"version":1,"provenance":{"source":"cache","repo":"dotnet/corefx","exported_at":"..."},"issue":{"PullRequest":{...},"Issue":{...},"Conflate":true,"IsTrained":false}}
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/gateway/conflation"
+)
+
+// Version is the format version written to every record.
+const Version = 1
+
+// Sources of exported issues.
+const (
+	SourceCache    = "cache"
+	SourceDatabase = "database"
+	SourceJira     = "jira"
+)
+
+// Provenance records where a record was exported from. Repo is "owner/name"
+// when known, or the project key for Jira exports; RepoID is set for
+// database exports.
+type Provenance struct {
+	Source     string    `json:"source"`
+	Repo       string    `json:"repo,omitempty"`
+	RepoID     int64     `json:"repo_id,omitempty"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Record is a line of a dataset file.
+type Record struct {
+	Version    int                      `json:"version"`
+	Provenance Provenance               `json:"provenance"`
+	Issue      conflation.ExpandedIssue `json:"issue"`
+}
+
+// Write writes the issues with their provenance, ordered by number. The
+// input slice is not modified.
+func Write(w io.Writer, provenance Provenance, issues []conflation.ExpandedIssue) error {
+	ordered := make([]conflation.ExpandedIssue, len(issues))
+	copy(ordered, issues)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.Issue.GetNumber() != b.Issue.GetNumber() {
+			return a.Issue.GetNumber() < b.Issue.GetNumber()
+		}
+		return a.PullRequest.GetNumber() < b.PullRequest.GetNumber()
+	})
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for _, issue := range ordered {
+		if err := encoder.Encode(Record{Version: Version, Provenance: provenance, Issue: issue}); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// Read reads every record of a dataset; records of an unknown version are
+// rejected.
+func Read(r io.Reader) ([]Record, error) {
+	records := []Record{}
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		record := Record{}
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("dataset record %d: %v", line, err)
+		}
+		if record.Version != Version {
+			return nil, fmt.Errorf("dataset record %d: unsupported version %d", line, record.Version)
+		}
+		records = append(records, record)
+	}
+}
+
+// Issues returns the issues of the records.
+func Issues(records []Record) []conflation.ExpandedIssue {
+	issues := make([]conflation.ExpandedIssue, len(records))
+	for i := range records {
+		issues[i] = records[i].Issue
+	}
+	return issues
+}
+
+// Conflate links the issues and pull requests the way the backtests and the
+// backend do.
+func Conflate(issues []*github.Issue, pulls []*github.PullRequest) []conflation.ExpandedIssue {
+	return conflation.Conflate(issues, pulls)
+}
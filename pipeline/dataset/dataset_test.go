@@ -0,0 +1,109 @@
+package dataset
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/gateway"
+	"core/pipeline/gateway/conflation"
+	"core/utils"
+)
+
+func buildIssue(number int, title, assignee string) conflation.ExpandedIssue {
+	issue := github.Issue{Number: &number, Title: &title, Assignees: []*github.User{{Login: &assignee}}}
+	return conflation.ExpandedIssue{Issue: conflation.CRIssue{Issue: issue}, Conflate: true}
+}
+
+var provenance = Provenance{
+	Source:     SourceCache,
+	Repo:       "rebellion/x-wing",
+	ExportedAt: time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC),
+}
+
+func TestRoundTrip(t *testing.T) {
+	issues := []conflation.ExpandedIssue{
+		buildIssue(3, "Torpedo", "Luke"),
+		buildIssue(1, "Hyperdrive", "Chewbacca"),
+		buildIssue(2, "Astromech", "R2-D2"),
+	}
+	var first, second bytes.Buffer
+	if err := Write(&first, provenance, issues); err != nil {
+		t.Fatal(err)
+	}
+	reversed := []conflation.ExpandedIssue{issues[2], issues[1], issues[0]}
+	if err := Write(&second, provenance, reversed); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Error("\nEXPORT NOT DETERMINISTIC", "\nEXPECTED: ", first.String(), "\nACTUAL:   ", second.String())
+	}
+	if lines := strings.Count(first.String(), "\n"); lines != 3 {
+		t.Error("\nUNEXPECTED LINE COUNT", "\nEXPECTED: ", 3, "\nACTUAL:   ", lines)
+	}
+	if issues[0].Issue.GetNumber() != 3 {
+		t.Error("\nINPUT ISSUES MODIFIED", "\nACTUAL:   ", issues[0].Issue.GetNumber())
+	}
+
+	records, err := Read(&first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := Issues(records)
+	if len(decoded) != 3 {
+		t.Fatal("\nRECORDS MISSING", "\nACTUAL:   ", len(decoded))
+	}
+	for i, issue := range decoded {
+		if issue.Issue.GetNumber() != i+1 || !issue.Conflate || issue.Issue.Assignees[0].GetLogin() != issues[(i+1)%3].Issue.Assignees[0].GetLogin() {
+			t.Error("\nISSUE NOT RESTORED", "\nACTUAL:   ", issue.Issue.GetNumber(), issue.Issue.GetTitle())
+		}
+		if records[i].Version != Version || records[i].Provenance != provenance {
+			t.Error("\nPROVENANCE NOT RESTORED", "\nEXPECTED: ", provenance, "\nACTUAL:   ", records[i].Provenance)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := Read(strings.NewReader(`{"version":2,"issue":{}}`)); err == nil {
+		t.Error("\nUNSUPPORTED VERSION ACCEPTED")
+	}
+	if _, err := Read(strings.NewReader(`{"version":1,"issue":{}}` + "\n{")); err == nil {
+		t.Error("\nTRUNCATED DATASET ACCEPTED")
+	}
+	if records, err := Read(strings.NewReader("")); err != nil || len(records) != 0 {
+		t.Error("\nEMPTY DATASET NOT EMPTY", "\nACTUAL:   ", records, err)
+	}
+}
+
+func TestFromCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dataset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := utils.Config.DataCachesPath
+	utils.Config.DataCachesPath = dir
+	defer func() { utils.Config.DataCachesPath = path }()
+
+	if _, _, err := FromCache("rebellion", "x-wing"); err != gateway.ErrNotCached {
+		t.Error("\nCACHE MISS NOT REPORTED", "\nEXPECTED: ", gateway.ErrNotCached, "\nACTUAL:   ", err)
+	}
+
+	number := 1
+	cache := gateway.DiskCache{}
+	if err := cache.Set("/rebellion-x-wingclosed-issues", []*github.Issue{{Number: &number}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Set("/rebellion-x-wingclosed-pulls", []*github.PullRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	issues, pulls, err := FromCache("rebellion", "x-wing")
+	if err != nil || len(issues) != 1 || len(pulls) != 0 || issues[0].GetNumber() != 1 {
+		t.Error("\nCACHE NOT READ", "\nACTUAL:   ", issues, pulls, err)
+	}
+}
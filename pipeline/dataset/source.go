@@ -0,0 +1,72 @@
+package dataset
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/gateway"
+)
+
+// FromCache reads the closed issues and pull requests of a repo from the
+// gateway disk cache without contacting GitHub.
+func FromCache(owner, repo string) ([]*github.Issue, []*github.PullRequest, error) {
+	cached := gateway.CachedGateway{DiskCache: &gateway.DiskCache{}}
+	issues, err := cached.GetClosedIssues(owner, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	pulls, err := cached.GetClosedPulls(owner, repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return issues, pulls, nil
+}
+
+// FromDatabase reads the latest state of the closed issues and pull requests
+// of a repo from the github_events table written by the ingestor.
+func FromDatabase(db *sql.DB, repoID int64) ([]*github.Issue, []*github.PullRequest, error) {
+	results, err := db.Query(`
+    SELECT g.is_pull, g.payload
+    FROM github_events g
+    JOIN (
+        SELECT max(id) id
+        FROM github_events
+        WHERE repo_id = ?
+        GROUP BY issues_id, number
+    ) T
+    ON T.id = g.id AND g.action = 'closed'
+    ORDER BY g.number`, repoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer results.Close()
+
+	issues := []*github.Issue{}
+	pulls := []*github.PullRequest{}
+	for results.Next() {
+		var isPull bool
+		var payload []byte
+		if err := results.Scan(&isPull, &payload); err != nil {
+			return nil, nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.UseNumber()
+		if isPull {
+			pull := &github.PullRequest{}
+			if err := decoder.Decode(pull); err != nil {
+				return nil, nil, err
+			}
+			pulls = append(pulls, pull)
+		} else {
+			issue := &github.Issue{}
+			if err := decoder.Decode(issue); err != nil {
+				return nil, nil, err
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues, pulls, results.Err()
+}
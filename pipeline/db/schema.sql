@@ -15,6 +15,31 @@
 /*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;
 /*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;
 
+--
+-- Table structure for table `actions`
+--
+
+DROP TABLE IF EXISTS `actions`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `actions` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `repo_id` int(11) DEFAULT NULL,
+  `number` int(11) DEFAULT NULL,
+  `action` varchar(20) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL,
+  `targets` JSON DEFAULT NULL,
+  `body` text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `actor` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `model_version` varchar(25) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `scores` JSON DEFAULT NULL,
+  `settings` JSON DEFAULT NULL,
+  `error` text CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `actions_repo_created` (`repo_id`, `created_at`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `github_event_assignees`
 --
@@ -85,6 +110,51 @@ CREATE TABLE `integrations` (
 ) AUTO_INCREMENT=360;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `integrations_assignee_caps`
+--
+
+DROP TABLE IF EXISTS `integrations_assignee_caps`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `integrations_assignee_caps` (
+  `repo_id` int(11) NOT NULL,
+  `assignee` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL,
+  `cap` int(11) NOT NULL,
+  PRIMARY KEY (`repo_id`, `assignee`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `integrations_model_params`
+--
+
+DROP TABLE IF EXISTS `integrations_model_params`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `integrations_model_params` (
+  `repo_id` int(11) NOT NULL,
+  `params` text NOT NULL,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`repo_id`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `integrations_paused`
+--
+
+DROP TABLE IF EXISTS `integrations_paused`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `integrations_paused` (
+  `repo_id` int(11) NOT NULL,
+  `paused` tinyint(1) NOT NULL DEFAULT 0,
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`repo_id`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `integrations_settings`
 --
@@ -100,10 +170,33 @@ CREATE TABLE `integrations_settings` (
   `twitter` varchar(25) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
   `enable_triager` tinyint(1) NOT NULL,
   `enable_labeler` tinyint(1) NOT NULL,
+  `codeowners_mode` varchar(10) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `top_k` int(11) DEFAULT NULL,
+  `window_days` int(11) DEFAULT NULL,
+  `window_limit` int(11) DEFAULT NULL,
+  `assignment_policy` varchar(20) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `shadow_mode` tinyint(1) NOT NULL DEFAULT 0,
+  `config_file` tinyint(1) NOT NULL DEFAULT 0,
   PRIMARY KEY (`id`)
 ) AUTO_INCREMENT=251;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `integrations_settings_capacity_lk`
+--
+
+DROP TABLE IF EXISTS `integrations_settings_capacity_lk`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `integrations_settings_capacity_lk` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `integrations_settings_fk` bigint(20) DEFAULT NULL,
+  `user` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `weight` double NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `integrations_settings_ignorelabels_lk`
 --
@@ -151,6 +244,64 @@ CREATE TABLE `integrations_settings_labels_bif_lk` (
   PRIMARY KEY (`id`)
 ) AUTO_INCREMENT=22;
 /*!40101 SET character_set_client = @saved_cs_client */;
+--
+-- Table structure for table `issue_feedback`
+--
+
+DROP TABLE IF EXISTS `issue_feedback`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `issue_feedback` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `repo_id` int(11) DEFAULT NULL,
+  `number` int(11) DEFAULT NULL,
+  `action` varchar(32) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `actor` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `assignee` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `label` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `payload` JSON COLLATE utf8_bin NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `model_quality`
+--
+
+DROP TABLE IF EXISTS `model_quality`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `model_quality` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `repo_id` int(11) DEFAULT NULL,
+  `assignments` int(11) NOT NULL DEFAULT 0,
+  `assignment_overrides` int(11) NOT NULL DEFAULT 0,
+  `labels` int(11) NOT NULL DEFAULT 0,
+  `label_overrides` int(11) NOT NULL DEFAULT 0,
+  `recorded_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `shadow_actions`
+--
+
+DROP TABLE IF EXISTS `shadow_actions`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `shadow_actions` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `repo_id` int(11) DEFAULT NULL,
+  `number` int(11) DEFAULT NULL,
+  `action` varchar(20) CHARACTER SET utf8 COLLATE utf8_general_ci NOT NULL,
+  `value` varchar(255) CHARACTER SET utf8 COLLATE utf8_general_ci DEFAULT NULL,
+  `confidence` double NOT NULL DEFAULT 0,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
+/*!40101 SET character_set_client = @saved_cs_client */;
 /*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;
 
 /*!40101 SET SQL_MODE=@OLD_SQL_MODE */;
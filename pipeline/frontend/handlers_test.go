@@ -15,6 +15,8 @@ import (
 	"github.com/google/go-github/github"
 	"github.com/gorilla/sessions"
 	"github.com/stretchr/testify/assert"
+
+	"core/testutil/fakegithub"
 )
 
 var req = &http.Request{}
@@ -139,36 +141,20 @@ func Test_reposHTML(t *testing.T) {
 }
 
 func Test_repos(t *testing.T) {
-	// Dummy GitHub server to return values for ListUserInstallations.
-	mux := http.NewServeMux()
-	mux.HandleFunc("/user/installations/1234/repositories", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"repositories":[{"id":-65,"full_name":"contingency/chancellor"},{"id":-66,"full_name":"contingency/jedi"}]}`)
-	})
-	mux.HandleFunc("/repos/contingency/chancellor/labels", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `[{"name":"do-not-use"}]`)
-	})
-	mux.HandleFunc("/repos/contingency/jedi/labels", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `[{"name":"definitely-use"}]`)
-	})
-	mux.HandleFunc("/user/installations", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"installations":[{"id":1234,"app_id":6807}]}`)
-	})
-
-	server := httptest.NewServer(mux)
-	testURL, _ := url.Parse(server.URL + "/")
+	gh := fakegithub.New()
+	defer gh.Close()
+	gh.AddRepoWithID(-65, "contingency", "chancellor")
+	gh.AddRepoWithID(-66, "contingency", "jedi")
+	gh.AddLabel("contingency", "chancellor", "do-not-use")
+	gh.AddLabel("contingency", "jedi", "definitely-use")
+	gh.AddInstallation(1234, 6807, "contingency/chancellor", "contingency/jedi")
 
 	newUserToServerClient = func(code string) (*github.Client, error) {
-		c := github.NewClient(nil)
-		c.BaseURL = testURL
-		c.UploadURL = testURL
-		return c, nil
+		return gh.Client(), nil
 	}
 
 	newServerToServerClient = func(appId int, installationId int64) (*github.Client, error) {
-		c := github.NewClient(nil)
-		c.BaseURL = testURL
-		c.UploadURL = testURL
-		return c, nil
+		return gh.Client(), nil
 	}
 
 	assert := assert.New(t)
@@ -1,12 +1,21 @@
 package gateway
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
 
 	"core/utils"
 )
 
+// ErrNotCached is returned on a cache miss when there is no Gateway to fall
+// back on.
+var ErrNotCached = errors.New("not in the disk cache")
+
+// CachedGateway serves GitHub data from the disk cache, downloading and
+// caching it through Gateway on a miss. A nil Gateway makes it offline.
 type CachedGateway struct {
 	Gateway   *Gateway
 	DiskCache *DiskCache
@@ -15,6 +24,9 @@ type CachedGateway struct {
 func (c *CachedGateway) getPulls(owner, repo, state string) (pulls []*github.PullRequest, err error) {
 	key := "/" + owner + "-" + repo + state + "-pulls"
 	cacheError := c.DiskCache.TryGet(key, &pulls)
+	if cacheError != nil && c.Gateway == nil {
+		return nil, ErrNotCached
+	}
 	if cacheError != nil {
 		utils.AppLog.Warn("CachedGateway: ", zap.Error(cacheError))
 		utils.AppLog.Info("CachedGateway: Starting - Downloading Pulls from Github.",
@@ -36,6 +48,9 @@ func (c *CachedGateway) getPulls(owner, repo, state string) (pulls []*github.Pul
 func (c *CachedGateway) getIssues(owner, repo, state string) (issues []*github.Issue, err error) {
 	key := "/" + owner + "-" + repo + state + "-issues"
 	cacheError := c.DiskCache.TryGet(key, &issues)
+	if cacheError != nil && c.Gateway == nil {
+		return nil, ErrNotCached
+	}
 	if cacheError != nil {
 		utils.AppLog.Warn("CachedGateway: ", zap.Error(cacheError))
 		utils.AppLog.Info("CachedGateway: Starting - Downloading Issues from Github.",
@@ -69,3 +84,18 @@ func (c *CachedGateway) GetOpenIssues(owner, repo string) ([]*github.Issue, erro
 func (c *CachedGateway) GetClosedIssues(owner, repo string) ([]*github.Issue, error) {
 	return c.getIssues(owner, repo, "closed")
 }
+
+func (c *CachedGateway) GetPullFiles(owner, repo string, number int) (files []*github.CommitFile, err error) {
+	key := "/" + owner + "-" + repo + "-pull-" + strconv.Itoa(number) + "-files"
+	cacheError := c.DiskCache.TryGet(key, &files)
+	if cacheError != nil && c.Gateway == nil {
+		return nil, ErrNotCached
+	}
+	if cacheError != nil {
+		files, err = c.Gateway.GetPullFiles(owner, repo, number)
+		if err == nil {
+			c.DiskCache.Set(key, files)
+		}
+	}
+	return files, err
+}
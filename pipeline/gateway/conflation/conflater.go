@@ -18,6 +18,23 @@ type Context struct {
 	Issues []ExpandedIssue
 }
 
+// Conflate links the issues and pull requests with the scenarios and
+// algorithm used by the backend.
+func Conflate(issues []*github.Issue, pulls []*github.PullRequest) []ExpandedIssue {
+	context := &Context{}
+	conflator := Conflator{
+		Scenarios:            []Scenario{&Scenario2{}, &Scenario3{}, &Scenario7{}},
+		ConflationAlgorithms: []ConflationAlgorithm{&ComboAlgorithm{Context: context}},
+		Normalizer:           Normalizer{Context: context},
+		Context:              context,
+	}
+	conflator.Context.Issues = []ExpandedIssue{}
+	conflator.SetIssueRequests(issues)
+	conflator.SetPullRequests(pulls)
+	conflator.Conflate()
+	return conflator.Context.Issues
+}
+
 func (c *Conflator) SetPullRequests(pulls []*github.PullRequest) {
 	for i := 0; i < len(pulls); i++ {
 		c.Context.Issues = append(c.Context.Issues, ExpandedIssue{PullRequest: CRPullRequest{*pulls[i], []int{}, []CRIssue{}}, IsTrained: false})
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/google/go-github/github"
 )
@@ -114,3 +115,56 @@ func (g *Gateway) GetLabels(owner, repo string) ([]*github.Label, error) {
 	}
 	return output, nil
 }
+
+func (g *Gateway) GetPullFiles(owner, repo string, number int) ([]*github.CommitFile, error) {
+	options := &github.ListOptions{PerPage: 100}
+	output := []*github.CommitFile{}
+	for {
+		files, resp, err := g.Client.PullRequests.ListFiles(context.Background(), owner, repo, number, options)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, files...)
+		if resp.NextPage == 0 || g.UnitTesting {
+			break
+		} else {
+			options.Page = resp.NextPage
+		}
+	}
+	return output, nil
+}
+
+// GetCodeOwners returns the contents of the first CODEOWNERS file found in
+// the given locations or nil if the repository has none.
+func (g *Gateway) GetCodeOwners(owner, repo string, locations []string) ([]byte, error) {
+	for _, location := range locations {
+		content, err := g.GetFile(owner, repo, location)
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			return content, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetFile returns the contents of a file on the default branch or nil if it
+// does not exist.
+func (g *Gateway) GetFile(owner, repo, path string) ([]byte, error) {
+	file, _, resp, err := g.Client.Repositories.GetContents(context.Background(), owner, repo, path, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, nil
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
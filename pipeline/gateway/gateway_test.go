@@ -21,6 +21,12 @@ func TestGateway(t *testing.T) {
 	mux.HandleFunc(fmt.Sprintf("/repos/%v/%v/pulls", owner, repo), func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `[{"id":321}]`)
 	})
+	mux.HandleFunc(fmt.Sprintf("/repos/%v/%v/pulls/7/files", owner, repo), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"filename":"sith/holocron.go"}]`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/repos/%v/%v/contents/.github/CODEOWNERS", owner, repo), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"type":"file","encoding":"base64","content":"KiBAZGFydGgta3JheXQK"}`)
+	})
 	server := httptest.NewServer(mux)
 	url, _ := url.Parse(server.URL + "/")
 
@@ -45,4 +51,22 @@ func TestGateway(t *testing.T) {
 			t.Errorf("failed retrieving pulls: %v", err)
 		}
 	})
+	t.Run("pull files", func(t *testing.T) {
+		files, err := testGateway.GetPullFiles(owner, repo, 7)
+		if err != nil {
+			t.Errorf("failed retrieving pull files: %v", err)
+		}
+		if len(files) != 1 || files[0].GetFilename() != "sith/holocron.go" {
+			t.Errorf("unexpected pull files: %v", files)
+		}
+	})
+	t.Run("codeowners", func(t *testing.T) {
+		content, err := testGateway.GetCodeOwners(owner, repo, []string{"CODEOWNERS", ".github/CODEOWNERS"})
+		if err != nil {
+			t.Errorf("failed retrieving codeowners: %v", err)
+		}
+		if string(content) != "* @darth-krayt\n" {
+			t.Errorf("unexpected codeowners: %q", content)
+		}
+	})
 }
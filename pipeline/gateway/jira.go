@@ -0,0 +1,207 @@
+package gateway
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/github"
+
+	"core/pipeline/gateway/conflation"
+)
+
+// DefaultFixedResolutions are the Jira resolutions of issues fixed by their
+// assignee.
+var DefaultFixedResolutions = []string{"Fixed", "Done"}
+
+// JiraSource reads the issues of a Jira project in the shape of GitHub
+// issues, for the backtests and offline training; the live pipeline only
+// ingests GitHub webhooks. The issues are mapped as follows:
+//   - the number is that of the issue key and the URL the REST self link;
+//   - the reporter is the user and the assignee the only assignee;
+//   - the issue type (e.g. "Bug", "New Feature") is the first label, followed
+//     by the components and the Jira labels, so the labeler settings can name
+//     Jira issue types;
+//   - a resolved issue is closed at its resolution date, or at its last
+//     update when Jira has none, and marked Conflate when the resolution is a
+//     fix and it has an assignee.
+type JiraSource struct {
+	Client *jira.Client
+	// JQL further restricts the issues, e.g. "created >= 2005-08-05".
+	JQL string
+	// FixedResolutions are the resolutions of issues fixed by their assignee;
+	// DefaultFixedResolutions if nil.
+	FixedResolutions []string
+	// Corrections replace the issue type label of issues by key, e.g. with a
+	// manual classification (see ReadCorrections).
+	Corrections map[string]string
+}
+
+func (j *JiraSource) OpenIssues(project string) ([]conflation.ExpandedIssue, error) {
+	return j.search(project, "resolution IS EMPTY")
+}
+
+func (j *JiraSource) ClosedIssues(project string) ([]conflation.ExpandedIssue, error) {
+	return j.search(project, "resolution IS NOT EMPTY")
+}
+
+func (j *JiraSource) search(project, resolution string) ([]conflation.ExpandedIssue, error) {
+	jql := "project = \"" + project + "\" AND " + resolution
+	if j.JQL != "" {
+		jql += " AND (" + j.JQL + ")"
+	}
+	jql += " ORDER BY key ASC"
+	output := []conflation.ExpandedIssue{}
+	opt := &jira.SearchOptions{StartAt: 0, MaxResults: 100}
+	err := j.Client.Issue.SearchPages(jql, opt, func(issue jira.Issue) error {
+		output = append(output, j.Expand(issue))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// Expand maps a Jira issue into an ExpandedIssue.
+func (j *JiraSource) Expand(issue jira.Issue) conflation.ExpandedIssue {
+	fields := issue.Fields
+	if fields == nil {
+		fields = &jira.IssueFields{}
+	}
+	gh := github.Issue{
+		Title:  github.String(fields.Summary),
+		Body:   github.String(fields.Description),
+		State:  github.String("open"),
+		User:   &github.User{Login: github.String(jiraLogin(fields.Reporter))},
+		Labels: []github.Label{},
+		Repository: &github.Repository{
+			Name:     github.String(fields.Project.Key),
+			FullName: github.String(fields.Project.Key),
+		},
+	}
+	if id, err := strconv.ParseInt(issue.ID, 10, 64); err == nil {
+		gh.ID = github.Int64(id)
+	}
+	if id, err := strconv.ParseInt(fields.Project.ID, 10, 64); err == nil {
+		gh.Repository.ID = github.Int64(id)
+	}
+	gh.Number = github.Int(jiraNumber(issue))
+	if j.Client != nil && issue.Key != "" {
+		base := j.Client.GetBaseURL()
+		gh.HTMLURL = github.String(strings.TrimSuffix(base.String(), "/") + "/browse/" + issue.Key)
+	}
+	gh.URL = github.String(issue.Self)
+	if issue.Self == "" {
+		gh.URL = github.String(gh.GetHTMLURL())
+	}
+	if created := time.Time(fields.Created); !created.IsZero() {
+		gh.CreatedAt = &created
+	}
+
+	typeLabel := fields.Type.Name
+	if corrected, ok := j.Corrections[issue.Key]; ok {
+		typeLabel = corrected
+	}
+	if typeLabel != "" {
+		gh.Labels = append(gh.Labels, github.Label{Name: github.String(typeLabel)})
+	}
+	for _, component := range fields.Components {
+		if component != nil && component.Name != "" {
+			gh.Labels = append(gh.Labels, github.Label{Name: github.String(component.Name)})
+		}
+	}
+	for _, label := range fields.Labels {
+		gh.Labels = append(gh.Labels, github.Label{Name: github.String(label)})
+	}
+
+	if fields.Assignee != nil {
+		assignee := &github.User{Login: github.String(jiraLogin(fields.Assignee))}
+		gh.Assignee = assignee
+		gh.Assignees = []*github.User{assignee}
+	}
+
+	fixed := false
+	if fields.Resolution != nil {
+		gh.State = github.String("closed")
+		if resolved := time.Time(fields.Resolutiondate); !resolved.IsZero() {
+			gh.ClosedAt = &resolved
+		} else if updated := time.Time(fields.Updated); !updated.IsZero() {
+			gh.ClosedAt = &updated
+		}
+		fixed = j.fixed(fields.Resolution.Name) && fields.Assignee != nil
+	}
+
+	labeled := len(gh.Labels) != 0
+	triaged := fields.Assignee != nil
+	return conflation.ExpandedIssue{
+		Issue: conflation.CRIssue{
+			Issue:      gh,
+			RefPullIds: []int{},
+			RefPulls:   []conflation.CRPullRequest{},
+			Labeled:    &labeled,
+			Triaged:    &triaged,
+		},
+		Conflate: fixed,
+	}
+}
+
+func (j *JiraSource) fixed(resolution string) bool {
+	resolutions := j.FixedResolutions
+	if resolutions == nil {
+		resolutions = DefaultFixedResolutions
+	}
+	for _, r := range resolutions {
+		if strings.EqualFold(r, resolution) {
+			return true
+		}
+	}
+	return false
+}
+
+// jiraNumber returns the number of the issue key (e.g. 42 for "DS-42"), or
+// else the issue ID, since the models require a number.
+func jiraNumber(issue jira.Issue) int {
+	if i := strings.LastIndex(issue.Key, "-"); i != -1 {
+		if number, err := strconv.Atoi(issue.Key[i+1:]); err == nil {
+			return number
+		}
+	}
+	number, _ := strconv.Atoi(issue.ID)
+	return number
+}
+
+// jiraLogin returns the user name on Jira Server and the account ID on Jira
+// Cloud, where names are not exposed.
+func jiraLogin(user *jira.User) string {
+	switch {
+	case user == nil:
+		return ""
+	case user.Name != "":
+		return user.Name
+	case user.AccountID != "":
+		return user.AccountID
+	}
+	return user.DisplayName
+}
+
+// ReadCorrections reads issue type corrections from a CSV file whose first
+// row is a header and whose first two columns are the issue key and the
+// corrected type.
+func ReadCorrections(r io.Reader) (map[string]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	corrections := make(map[string]string)
+	for i := 1; i < len(records); i++ {
+		if len(records[i]) < 2 {
+			continue
+		}
+		corrections[records[i][0]] = records[i][1]
+	}
+	return corrections, nil
+}
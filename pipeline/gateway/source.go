@@ -0,0 +1,109 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/gateway/conflation"
+)
+
+// Source is an issue tracker the models can be trained on and run against
+// offline, e.g. in the backtests.
+// A project is "owner/name" on GitHub and a project key on Jira. Closed
+// issues marked Conflate are the ones resolved by their assignee, the only
+// ones the assignment model trains on.
+type Source interface {
+	OpenIssues(project string) ([]conflation.ExpandedIssue, error)
+	ClosedIssues(project string) ([]conflation.ExpandedIssue, error)
+}
+
+// IssueGateway is satisfied by Gateway and CachedGateway.
+type IssueGateway interface {
+	GetOpenIssues(owner, repo string) ([]*github.Issue, error)
+	GetClosedIssues(owner, repo string) ([]*github.Issue, error)
+	GetOpenPulls(owner, repo string) ([]*github.PullRequest, error)
+	GetClosedPulls(owner, repo string) ([]*github.PullRequest, error)
+}
+
+// GitHubSource reads the issues and pull requests of a repository and
+// conflates them.
+type GitHubSource struct {
+	Gateway IssueGateway
+}
+
+func splitRepo(project string) (string, string, error) {
+	r := strings.Split(project, "/")
+	if len(r) != 2 || r[0] == "" || r[1] == "" {
+		return "", "", fmt.Errorf("invalid github repo %q, expected owner/name", project)
+	}
+	return r[0], r[1], nil
+}
+
+func (g *GitHubSource) OpenIssues(project string) ([]conflation.ExpandedIssue, error) {
+	owner, repo, err := splitRepo(project)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := g.Gateway.GetOpenIssues(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	pulls, err := g.Gateway.GetOpenPulls(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return conflation.Conflate(issues, pulls), nil
+}
+
+func (g *GitHubSource) ClosedIssues(project string) ([]conflation.ExpandedIssue, error) {
+	owner, repo, err := splitRepo(project)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := g.Gateway.GetClosedIssues(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	pulls, err := g.Gateway.GetClosedPulls(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return conflation.Conflate(issues, pulls), nil
+}
+
+// CachedSource serves the issues of a Source from the disk cache, reading
+// them from the Source on a miss. Name tells sources apart in the cache.
+type CachedSource struct {
+	Name      string
+	Source    Source
+	DiskCache *DiskCache
+}
+
+func (c *CachedSource) get(project, state string) (issues []conflation.ExpandedIssue, err error) {
+	key := "/" + c.Name + "-" + strings.Replace(project, "/", "-", -1) + state + "-source"
+	cacheError := c.DiskCache.TryGet(key, &issues)
+	if cacheError != nil && c.Source == nil {
+		return nil, ErrNotCached
+	}
+	if cacheError != nil {
+		if state == "open" {
+			issues, err = c.Source.OpenIssues(project)
+		} else {
+			issues, err = c.Source.ClosedIssues(project)
+		}
+		if err == nil {
+			c.DiskCache.Set(key, issues)
+		}
+	}
+	return issues, err
+}
+
+func (c *CachedSource) OpenIssues(project string) ([]conflation.ExpandedIssue, error) {
+	return c.get(project, "open")
+}
+
+func (c *CachedSource) ClosedIssues(project string) ([]conflation.ExpandedIssue, error) {
+	return c.get(project, "closed")
+}
@@ -0,0 +1,206 @@
+package gateway
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/google/go-github/github"
+
+	"core/models/bhattacharya"
+	"core/pipeline/gateway/conflation"
+	"core/testutil/fakegithub"
+	"core/utils"
+)
+
+func TestGitHubSource(t *testing.T) {
+	gh := fakegithub.New()
+	defer gh.Close()
+	closed := time.Date(1983, 5, 25, 0, 0, 0, 0, time.UTC)
+	gh.AddIssue("rebellion", "x-wing", github.Issue{
+		Title:     github.String("Shield generator offline"),
+		Body:      github.String("The shield generator on Endor is still up"),
+		ClosedAt:  &closed,
+		Assignees: []*github.User{{Login: github.String("Han")}},
+	})
+	gh.AddPull("rebellion", "x-wing", github.PullRequest{
+		Title:    github.String("Take down the shield generator"),
+		Body:     github.String("Fixes #1"),
+		ClosedAt: &closed,
+		MergedAt: &closed,
+		User:     &github.User{Login: github.String("Han")},
+	})
+	gh.AddIssue("rebellion", "x-wing", github.Issue{Title: github.String("Ewoks need gliders")})
+
+	source := GitHubSource{Gateway: &Gateway{Client: gh.Client()}}
+	issues, err := source.ClosedIssues("rebellion/x-wing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Error("\nUNEXPECTED CLOSED ISSUE COUNT", "\nEXPECTED: ", 2, "\nACTUAL:   ", len(issues))
+	}
+	open, err := source.OpenIssues("rebellion/x-wing")
+	if err != nil || len(open) != 1 || open[0].Issue.GetTitle() != "Ewoks need gliders" {
+		t.Error("\nUNEXPECTED OPEN ISSUES", "\nEXPECTED: ", "Ewoks need gliders", "\nACTUAL:   ", open, err)
+	}
+	if _, err := source.ClosedIssues("x-wing"); err == nil {
+		t.Error("\nINVALID REPO ACCEPTED")
+	}
+}
+
+const jiraSearch = `{"startAt":0,"maxResults":100,"total":2,"issues":[
+{"id":"10001","key":"DS-1","fields":{
+	"summary":"Exhaust port exposed","description":"A small thermal exhaust port leads to the reactor",
+	"issuetype":{"name":"Bug"},"project":{"id":"1977","key":"DS"},
+	"components":[{"name":"Reactor"}],"labels":["weakness"],
+	"reporter":{"name":"galen"},"assignee":{"name":"bevel"},
+	"resolution":{"name":"Fixed"},"created":"1977-05-25T10:00:00.000+0000","resolutiondate":"1977-05-26T10:00:00.000+0000"}},
+{"id":"10002","key":"DS-2","fields":{
+	"summary":"Superlaser recharge too slow","description":"",
+	"issuetype":{"name":"Improvement"},"project":{"id":"1977","key":"DS"},
+	"reporter":{"accountId":"5b10a2844c20165700ede21g"},"assignee":{"displayName":"Krennic"},
+	"resolution":{"name":"Won't Fix"},"created":"1977-05-25T10:00:00.000+0000","resolutiondate":"1977-05-27T10:00:00.000+0000"}}
+]}`
+
+func TestJiraSource(t *testing.T) {
+	queries := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/search", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("jql"))
+		fmt.Fprint(w, jiraSearch)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := jira.NewClient(nil, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := JiraSource{Client: client, JQL: "created >= 1977-01-01", Corrections: map[string]string{"DS-2": "Bug"}}
+	issues, err := source.ClosedIssues("DS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJQL := `project = "DS" AND resolution IS NOT EMPTY AND (created >= 1977-01-01) ORDER BY key ASC`
+	if len(queries) != 1 || queries[0] != expectedJQL {
+		t.Error("\nUNEXPECTED JQL", "\nEXPECTED: ", expectedJQL, "\nACTUAL:   ", queries)
+	}
+	if len(issues) != 2 {
+		t.Fatal("\nUNEXPECTED ISSUE COUNT", "\nEXPECTED: ", 2, "\nACTUAL:   ", len(issues))
+	}
+
+	fixed := issues[0]
+	labels := []string{}
+	for _, label := range fixed.Issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	if strings.Join(labels, ",") != "Bug,Reactor,weakness" {
+		t.Error("\nUNEXPECTED LABELS", "\nEXPECTED: ", "Bug,Reactor,weakness", "\nACTUAL:   ", labels)
+	}
+	if fixed.Issue.GetNumber() != 1 || fixed.Issue.GetID() != 10001 || fixed.Issue.Repository.GetID() != 1977 {
+		t.Error("\nUNEXPECTED IDENTIFIERS", "\nEXPECTED: ", 1, 10001, 1977, "\nACTUAL:   ", fixed.Issue.GetNumber(), fixed.Issue.GetID(), fixed.Issue.Repository.GetID())
+	}
+	if fixed.Issue.GetUser().GetLogin() != "galen" || len(fixed.Issue.Assignees) != 1 || fixed.Issue.Assignees[0].GetLogin() != "bevel" {
+		t.Error("\nUNEXPECTED USERS", "\nEXPECTED: ", "galen", "bevel", "\nACTUAL:   ", fixed.Issue.GetUser().GetLogin(), fixed.Issue.Assignees)
+	}
+	if fixed.Issue.GetState() != "closed" || !fixed.Issue.GetClosedAt().Equal(time.Date(1977, 5, 26, 10, 0, 0, 0, time.UTC)) || !fixed.Conflate {
+		t.Error("\nFIXED ISSUE NOT TRAINABLE", "\nEXPECTED: ", "closed 1977-05-26 10:00", "\nACTUAL:   ", fixed.Issue.GetState(), fixed.Issue.GetClosedAt(), fixed.Conflate)
+	}
+	if fixed.Issue.GetHTMLURL() != server.URL+"/browse/DS-1" || fixed.Issue.GetURL() != fixed.Issue.GetHTMLURL() {
+		t.Error("\nUNEXPECTED URL", "\nEXPECTED: ", server.URL+"/browse/DS-1", "\nACTUAL:   ", fixed.Issue.GetHTMLURL(), fixed.Issue.GetURL())
+	}
+
+	wontFix := issues[1]
+	if wontFix.Conflate {
+		t.Error("\nWON'T FIX ISSUE TRAINABLE")
+	}
+	if wontFix.Issue.Labels[0].GetName() != "Bug" {
+		t.Error("\nCORRECTION NOT APPLIED", "\nEXPECTED: ", "Bug", "\nACTUAL:   ", wontFix.Issue.Labels[0].GetName())
+	}
+	if wontFix.Issue.GetUser().GetLogin() != "5b10a2844c20165700ede21g" || wontFix.Issue.Assignees[0].GetLogin() != "Krennic" {
+		t.Error("\nUNEXPECTED CLOUD USERS", "\nEXPECTED: ", "5b10a2844c20165700ede21g", "Krennic", "\nACTUAL:   ", wontFix.Issue.GetUser().GetLogin(), wontFix.Issue.Assignees[0].GetLogin())
+	}
+}
+
+func TestJiraSourceNoResolutionDate(t *testing.T) {
+	updated := time.Date(1977, 5, 27, 0, 0, 0, 0, time.UTC)
+	source := JiraSource{}
+	issue := source.Expand(jira.Issue{ID: "10003", Key: "DS-3", Fields: &jira.IssueFields{
+		Summary:    "thermal exhaust port unshielded",
+		Assignee:   &jira.User{Name: "bevel"},
+		Resolution: &jira.Resolution{Name: "Fixed"},
+		Updated:    jira.Time(updated),
+	}})
+	if issue.Issue.ClosedAt == nil || !issue.Issue.ClosedAt.Equal(updated) {
+		t.Error("\nCLOSED AT NOT THE LAST UPDATE", "\nEXPECTED: ", updated, "\nACTUAL:   ", issue.Issue.ClosedAt)
+	}
+}
+
+func TestJiraSourceNBModel(t *testing.T) {
+	resolved := jira.Time(time.Date(1983, 5, 25, 0, 0, 0, 0, time.UTC))
+	issue := func(id, key, summary, assignee string) jira.Issue {
+		return jira.Issue{ID: id, Key: key, Self: "https://jira.empire.gov/rest/api/2/issue/" + id, Fields: &jira.IssueFields{
+			Summary:        summary,
+			Description:    summary,
+			Assignee:       &jira.User{Name: assignee},
+			Resolution:     &jira.Resolution{Name: "Fixed"},
+			Resolutiondate: resolved,
+		}}
+	}
+	source := JiraSource{}
+	issues := []conflation.ExpandedIssue{}
+	for _, i := range []jira.Issue{
+		issue("20001", "DS2-1", "shield generator offline", "Jerjerrod"),
+		issue("20002", "DS2-1A", "superlaser targeting drifts", "Piett"),
+		{ID: "20003", Key: "DS2-2", Fields: &jira.IssueFields{Summary: "shield generator power", Assignee: &jira.User{Name: "Jerjerrod"}, Resolution: &jira.Resolution{Name: "Fixed"}}},
+	} {
+		issues = append(issues, source.Expand(i))
+	}
+	if issues[1].Issue.GetNumber() != 20002 || issues[0].Issue.GetURL() != "https://jira.empire.gov/rest/api/2/issue/20001" {
+		t.Error("\nUNEXPECTED IDENTIFIERS", "\nEXPECTED: ", 20002, "https://jira.empire.gov/rest/api/2/issue/20001", "\nACTUAL:   ", issues[1].Issue.GetNumber(), issues[0].Issue.GetURL())
+	}
+
+	model := &bhattacharya.NBModel{}
+	model.Learn(issues)
+	if !model.IsBootstrapped() {
+		t.Fatal("\nMODEL NOT TRAINED ON JIRA ISSUES")
+	}
+	if predictions := model.Predict(source.Expand(issue("20004", "DS2-4", "superlaser targeting offline", ""))); len(predictions) == 0 || predictions[0] != "Piett" {
+		t.Error("\nUNEXPECTED PREDICTION", "\nEXPECTED: ", "Piett", "\nACTUAL:   ", predictions)
+	}
+}
+
+func TestCachedSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := utils.Config.DataCachesPath
+	utils.Config.DataCachesPath = dir
+	defer func() { utils.Config.DataCachesPath = path }()
+
+	gh := fakegithub.New()
+	defer gh.Close()
+	gh.AddIssue("rebellion", "x-wing", github.Issue{Title: github.String("R2 unit damaged")})
+
+	cached := CachedSource{Name: "github", Source: &GitHubSource{Gateway: &Gateway{Client: gh.Client()}}, DiskCache: &DiskCache{}}
+	if _, err := cached.OpenIssues("rebellion/x-wing"); err != nil {
+		t.Fatal(err)
+	}
+	offline := CachedSource{Name: "github", DiskCache: &DiskCache{}}
+	issues, err := offline.OpenIssues("rebellion/x-wing")
+	if err != nil || len(issues) != 1 || issues[0].Issue.GetTitle() != "R2 unit damaged" {
+		t.Error("\nISSUES NOT CACHED", "\nEXPECTED: ", "R2 unit damaged", "\nACTUAL:   ", issues, err)
+	}
+	if _, err := offline.ClosedIssues("rebellion/x-wing"); err != ErrNotCached {
+		t.Error("\nUNEXPECTED CACHE MISS ERROR", "\nEXPECTED: ", ErrNotCached, "\nACTUAL:   ", err)
+	}
+}
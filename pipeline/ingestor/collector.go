@@ -54,7 +54,7 @@ var Workload = make(chan interface{}, 100)
 func collectorHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		eventType := r.Header.Get("X-Github-Event")
-		if eventType != "issues" && eventType != "pull_request" && eventType != "installation" && eventType != "installation_repositories" && eventType != "issue_comment" {
+		if eventType != "issues" && eventType != "pull_request" && eventType != "installation" && eventType != "installation_repositories" && eventType != "issue_comment" && eventType != "push" {
 			utils.AppLog.Warn("Ignoring event", zap.String("EventType", eventType))
 			return
 		}
@@ -75,6 +75,8 @@ func collectorHandler() http.Handler {
 			Workload <- *v
 		case *github.IssueCommentEvent:
 			Workload <- *v
+		case *github.PushEvent:
+			Workload <- *v
 		case *github.InstallationEvent:
 			e := &HeuprInstallationEvent{}
 			err := json.Unmarshal(payload, &e)
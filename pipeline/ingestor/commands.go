@@ -0,0 +1,339 @@
+package ingestor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+
+	"core/pipeline/audit"
+	"core/pipeline/command"
+	"core/pipeline/suggestion"
+	"core/utils"
+)
+
+// ProcessCommandEvent runs a "/heupr" command from an issue comment and
+// replies with the outcome.
+func (w *Worker) ProcessCommandEvent(event github.IssueCommentEvent) {
+	c, ok := command.Parse(event.Comment.GetBody())
+	if !ok {
+		return
+	}
+	owner := *event.Repo.Owner.Login
+	repo := *event.Repo.Name
+	repoID := *event.Repo.ID
+	number := *event.Issue.Number
+	sender := *event.Sender.Login
+
+	integration, err := w.Database.ReadIntegrationByRepoID(repoID)
+	if err != nil {
+		utils.AppLog.Error("Failed to process CommandEvent.", zap.Error(err))
+		return
+	}
+	client := NewClient(integration.AppID, integration.InstallationID)
+
+	var reply command.Reply
+	if !c.Known() {
+		reply = command.UsageReply(sender, c, "Unknown command. Available commands:")
+	} else {
+		level, _, err := client.Repositories.GetPermissionLevel(context.Background(), owner, repo, sender)
+		if err != nil {
+			utils.AppLog.Error("Failed to process CommandEvent.", zap.Error(err))
+			return
+		}
+		if !c.Permitted(level.GetPermission()) {
+			reply = command.Reply{User: sender, Command: c, Message: fmt.Sprintf("This command requires %v permission.", command.Required[c.Name])}
+		} else {
+			handler := commandHandler{client: client, database: w.Database, owner: owner, repo: repo, repoID: repoID, issue: event.Issue, integration: *integration, sender: sender}
+			reply = handler.run(sender, c)
+			reply.User, reply.Command = sender, c
+		}
+	}
+
+	body := reply.Render()
+	_, _, err = client.Issues.CreateComment(context.Background(), owner, repo, number, &github.IssueComment{Body: &body})
+	w.Database.InsertActions(commentAction(repoID, number, sender, body, err))
+	if err != nil {
+		utils.AppLog.Error("Failed to process CommandEvent.", zap.Error(err))
+	}
+}
+
+type commandHandler struct {
+	client      *github.Client
+	database    DataAccess
+	owner       string
+	repo        string
+	repoID      int64
+	issue       *github.Issue
+	integration Integration
+	sender      string
+}
+
+func (h commandHandler) run(sender string, c command.Command) command.Reply {
+	switch c.Name {
+	case command.Assign:
+		return h.assign(c)
+	case command.Relabel:
+		return h.relabel(c)
+	case command.Ignore:
+		return h.ignore(c)
+	case command.Explain:
+		return h.explain(c)
+	case command.Settings:
+		return h.settings(c)
+	case command.Accept:
+		return h.accept(sender, c)
+	}
+	return command.UsageReply(sender, c, "Unknown command. Available commands:")
+}
+
+// record audits a mutation made on the issue.
+func (h commandHandler) record(action string, targets []string, scores map[string]float64, err error) {
+	entry := audit.New(h.repoID, *h.issue.Number, action, targets, err)
+	entry.Actor, entry.Scores = h.sender, scores
+	h.database.InsertActions(entry)
+}
+
+func (h commandHandler) failed(err error) command.Reply {
+	utils.AppLog.Error("Failed to process CommandEvent.", zap.Error(err))
+	return command.Reply{Message: fmt.Sprintf("The command failed: %v", err)}
+}
+
+func (h commandHandler) assign(c command.Command) command.Reply {
+	logins := c.Logins()
+	if len(logins) == 0 {
+		return command.Reply{Message: "Usage: `" + command.Usage[command.Assign] + "`"}
+	}
+	issue, _, err := h.client.Issues.AddAssignees(context.Background(), h.owner, h.repo, *h.issue.Number, logins)
+	h.record(audit.AddAssignees, logins, nil, err)
+	if err != nil {
+		return h.failed(err)
+	}
+	assigned := []string{}
+	for _, assignee := range issue.Assignees {
+		assigned = append(assigned, "@"+assignee.GetLogin())
+	}
+	return command.Reply{OK: true, Message: "Assignees: " + strings.Join(assigned, ", ")}
+}
+
+// relabel replaces the issue type labels (bug, feature, improvement) with
+// the given labels; other labels are kept.
+func (h commandHandler) relabel(c command.Command) command.Reply {
+	if len(c.Args) == 0 {
+		return command.Reply{Message: "Usage: `" + command.Usage[command.Relabel] + "`"}
+	}
+	typeLabels, err := h.database.ReadTypeLabels(h.repoID)
+	if err != nil {
+		return h.failed(err)
+	}
+	requested := make(map[string]bool)
+	for _, label := range c.Args {
+		requested[label] = true
+	}
+	details := []string{}
+	for _, label := range h.issue.Labels {
+		name := label.GetName()
+		if requested[name] {
+			continue
+		}
+		for _, typeLabel := range typeLabels {
+			if name != typeLabel {
+				continue
+			}
+			_, err := h.client.Issues.RemoveLabelForIssue(context.Background(), h.owner, h.repo, *h.issue.Number, name)
+			h.record(audit.RemoveLabel, []string{name}, nil, err)
+			if err != nil {
+				return h.failed(err)
+			}
+			details = append(details, "Removed `"+name+"`")
+		}
+	}
+	_, _, err = h.client.Issues.AddLabelsToIssue(context.Background(), h.owner, h.repo, *h.issue.Number, c.Args)
+	h.record(audit.AddLabels, c.Args, nil, err)
+	if err != nil {
+		return h.failed(err)
+	}
+	for _, label := range c.Args {
+		details = append(details, "Added `"+label+"`")
+	}
+	return command.Reply{OK: true, Message: "Labels updated.", Details: details}
+}
+
+// ignore stops Heupr from triaging or labeling the issue.
+func (h commandHandler) ignore(c command.Command) command.Reply {
+	_, _, err := h.client.Issues.AddLabelsToIssue(context.Background(), h.owner, h.repo, *h.issue.Number, []string{command.IgnoreLabel})
+	h.record(audit.AddLabels, []string{command.IgnoreLabel}, nil, err)
+	if err != nil {
+		return h.failed(err)
+	}
+	return command.Reply{OK: true, Message: fmt.Sprintf("Heupr will ignore this issue. Remove the `%v` label to undo.", command.IgnoreLabel)}
+}
+
+// explain reports the recommendation recorded for the issue.
+func (h commandHandler) explain(c command.Command) command.Reply {
+	_, s, found, err := suggestion.Find(h.client, h.owner, h.repo, *h.issue.Number)
+	if err != nil {
+		return h.failed(err)
+	}
+	if !found {
+		return command.Reply{OK: true, Message: "No recommendation has been recorded for this issue."}
+	}
+	details := []string{}
+	for _, candidate := range s.Candidates {
+		detail := fmt.Sprintf("@%v: %.0f%% confidence", candidate.Login, candidate.Confidence*100)
+		if len(candidate.Because) > 0 {
+			detail += ", because of " + strings.Join(candidate.Because, ", ")
+		}
+		details = append(details, detail)
+	}
+	if s.Label != "" {
+		details = append(details, "Label `"+s.Label+"` predicted by the issue type model")
+	}
+	return command.Reply{OK: true, Message: "Candidates ranked by the assignment model:", Details: details}
+}
+
+func (h commandHandler) accept(sender string, c command.Command) command.Reply {
+	comment, s, found, err := suggestion.Find(h.client, h.owner, h.repo, *h.issue.Number)
+	if err != nil {
+		return h.failed(err)
+	}
+	if !found {
+		return command.Reply{Message: "There is no suggestion on this issue."}
+	}
+	if s.AcceptedBy != "" {
+		return command.Reply{Message: fmt.Sprintf("@%v already accepted the suggestion.", s.AcceptedBy)}
+	}
+	login := ""
+	if logins := c.Logins(); len(logins) > 0 {
+		login = logins[0]
+	}
+	assignee, ok := s.Choose(login)
+	if !ok {
+		return command.Reply{Message: fmt.Sprintf("@%v is not one of the suggested assignees.", login)}
+	}
+	err = suggestion.Accept(h.client, h.owner, h.repo, *h.issue.Number, comment, s, sender, assignee)
+	h.record(audit.AddAssignees, []string{assignee}, s.Confidences(), err)
+	if s.Label != "" {
+		h.record(audit.AddLabels, []string{s.Label}, nil, err)
+	}
+	if err != nil {
+		return h.failed(err)
+	}
+	return command.Reply{OK: true, Message: fmt.Sprintf("Assigned @%v.", assignee)}
+}
+
+// settings merges the given key=value pairs into the current repo settings;
+// unspecified settings keep their values.
+func (h commandHandler) settings(c command.Command) command.Reply {
+	pairs, err := c.Pairs()
+	if err == nil && len(pairs) == 0 {
+		err = fmt.Errorf("no settings given")
+	}
+	if err != nil {
+		return command.Reply{Message: err.Error() + ". Usage: `" + command.Usage[command.Settings] + "`"}
+	}
+	current, err := h.database.ReadRepositoryIntegrationSettings(h.repoID)
+	if err != nil {
+		return h.failed(err)
+	}
+	if current.ConfigFile {
+		return command.Reply{Message: fmt.Sprintf("Settings are managed by `%v`; edit that file instead.", RepoConfigPath)}
+	}
+	settings, err := parseSettings(current, pairs)
+	if err != nil {
+		return command.Reply{Message: err.Error() + ". Usage: `" + command.Usage[command.Settings] + "`"}
+	}
+	settings.Integration = h.integration
+	h.database.InsertRepositoryIntegrationSettings(settings)
+	//Workaround: This causes the backend to kick in and pull in the latest settings.
+	action := "opened"
+	h.database.InsertIssue(*h.issue, &action)
+
+	details := []string{
+		fmt.Sprintf("triager: %v", settings.EnableTriager),
+		fmt.Sprintf("labeler: %v", settings.EnableLabeler),
+		fmt.Sprintf("ignore-users: %v", settings.IgnoreUsers),
+		fmt.Sprintf("ignore-labels: %v", settings.IgnoreLabels),
+		fmt.Sprintf("start-time: %v", settings.StartTime.Format(time.RFC822)),
+		fmt.Sprintf("policy: %v", settings.AssignmentPolicy),
+		fmt.Sprintf("shadow: %v", settings.Shadow),
+	}
+	return command.Reply{OK: true, Message: "Settings applied.", Details: details}
+}
+
+// parseSettings applies "/heupr settings" pairs on top of the current repo
+// settings. The current settings are not modified. Like the config file,
+// top-k and the window can only be set for the policies that use them; they
+// can always be cleared with 0.
+func parseSettings(current HeuprConfigSettings, pairs map[string]string) (HeuprConfigSettings, error) {
+	settings := current
+	list := func(value string) []string {
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimPrefix(strings.TrimSpace(item), "@"); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	for key, value := range pairs {
+		var err error
+		switch key {
+		case "triager":
+			settings.EnableTriager, err = parseToggle(value)
+		case "labeler":
+			settings.EnableLabeler, err = parseToggle(value)
+		case "ignore-users":
+			settings.IgnoreUsers = list(value)
+		case "ignore-labels":
+			settings.IgnoreLabels = list(value)
+		case "start-time":
+			settings.StartTime, err = time.Parse("2006-01-02", value)
+		case "email":
+			settings.Email = value
+		case "twitter":
+			settings.Twitter = value
+		case "codeowners":
+			settings.CodeOwners = value
+		case "policy":
+			settings.AssignmentPolicy = value
+		case "top-k":
+			settings.TopK, err = strconv.Atoi(value)
+		case "window-days":
+			settings.WindowDays, err = strconv.Atoi(value)
+		case "window-limit":
+			settings.WindowLimit, err = strconv.Atoi(value)
+		case "shadow":
+			settings.Shadow, err = parseToggle(value)
+		default:
+			err = fmt.Errorf("unknown setting %q", key)
+		}
+		if err != nil {
+			return settings, fmt.Errorf("invalid %v: %v", key, err)
+		}
+	}
+	policy := settings.AssignmentPolicy
+	if _, ok := pairs["top-k"]; ok && settings.TopK > 0 && !topKPolicies[policy] {
+		return settings, fmt.Errorf("invalid top-k: only used by the round-robin, least-loaded and suggest-only policies")
+	}
+	for _, key := range []string{"window-days", "window-limit"} {
+		if _, ok := pairs[key]; ok && (settings.WindowDays > 0 || settings.WindowLimit > 0) && !workloadPolicies[policy] {
+			return settings, fmt.Errorf("invalid %v: only used by the round-robin and least-loaded policies", key)
+		}
+	}
+	return settings, nil
+}
+
+func parseToggle(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes":
+		return true, nil
+	case "off", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected on or off, got %q", value)
+}
@@ -0,0 +1,103 @@
+package ingestor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSettings(t *testing.T) {
+	current := HeuprConfigSettings{EnableTriager: true, EnableLabeler: true}
+	settings, err := parseSettings(current, map[string]string{
+		"triager":      "off",
+		"ignore-users": "@han, chewie",
+		"start-time":   "1977-05-25",
+		"policy":       "suggest-only",
+		"top-k":        "2",
+		"shadow":       "on",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := HeuprConfigSettings{
+		EnableTriager:    false,
+		EnableLabeler:    true,
+		IgnoreUsers:      []string{"han", "chewie"},
+		StartTime:        time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC),
+		AssignmentPolicy: "suggest-only",
+		TopK:             2,
+		Shadow:           true,
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Error(
+			"\nSETTINGS NOT PARSED",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", settings,
+		)
+	}
+	for _, pairs := range []map[string]string{
+		{"triager": "maybe"},
+		{"top-k": "many"},
+		{"deathstar": "on"},
+		{"top-k": "2"},
+		{"policy": "greedy", "window-limit": "5"},
+		{"policy": "suggest-only", "window-days": "7"},
+	} {
+		if _, err := parseSettings(current, pairs); err == nil {
+			t.Error("\nINVALID SETTINGS ACCEPTED", pairs)
+		}
+	}
+}
+
+func TestParseSettingsMerge(t *testing.T) {
+	start := time.Date(1980, 5, 21, 0, 0, 0, 0, time.UTC)
+	current := HeuprConfigSettings{
+		EnableTriager:    true,
+		IgnoreUsers:      []string{"jabba"},
+		StartTime:        start,
+		CodeOwners:       "filter",
+		AssignmentPolicy: "least-loaded",
+		WindowLimit:      3,
+		CapacityWeights:  map[string]float64{"lando": 0.5},
+	}
+	settings, err := parseSettings(current, map[string]string{"labeler": "on"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := current
+	expected.EnableLabeler = true
+	if !reflect.DeepEqual(settings, expected) {
+		t.Error(
+			"\nSETTINGS NOT MERGED",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", settings,
+		)
+	}
+	if current.EnableLabeler {
+		t.Error("\nCURRENT SETTINGS MODIFIED")
+	}
+}
+
+func TestConfirmation(t *testing.T) {
+	cases := []struct {
+		body   string
+		answer bool
+		ok     bool
+	}{
+		{"Yes please", true, true},
+		{"no.", false, true},
+		{"I know the settings look fine", false, false},
+		{"", false, false},
+	}
+	for _, c := range cases {
+		answer, ok := confirmation(c.body)
+		if answer != c.answer || ok != c.ok {
+			t.Error(
+				"\nCONFIRMATION NOT PARSED",
+				"\nBODY:     ", c.body,
+				"\nEXPECTED: ", c.answer, c.ok,
+				"\nACTUAL:   ", answer, ok,
+			)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/google/go-github/github"
+
+	"core/pipeline/audit"
 )
 
 var tests = []struct {
@@ -43,6 +45,14 @@ func (r *continuityDA) InsertGobLabelSettings(settings storage) error {
 
 func (c *continuityDA) ReadIntegrations() ([]Integration, error) { return nil, nil }
 
+func (c *continuityDA) ReadTypeLabels(repoID int64) ([]string, error) {
+	return nil, nil
+}
+
+func (c *continuityDA) ReadRepositoryIntegrationSettings(repoID int64) (HeuprConfigSettings, error) {
+	return HeuprConfigSettings{}, nil
+}
+
 func (c *continuityDA) ReadIntegrationByRepoID(id int64) (*Integration, error) {
 	return &Integration{1, 1, 1}, nil
 }
@@ -51,6 +61,9 @@ func (c *continuityDA) InsertIssue(i github.Issue, action *string) {}
 
 func (c *continuityDA) InsertPullRequest(p github.PullRequest, action *string) {}
 
+func (c *continuityDA) InsertIssueFeedback(event github.IssuesEvent) {}
+func (c *continuityDA) InsertActions(actions ...audit.Action)        {}
+
 func (c *continuityDA) BulkInsertIssuesPullRequests(i []*github.Issue, p []*github.PullRequest) {}
 
 func (c *continuityDA) InsertRepositoryIntegration(repoID int64, appID int, installID int64) {}
@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
 
+	"core/pipeline/audit"
 	"core/utils"
 )
 
@@ -50,8 +51,12 @@ type DataAccess interface {
 	restartCheck(query string, repoID int64) (int, int, error)
 	ReadIntegrations() ([]Integration, error)
 	ReadIntegrationByRepoID(repoID int64) (*Integration, error)
+	ReadTypeLabels(repoID int64) ([]string, error)
+	ReadRepositoryIntegrationSettings(repoID int64) (HeuprConfigSettings, error)
 	InsertIssue(issue github.Issue, action *string)
 	InsertPullRequest(pull github.PullRequest, action *string)
+	InsertIssueFeedback(event github.IssuesEvent)
+	InsertActions(actions ...audit.Action)
 	BulkInsertIssuesPullRequests(issues []*github.Issue, pulls []*github.PullRequest)
 	InsertRepositoryIntegration(repoID int64, appID int, installationID int64)
 	InsertRepositoryIntegrationSettings(settings HeuprConfigSettings)
@@ -237,12 +242,12 @@ func (d *Database) InsertGobLabelSettings(settings storage) error {
 func (d *Database) InsertRepositoryIntegrationSettings(settings HeuprConfigSettings) {
 	var settingsID int64
 	var buffer bytes.Buffer
-	settingsInsert := "INSERT INTO integrations_settings(repo_id, start_time, email, twitter, enable_triager, enable_labeler) VALUES"
-	valuesFmt := "(?,?,?,?,?,?)"
+	settingsInsert := "INSERT INTO integrations_settings(repo_id, start_time, email, twitter, enable_triager, enable_labeler, codeowners_mode, top_k, window_days, window_limit, assignment_policy, shadow_mode, config_file) VALUES"
+	valuesFmt := "(?,?,?,?,?,?,?,?,?,?,?,?,?)"
 
 	buffer.WriteString(settingsInsert)
 	buffer.WriteString(valuesFmt)
-	result, err := d.db.Exec(buffer.String(), settings.Integration.RepoID, settings.StartTime, settings.Email, settings.Twitter, settings.EnableTriager, settings.EnableLabeler)
+	result, err := d.db.Exec(buffer.String(), settings.Integration.RepoID, settings.StartTime, settings.Email, settings.Twitter, settings.EnableTriager, settings.EnableLabeler, settings.CodeOwners, settings.TopK, settings.WindowDays, settings.WindowLimit, settings.AssignmentPolicy, settings.Shadow, settings.ConfigFile)
 	if err != nil {
 		utils.AppLog.Error("Database Insert Failure", zap.Error(err))
 		return
@@ -277,6 +282,28 @@ func (d *Database) InsertRepositoryIntegrationSettings(settings HeuprConfigSetti
 	}
 	buffer.Reset()
 
+	if len(settings.CapacityWeights) > 0 {
+		settingsCapacityLookupInsert := "INSERT INTO integrations_settings_capacity_lk(integrations_settings_fk, user, weight) VALUES"
+		settingsCapacityLookupValuesFmt := "(?,?,?)"
+		buffer.WriteString(settingsCapacityLookupInsert)
+		values := []interface{}{}
+		delimeter := ""
+		for user, weight := range settings.CapacityWeights {
+			buffer.WriteString(delimeter)
+			buffer.WriteString(settingsCapacityLookupValuesFmt)
+			values = append(values, settingsID, user, weight)
+			delimeter = ","
+		}
+		result, err = d.db.Exec(buffer.String(), values...)
+		if err != nil {
+			utils.AppLog.Error("Database Insert Failure", zap.Error(err))
+		} else {
+			rows, _ := result.RowsAffected()
+			utils.AppLog.Info("Database Insert Success", zap.Int64("Rows", rows))
+		}
+	}
+	buffer.Reset()
+
 	if settings.IgnoreLabels != nil && len(settings.IgnoreLabels) > 0 {
 		settingsIgnoreLabelsLookupInsert := "INSERT INTO integrations_settings_ignorelabels_lk(integrations_settings_fk, label) VALUES"
 		settingsIgnoreLabelsValuesFmt := "(?,?)"
@@ -373,6 +400,89 @@ func (d *Database) ReadIntegrationByRepoID(repoID int64) (*Integration, error) {
 	return integration, nil
 }
 
+// ReadRepositoryIntegrationSettings returns the most recent settings row for
+// the repo along with its ignore and capacity lookups.
+func (d *Database) ReadRepositoryIntegrationSettings(repoID int64) (HeuprConfigSettings, error) {
+	settings := HeuprConfigSettings{}
+	var settingsID int64
+	var codeOwners, policy sql.NullString
+	var topK, windowDays, windowLimit sql.NullInt64
+	err := d.db.QueryRow("SELECT id, repo_id, start_time, email, twitter, enable_triager, enable_labeler, codeowners_mode, top_k, window_days, window_limit, assignment_policy, shadow_mode, config_file FROM integrations_settings WHERE repo_id = ? ORDER BY id DESC LIMIT 1", repoID).Scan(&settingsID, &settings.Integration.RepoID, &settings.StartTime, &settings.Email, &settings.Twitter, &settings.EnableTriager, &settings.EnableLabeler, &codeOwners, &topK, &windowDays, &windowLimit, &policy, &settings.Shadow, &settings.ConfigFile)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			utils.AppLog.Error("database read failure - ReadRepositoryIntegrationSettings()", zap.Error(err))
+		}
+		return settings, err
+	}
+	settings.CodeOwners, settings.AssignmentPolicy = codeOwners.String, policy.String
+	settings.TopK, settings.WindowDays, settings.WindowLimit = int(topK.Int64), int(windowDays.Int64), int(windowLimit.Int64)
+
+	lookup := func(query string, scan func(*sql.Rows) error) error {
+		rows, err := d.db.Query(query, settingsID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+	err = lookup("SELECT user FROM integrations_settings_ignoreusers_lk WHERE integrations_settings_fk = ?", func(rows *sql.Rows) error {
+		var user string
+		err := rows.Scan(&user)
+		settings.IgnoreUsers = append(settings.IgnoreUsers, user)
+		return err
+	})
+	if err == nil {
+		err = lookup("SELECT label FROM integrations_settings_ignorelabels_lk WHERE integrations_settings_fk = ?", func(rows *sql.Rows) error {
+			var label string
+			err := rows.Scan(&label)
+			settings.IgnoreLabels = append(settings.IgnoreLabels, label)
+			return err
+		})
+	}
+	if err == nil {
+		err = lookup("SELECT user, weight FROM integrations_settings_capacity_lk WHERE integrations_settings_fk = ?", func(rows *sql.Rows) error {
+			var user string
+			var weight float64
+			err := rows.Scan(&user, &weight)
+			if settings.CapacityWeights == nil {
+				settings.CapacityWeights = make(map[string]float64)
+			}
+			settings.CapacityWeights[user] = weight
+			return err
+		})
+	}
+	if err != nil {
+		utils.AppLog.Error("database read failure - ReadRepositoryIntegrationSettings()", zap.Error(err))
+	}
+	return settings, err
+}
+
+// ReadTypeLabels returns the bug, feature and improvement labels most
+// recently configured for the repo.
+func (d *Database) ReadTypeLabels(repoID int64) ([]string, error) {
+	var bug, feature, improvement sql.NullString
+	err := d.db.QueryRow("SELECT bug, feature, improvement FROM integrations_settings_labels_bif_lk WHERE repo_id = ? ORDER BY id DESC LIMIT 1", repoID).Scan(&bug, &feature, &improvement)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			utils.AppLog.Error("database read failure - ReadTypeLabels()", zap.Error(err))
+			return nil, err
+		}
+		return nil, nil
+	}
+	labels := []string{}
+	for _, label := range []sql.NullString{bug, feature, improvement} {
+		if label.Valid && label.String != "" {
+			labels = append(labels, label.String)
+		}
+	}
+	return labels, nil
+}
+
 func (d *Database) BulkInsertBacktestEvents(events []*Event) {
 	buffer := d.BufferPool.Get()
 	for i := 0; i < len(events); i++ {
@@ -716,6 +826,31 @@ func (d *Database) BulkInsertIssues(issues []*github.Issue) {
 	}
 }
 
+func (d *Database) InsertIssueFeedback(event github.IssuesEvent) {
+	var assignee, label *string
+	if event.Assignee != nil {
+		assignee = event.Assignee.Login
+	}
+	if event.Label != nil {
+		label = event.Label.Name
+	}
+	payload, _ := json.Marshal(event.Issue)
+	result, err := d.db.Exec("INSERT INTO issue_feedback(repo_id, number, action, actor, assignee, label, payload) VALUES(?,?,?,?,?,?,?)", *event.Repo.ID, event.Issue.Number, event.Action, event.Sender.Login, assignee, label, stripCtlAndExtFromBytes(payload))
+	if err != nil {
+		utils.AppLog.Error("Database Insert Failure", zap.Error(err))
+	} else {
+		rows, _ := result.RowsAffected()
+		utils.AppLog.Debug("Database Insert Success", zap.Int64("Rows", rows))
+	}
+}
+
+// InsertActions audits GitHub mutations made by Heupr.
+func (d *Database) InsertActions(actions ...audit.Action) {
+	if err := audit.Insert(d.db, actions...); err != nil {
+		utils.AppLog.Error("Database Insert Failure", zap.Error(err))
+	}
+}
+
 func (d *Database) InsertPullRequest(pull github.PullRequest, action *string) {
 	if pull.Merged != nil && *pull.Merged == true {
 		d.LogMergedPullRequestAssignees(pull)
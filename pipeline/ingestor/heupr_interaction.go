@@ -3,13 +3,14 @@ package ingestor
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
 
+	"core/pipeline/audit"
+	"core/pipeline/command"
 	"core/utils"
 )
 
@@ -22,121 +23,83 @@ type HeuprConfigSettings struct {
 	IgnoreLabels  []string
 	Email         string
 	Twitter       string
+	CodeOwners    string
+	// TopK, WindowDays, WindowLimit and CapacityWeights configure the backend
+	// workload balancing.
+	TopK            int
+	WindowDays      int
+	WindowLimit     int
+	CapacityWeights map[string]float64
+	// AssignmentPolicy is one of greedy, round-robin, least-loaded or
+	// suggest-only.
+	AssignmentPolicy string
+	// Shadow records the actions the backend would take instead of applying
+	// them on GitHub.
+	Shadow bool
+	// ConfigFile is set while the settings come from .github/heupr.yml; the
+	// file then takes precedence over "/heupr settings" and the frontend.
+	ConfigFile bool
 }
 
-func extractSettings(issue github.Issue) (ignoreUsers []string, startTime time.Time, ignoreLabels []string, email string, twitter string, err error) {
-	r := strings.Replace("IgnoreUsers=\\{(.*?)\\}", "{", `"`, 1)
-	r = strings.Replace(r, "}", `"`, 1)
-	ignoreUsersRegex := regexp.MustCompile(r)
-	ignoreUsersMatch := ignoreUsersRegex.FindAllSubmatch([]byte(*issue.Body), -1)
-	if len(ignoreUsersMatch) > 0 {
-		ignoreUsers = strings.Split(string(ignoreUsersMatch[len(ignoreUsersMatch)-1][1]), ",")
-	}
-
-	r = strings.Replace("TriageStartTime=\\{(.*?)\\}", "{", `"`, 1)
-	r = strings.Replace(r, "}", `"`, 1)
-	startTimeRegex := regexp.MustCompile(r)
-	startTimeMatch := startTimeRegex.FindAllSubmatch([]byte(*issue.Body), -1)
-	if len(startTimeMatch) > 0 {
-		startTime, err = time.Parse(time.RFC822, string(startTimeMatch[len(startTimeMatch)-1][1]))
-	}
+// defaultSettings are the settings of a newly installed repo.
+func defaultSettings(integration Integration) HeuprConfigSettings {
+	return HeuprConfigSettings{Integration: integration, EnableTriager: false, EnableLabeler: true, StartTime: time.Now()}
+}
 
-	r = strings.Replace("IgnoreLabels=\\{(.*?)\\}", "{", `"`, 1)
-	r = strings.Replace(r, "}", `"`, 1)
-	ignoreLabelsRegex := regexp.MustCompile(r)
-	ignoreLabelsMatch := ignoreLabelsRegex.FindAllSubmatch([]byte(*issue.Body), -1)
-	if len(ignoreLabelsMatch) > 0 {
-		ignoreLabels = strings.Split(string(ignoreLabelsMatch[len(ignoreLabelsMatch)-1][1]), ",")
-	}
+// commentAction audits a comment posted by Heupr in reply to the actor.
+func commentAction(repoID int64, number int, actor, body string, err error) audit.Action {
+	action := audit.New(repoID, number, audit.CreateComment, nil, err)
+	action.Actor, action.Body = actor, body
+	return action
+}
 
-	r = strings.Replace("Email=\\{(.*?)\\}", "{", `"`, 1)
-	r = strings.Replace(r, "}", `"`, 1)
-	emailRegex := regexp.MustCompile(r)
-	emailMatch := emailRegex.FindAllSubmatch([]byte(*issue.Body), -1)
-	if len(emailMatch) > 0 {
-		email = string(emailMatch[len(emailMatch)-1][1])
+// confirmation reads a yes/no answer from the first word of a comment so
+// that words merely containing "no" (e.g. "know") are not refusals.
+func confirmation(body string) (bool, bool) {
+	fields := strings.Fields(body)
+	if len(fields) == 0 {
+		return false, false
 	}
-
-	r = strings.Replace("Twitter=\\{(.*?)\\}", "{", `"`, 1)
-	r = strings.Replace(r, "}", `"`, 1)
-	twitterRegex := regexp.MustCompile(r)
-	twitterMatch := twitterRegex.FindAllSubmatch([]byte(*issue.Body), -1)
-	if len(twitterMatch) > 0 {
-		twitter = string(twitterMatch[len(twitterMatch)-1][1])
+	switch strings.ToLower(strings.TrimRight(fields[0], ".,!")) {
+	case "yes":
+		return true, true
+	case "no":
+		return false, true
 	}
-
-	return ignoreUsers, startTime, ignoreLabels, email, twitter, err
+	return false, false
 }
 
+// ProcessHeuprInteractionCommentEvent answers a yes/no reply to the
+// onboarding issue with where the settings moved.
+//
+// Deprecated: settings are changed with "/heupr settings" or the config file.
 func (w *Worker) ProcessHeuprInteractionCommentEvent(event github.IssueCommentEvent) {
-	owner := *event.Repo.Owner.Login
-	repo := *event.Repo.Name
-	repoID := *event.Repo.ID
-	number := *event.Issue.Number
-	integration, err := w.Database.ReadIntegrationByRepoID(repoID)
-	if err != nil {
-		utils.AppLog.Error("Failed to process HeuprInteractionEvent.", zap.Error(err))
-	}
-	client := NewClient(integration.AppID, integration.InstallationID)
-
-	if strings.Contains(*event.Comment.Body, "no") || strings.Contains(*event.Comment.Body, "No") {
-		body := fmt.Sprintf(HoldOnMessage, *event.Sender.Login)
-		comment := &github.IssueComment{Body: &body}
-		_, _, err = client.Issues.CreateComment(context.Background(), owner, repo, number, comment)
-		if err != nil {
-			utils.AppLog.Error("Failed to process HeuprInteractionEvent.", zap.Error(err))
-		}
+	if _, ok := confirmation(*event.Comment.Body); !ok {
 		return
 	}
-
-	if !strings.Contains(*event.Comment.Body, "yes") && !strings.Contains(*event.Comment.Body, "Yes") {
-		return
-	}
-
-	//Duplicate Logic. It gets the job done for validation & settings extraction)
-	ignoreUsers, startTime, ignoreLabels, email, twitter, err := extractSettings(*event.Issue)
-	var body string
-	if err != nil {
-		body = fmt.Sprintf(ConfirmationErrMessage, *event.Sender.Login, err, ignoreUsers, startTime, ignoreLabels, email, twitter)
-	} else {
-		body = fmt.Sprintf(AppliedSettingsMessage, *event.Sender.Login, ignoreUsers, startTime, ignoreLabels, email, twitter)
-	}
-	comment := &github.IssueComment{Body: &body}
-	_, _, err = client.Issues.CreateComment(context.Background(), owner, repo, number, comment)
-	if err != nil {
-		utils.AppLog.Error("Failed to process HeuprInteractionEvent.", zap.Error(err))
-	}
-
-	//Temp (Yarn is the only one using this logic but they won't have these two new fields- but safe to set to true)
-	//Not a big deal as this method will be gone soon.
-	settings := HeuprConfigSettings{EnableTriager: true, EnableLabeler: true, Integration: *integration, IgnoreUsers: ignoreUsers, StartTime: startTime, IgnoreLabels: ignoreLabels, Email: email, Twitter: twitter}
-	w.Database.InsertRepositoryIntegrationSettings(settings)
-	//Workaround: This causes the backend to kick in and pull in the latest settings.
-	action := "opened"
-	w.Database.InsertIssue(*event.Issue, &action)
+	w.replySettingsMoved(*event.Repo.Owner.Login, *event.Repo.Name, *event.Repo.ID, *event.Issue.Number, *event.Sender.Login)
 }
 
+// ProcessHeuprInteractionIssuesEvent answers an edit of the onboarding issue
+// with where the settings moved.
+//
+// Deprecated: settings are changed with "/heupr settings" or the config file.
 func (w *Worker) ProcessHeuprInteractionIssuesEvent(event github.IssuesEvent) {
-	//TODO: Add user validation
-	owner := *event.Issue.Repository.Owner.Login
-	repo := *event.Issue.Repository.Name
-	repoID := *event.Issue.Repository.ID
-	number := *event.Issue.Number
+	repository := event.Issue.Repository
+	w.replySettingsMoved(*repository.Owner.Login, *repository.Name, *repository.ID, *event.Issue.Number, *event.Sender.Login)
+}
+
+func (w *Worker) replySettingsMoved(owner, repo string, repoID int64, number int, sender string) {
 	integration, err := w.Database.ReadIntegrationByRepoID(repoID)
 	if err != nil {
 		utils.AppLog.Error("Failed to process HeuprInteractionEvent.", zap.Error(err))
+		return
 	}
 	client := NewClient(integration.AppID, integration.InstallationID)
 
-	ignoreUsers, startTime, ignoreLabels, email, twitter, err := extractSettings(*event.Issue)
-	var body string
-	if err == nil {
-		body = fmt.Sprintf(ConfirmationMessage, *event.Sender.Login, ignoreUsers, startTime, ignoreLabels, email, twitter)
-	} else {
-		body = fmt.Sprintf(ConfirmationErrMessage, *event.Sender.Login, err, ignoreUsers, startTime, ignoreLabels, email, twitter)
-	}
-	comment := &github.IssueComment{Body: &body}
-	_, _, err = client.Issues.CreateComment(context.Background(), owner, repo, number, comment)
+	body := fmt.Sprintf(SettingsMovedMessage, sender, command.Usage[command.Settings], RepoConfigPath)
+	_, _, err = client.Issues.CreateComment(context.Background(), owner, repo, number, &github.IssueComment{Body: &body})
+	w.Database.InsertActions(commentAction(repoID, number, sender, body, err))
 	if err != nil {
 		utils.AppLog.Error("Failed to process HeuprInteractionEvent.", zap.Error(err))
 	}
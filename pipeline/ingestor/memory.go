@@ -0,0 +1,234 @@
+package ingestor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/audit"
+)
+
+// StoredEvent is a row of the github_events table.
+type StoredEvent struct {
+	ID       int
+	RepoID   int64
+	IssuesID int64
+	Number   int
+	Action   string
+	IsPull   bool
+	ClosedAt *time.Time
+	Payload  []byte
+}
+
+// MemoryDatabase is an embedded DataAccess that keeps every table in memory.
+// It lets the ingestor run without MySQL, e.g. in the replay simulator and in
+// CI. It is safe for concurrent use.
+type MemoryDatabase struct {
+	mu           sync.Mutex
+	events       []StoredEvent
+	feedback     []github.IssuesEvent
+	actions      []audit.Action
+	integrations map[int64]Integration
+	settings     map[int64]HeuprConfigSettings
+	labels       map[int64][]string
+}
+
+func NewMemoryDatabase() *MemoryDatabase {
+	return &MemoryDatabase{
+		integrations: make(map[int64]Integration),
+		settings:     make(map[int64]HeuprConfigSettings),
+		labels:       make(map[int64][]string),
+	}
+}
+
+func (m *MemoryDatabase) open() {}
+
+func (m *MemoryDatabase) Close() {}
+
+func (m *MemoryDatabase) continuityCheck(query string) ([][]interface{}, error) {
+	return nil, nil
+}
+
+func (m *MemoryDatabase) restartCheck(query string, repoID int64) (int, int, error) {
+	return 0, 0, nil
+}
+
+func (m *MemoryDatabase) ReadIntegrations() ([]Integration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	integrations := []Integration{}
+	for _, integration := range m.integrations {
+		integrations = append(integrations, integration)
+	}
+	sort.Slice(integrations, func(i, j int) bool { return integrations[i].RepoID < integrations[j].RepoID })
+	return integrations, nil
+}
+
+func (m *MemoryDatabase) ReadIntegrationByRepoID(repoID int64) (*Integration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	integration, ok := m.integrations[repoID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &integration, nil
+}
+
+func (m *MemoryDatabase) ReadTypeLabels(repoID int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.labels[repoID], nil
+}
+
+func (m *MemoryDatabase) ReadRepositoryIntegrationSettings(repoID int64) (HeuprConfigSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	settings, ok := m.settings[repoID]
+	if !ok {
+		return settings, sql.ErrNoRows
+	}
+	return settings, nil
+}
+
+func (m *MemoryDatabase) InsertIssue(issue github.Issue, action *string) {
+	if issue.Repository == nil || issue.Repository.ID == nil {
+		return
+	}
+	payload, _ := json.Marshal(issue)
+	m.insert(StoredEvent{
+		RepoID:   *issue.Repository.ID,
+		IssuesID: issue.GetID(),
+		Number:   issue.GetNumber(),
+		Action:   stringValue(action),
+		ClosedAt: issue.ClosedAt,
+		Payload:  payload,
+	})
+}
+
+func (m *MemoryDatabase) InsertPullRequest(pull github.PullRequest, action *string) {
+	if pull.Base == nil || pull.Base.Repo == nil || pull.Base.Repo.ID == nil {
+		return
+	}
+	payload, _ := json.Marshal(pull)
+	m.insert(StoredEvent{
+		RepoID:   *pull.Base.Repo.ID,
+		IssuesID: pull.GetID(),
+		Number:   pull.GetNumber(),
+		Action:   stringValue(action),
+		IsPull:   true,
+		ClosedAt: pull.ClosedAt,
+		Payload:  payload,
+	})
+}
+
+func (m *MemoryDatabase) insert(event StoredEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.ID = len(m.events) + 1
+	m.events = append(m.events, event)
+}
+
+func (m *MemoryDatabase) InsertIssueFeedback(event github.IssuesEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feedback = append(m.feedback, event)
+}
+
+func (m *MemoryDatabase) InsertActions(actions ...audit.Action) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.actions = append(m.actions, actions...)
+}
+
+func (m *MemoryDatabase) BulkInsertIssuesPullRequests(issues []*github.Issue, pulls []*github.PullRequest) {
+	closed := "closed"
+	for _, issue := range issues {
+		m.InsertIssue(*issue, &closed)
+	}
+	for _, pull := range pulls {
+		m.InsertPullRequest(*pull, &closed)
+	}
+}
+
+func (m *MemoryDatabase) InsertRepositoryIntegration(repoID int64, appID int, installationID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.integrations[repoID] = Integration{RepoID: repoID, AppID: appID, InstallationID: int(installationID)}
+}
+
+func (m *MemoryDatabase) InsertRepositoryIntegrationSettings(settings HeuprConfigSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings[settings.Integration.RepoID] = settings
+}
+
+func (m *MemoryDatabase) InsertGobLabelSettings(settings storage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	labels := []string{}
+	for _, bucket := range []string{"typebug", "typefeature", "typeimprovement"} {
+		for _, l := range settings.Buckets[bucket] {
+			if l.Selected {
+				labels = append(labels, l.Name)
+			}
+		}
+	}
+	m.labels[settings.RepoID] = labels
+	return nil
+}
+
+func (m *MemoryDatabase) DeleteRepositoryIntegration(repoID int64, appID int, installationID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.integrations, repoID)
+}
+
+func (m *MemoryDatabase) ObliterateIntegration(appID int, installationID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for repoID, integration := range m.integrations {
+		if integration.AppID == appID && int64(integration.InstallationID) == installationID {
+			delete(m.integrations, repoID)
+			delete(m.settings, repoID)
+		}
+	}
+}
+
+// Events returns the stored github_events rows with an ID above the given
+// one, in insertion order.
+func (m *MemoryDatabase) Events(after int) []StoredEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if after < 0 {
+		after = 0
+	}
+	if after >= len(m.events) {
+		return []StoredEvent{}
+	}
+	return append([]StoredEvent{}, m.events[after:]...)
+}
+
+// Feedback returns the stored human assignee and label changes.
+func (m *MemoryDatabase) Feedback() []github.IssuesEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]github.IssuesEvent{}, m.feedback...)
+}
+
+// Actions returns the audited GitHub mutations.
+func (m *MemoryDatabase) Actions() []audit.Action {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]audit.Action{}, m.actions...)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,287 @@
+package ingestor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"core/pipeline/gateway"
+	"core/utils"
+)
+
+// RepoConfigPath is read from the default branch of each repo.
+const RepoConfigPath = ".github/heupr.yml"
+
+// RepoConfigVersion is the only supported config file version.
+const RepoConfigVersion = 1
+
+// RepoConfigCheck names the check run reporting config file errors.
+const RepoConfigCheck = "heupr/config"
+
+// RepoConfig is the schema of .github/heupr.yml. Unknown keys are rejected.
+type RepoConfig struct {
+	Version int `yaml:"version"`
+	// Shadow records what Heupr would do without touching the repo.
+	Shadow  bool `yaml:"shadow"`
+	Triager struct {
+		Enabled      *bool    `yaml:"enabled"`
+		StartTime    string   `yaml:"start_time"`
+		IgnoreUsers  []string `yaml:"ignore_users"`
+		IgnoreLabels []string `yaml:"ignore_labels"`
+		Policy       string   `yaml:"policy"`
+		CodeOwners   string   `yaml:"codeowners"`
+		TopK         int      `yaml:"top_k"`
+		Window       struct {
+			Days  int `yaml:"days"`
+			Limit int `yaml:"limit"`
+		} `yaml:"window"`
+		Capacity map[string]float64 `yaml:"capacity"`
+	} `yaml:"triager"`
+	Labeler struct {
+		Enabled *bool `yaml:"enabled"`
+		Labels  struct {
+			Bug         string `yaml:"bug"`
+			Feature     string `yaml:"feature"`
+			Improvement string `yaml:"improvement"`
+		} `yaml:"labels"`
+		Default []string `yaml:"default"`
+	} `yaml:"labeler"`
+	Notifications struct {
+		Email   string `yaml:"email"`
+		Twitter string `yaml:"twitter"`
+	} `yaml:"notifications"`
+}
+
+// RepoConfigErrors lists every problem found in a config file.
+type RepoConfigErrors []string
+
+func (e RepoConfigErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+var (
+	repoConfigPolicies   = map[string]bool{"": true, "greedy": true, "round-robin": true, "least-loaded": true, "suggest-only": true}
+	repoConfigCodeOwners = map[string]bool{"": true, "filter": true, "boost": true, "fallback": true}
+	// The greedy policy checks the caps against the open assignments only;
+	// top_k is used by the policies below and the window and capacity by
+	// those that balance assignments with the workload.
+	topKPolicies     = map[string]bool{"round-robin": true, "least-loaded": true, "suggest-only": true}
+	workloadPolicies = map[string]bool{"round-robin": true, "least-loaded": true}
+)
+
+// ParseRepoConfig decodes and validates a config file.
+func ParseRepoConfig(data []byte) (*RepoConfig, error) {
+	config := &RepoConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, RepoConfigErrors{err.Error()}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *RepoConfig) Validate() error {
+	errs := RepoConfigErrors{}
+	if c.Version != RepoConfigVersion {
+		errs = append(errs, fmt.Sprintf("version: must be %d", RepoConfigVersion))
+	}
+	if c.Triager.StartTime != "" {
+		if _, err := time.Parse("2006-01-02", c.Triager.StartTime); err != nil {
+			errs = append(errs, "triager.start_time: must be a YYYY-MM-DD date")
+		}
+	}
+	if !repoConfigPolicies[c.Triager.Policy] {
+		errs = append(errs, "triager.policy: must be one of greedy, round-robin, least-loaded or suggest-only")
+	}
+	if !repoConfigCodeOwners[c.Triager.CodeOwners] {
+		errs = append(errs, "triager.codeowners: must be one of filter, boost or fallback")
+	}
+	if c.Triager.TopK < 0 {
+		errs = append(errs, "triager.top_k: must not be negative")
+	}
+	if c.Triager.Window.Days < 0 || c.Triager.Window.Limit < 0 {
+		errs = append(errs, "triager.window: days and limit must not be negative")
+	}
+	for user, weight := range c.Triager.Capacity {
+		if weight < 0 {
+			errs = append(errs, fmt.Sprintf("triager.capacity.%v: must not be negative", user))
+		}
+	}
+	if c.Triager.TopK > 0 && !topKPolicies[c.Triager.Policy] {
+		errs = append(errs, "triager.top_k: only used by the round-robin, least-loaded and suggest-only policies")
+	}
+	if (c.Triager.Window.Days > 0 || c.Triager.Window.Limit > 0) && !workloadPolicies[c.Triager.Policy] {
+		errs = append(errs, "triager.window: only used by the round-robin and least-loaded policies")
+	}
+	if len(c.Triager.Capacity) > 0 && !workloadPolicies[c.Triager.Policy] {
+		errs = append(errs, "triager.capacity: only used by the round-robin and least-loaded policies")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Settings maps the config onto the repo settings and the label settings
+// stored alongside them. Toggles left unset keep the install defaults.
+func (c *RepoConfig) Settings(integration Integration) (HeuprConfigSettings, storage) {
+	settings := defaultSettings(integration)
+	settings.IgnoreUsers = c.Triager.IgnoreUsers
+	settings.IgnoreLabels = c.Triager.IgnoreLabels
+	settings.Email = c.Notifications.Email
+	settings.Twitter = c.Notifications.Twitter
+	settings.CodeOwners = c.Triager.CodeOwners
+	settings.TopK = c.Triager.TopK
+	settings.WindowDays = c.Triager.Window.Days
+	settings.WindowLimit = c.Triager.Window.Limit
+	settings.CapacityWeights = c.Triager.Capacity
+	settings.AssignmentPolicy = c.Triager.Policy
+	settings.Shadow = c.Shadow
+	settings.ConfigFile = true
+	if c.Triager.Enabled != nil {
+		settings.EnableTriager = *c.Triager.Enabled
+	}
+	if c.Labeler.Enabled != nil {
+		settings.EnableLabeler = *c.Labeler.Enabled
+	}
+	if c.Triager.StartTime != "" {
+		settings.StartTime, _ = time.Parse("2006-01-02", c.Triager.StartTime)
+	}
+
+	labels := storage{RepoID: integration.RepoID, Buckets: make(map[string][]label)}
+	buckets := map[string]string{
+		"typebug":         c.Labeler.Labels.Bug,
+		"typefeature":     c.Labeler.Labels.Feature,
+		"typeimprovement": c.Labeler.Labels.Improvement,
+	}
+	for bucket, name := range buckets {
+		if name != "" {
+			labels.Buckets[bucket] = []label{{Name: name, Selected: true}}
+		}
+	}
+	for _, name := range c.Labeler.Default {
+		labels.Buckets["typedefault"] = append(labels.Buckets["typedefault"], label{Name: name, Selected: true})
+	}
+	return settings, labels
+}
+
+// ApplyRepoConfig reads the config file from the repo's default branch and
+// stores the resulting settings. Errors are reported on the commit as a check
+// run when sha is given, otherwise in a new issue. Once the file is deleted
+// the repo goes back to the install defaults. It reports whether the
+// settings were replaced.
+func (w *Worker) ApplyRepoConfig(client *github.Client, owner, repo, branch, sha string, integration Integration) bool {
+	g := gateway.Gateway{Client: client}
+	data, err := g.GetFile(owner, repo, RepoConfigPath)
+	if err != nil {
+		utils.AppLog.Error("ApplyRepoConfig() read", zap.String("RepoName", owner+"/"+repo), zap.Error(err))
+		return false
+	}
+	var settings HeuprConfigSettings
+	var labels storage
+	if data == nil {
+		current, err := w.Database.ReadRepositoryIntegrationSettings(integration.RepoID)
+		if err != nil || !current.ConfigFile {
+			return false
+		}
+		settings, labels = defaultSettings(integration), storage{RepoID: integration.RepoID}
+	} else {
+		config, err := ParseRepoConfig(data)
+		if err != nil {
+			utils.AppLog.Warn("ApplyRepoConfig() invalid", zap.String("RepoName", owner+"/"+repo), zap.Error(err))
+			reportRepoConfig(client, owner, repo, branch, sha, err)
+			return false
+		}
+		settings, labels = config.Settings(integration)
+	}
+	w.Database.InsertRepositoryIntegrationSettings(settings)
+	// The labels are written even when empty so that mappings removed from
+	// the file are cleared.
+	if err := w.Database.InsertGobLabelSettings(labels); err != nil {
+		utils.AppLog.Error("ApplyRepoConfig() labels", zap.String("RepoName", owner+"/"+repo), zap.Error(err))
+	}
+	if data != nil {
+		reportRepoConfig(client, owner, repo, branch, sha, nil)
+	}
+	return true
+}
+
+func reportRepoConfig(client *github.Client, owner, repo, branch, sha string, configErr error) {
+	details := []string{}
+	if errs, ok := configErr.(RepoConfigErrors); ok {
+		for _, e := range errs {
+			details = append(details, "- "+e)
+		}
+	}
+	if sha == "" {
+		if configErr == nil {
+			return
+		}
+		title := "Heupr configuration error"
+		body := fmt.Sprintf(RepoConfigErrMessage, RepoConfigPath, strings.Join(details, "\n"))
+		if _, _, err := client.Issues.Create(context.Background(), owner, repo, &github.IssueRequest{Title: &title, Body: &body}); err != nil {
+			utils.AppLog.Error("reportRepoConfig() issue", zap.Error(err))
+		}
+		return
+	}
+
+	status, conclusion := "completed", "success"
+	title, summary := "Settings applied", fmt.Sprintf("%v is valid and its settings are now in use.", RepoConfigPath)
+	if configErr != nil {
+		conclusion = "failure"
+		title, summary = "Invalid configuration", fmt.Sprintf(RepoConfigErrMessage, RepoConfigPath, strings.Join(details, "\n"))
+	}
+	now := github.Timestamp{Time: time.Now()}
+	_, _, err := client.Checks.CreateCheckRun(context.Background(), owner, repo, github.CreateCheckRunOptions{
+		Name:        RepoConfigCheck,
+		HeadBranch:  branch,
+		HeadSHA:     sha,
+		Status:      &status,
+		Conclusion:  &conclusion,
+		CompletedAt: &now,
+		Output:      &github.CheckRunOutput{Title: &title, Summary: &summary},
+	})
+	if err != nil {
+		utils.AppLog.Error("reportRepoConfig() check run", zap.Error(err))
+	}
+}
+
+// touchesRepoConfig reports whether any pushed commit changed the config.
+func touchesRepoConfig(commits []github.PushEventCommit) bool {
+	for _, commit := range commits {
+		for _, files := range [][]string{commit.Added, commit.Modified, commit.Removed} {
+			for _, file := range files {
+				if file == RepoConfigPath {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (w *Worker) ProcessPushEvent(event github.PushEvent) {
+	if event.Repo == nil || event.Repo.ID == nil || !touchesRepoConfig(event.Commits) {
+		return
+	}
+	branch := event.Repo.GetDefaultBranch()
+	if event.GetRef() != "refs/heads/"+branch {
+		return
+	}
+	integration, err := w.Database.ReadIntegrationByRepoID(*event.Repo.ID)
+	if err != nil {
+		utils.AppLog.Error("Failed to process PushEvent.", zap.Error(err))
+		return
+	}
+	client := NewClient(integration.AppID, integration.InstallationID)
+	owner := event.Repo.GetOwner().GetName()
+	if w.ApplyRepoConfig(client, owner, event.Repo.GetName(), branch, event.GetAfter(), *integration) {
+		utils.AppLog.Info("ApplyRepoConfig()", zap.Int64("RepoID", *event.Repo.ID))
+	}
+}
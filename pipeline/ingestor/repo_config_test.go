@@ -0,0 +1,168 @@
+package ingestor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/pipeline/command"
+	"core/testutil/fakegithub"
+)
+
+var repoConfigFile = `
+version: 1
+triager:
+  enabled: true
+  start_time: 1977-05-25
+  ignore_users: [jabba]
+  ignore_labels: [wontfix]
+  policy: round-robin
+  top_k: 2
+  window:
+    days: 7
+    limit: 5
+  capacity:
+    luke: 0.5
+labeler:
+  labels:
+    bug: bug
+    feature: enhancement
+  default: [needs-review]
+notifications:
+  email: leia@alderaan.org
+`
+
+func TestParseRepoConfig(t *testing.T) {
+	config, err := ParseRepoConfig([]byte(repoConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, labels := config.Settings(Integration{RepoID: 4})
+	expected := HeuprConfigSettings{
+		Integration:      Integration{RepoID: 4},
+		EnableTriager:    true,
+		EnableLabeler:    true,
+		IgnoreUsers:      []string{"jabba"},
+		StartTime:        time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC),
+		IgnoreLabels:     []string{"wontfix"},
+		Email:            "leia@alderaan.org",
+		TopK:             2,
+		WindowDays:       7,
+		WindowLimit:      5,
+		CapacityWeights:  map[string]float64{"luke": 0.5},
+		AssignmentPolicy: "round-robin",
+		ConfigFile:       true,
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Error(
+			"\nCONFIG NOT MAPPED TO SETTINGS",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", settings,
+		)
+	}
+	expectedLabels := storage{RepoID: 4, Buckets: map[string][]label{
+		"typebug":     {{Name: "bug", Selected: true}},
+		"typefeature": {{Name: "enhancement", Selected: true}},
+		"typedefault": {{Name: "needs-review", Selected: true}},
+	}}
+	if !reflect.DeepEqual(labels, expectedLabels) {
+		t.Error(
+			"\nCONFIG NOT MAPPED TO LABELS",
+			"\nEXPECTED: ", expectedLabels,
+			"\nACTUAL:   ", labels,
+		)
+	}
+}
+
+func TestParseRepoConfigErrors(t *testing.T) {
+	cases := map[string]int{
+		"version: 1\ntriager:\n  enable: true\n": 1,
+		"version: 2\n":                           1,
+		"version: 1\ntriager:\n  policy: sith\n  top_k: -1\n  codeowners: all\n":     3,
+		"version: 1\ntriager:\n  start_time: May 25\n":                               1,
+		"version: 1\ntriager:\n  top_k: 2\n  window:\n    days: 7\n":                 2,
+		"version: 1\ntriager:\n  policy: suggest-only\n  capacity:\n    luke: 0.5\n": 1,
+	}
+	for data, count := range cases {
+		_, err := ParseRepoConfig([]byte(data))
+		errs, ok := err.(RepoConfigErrors)
+		if !ok || len(errs) != count {
+			t.Error(
+				"\nINVALID CONFIG NOT REPORTED",
+				"\nCONFIG:   ", data,
+				"\nEXPECTED: ", count,
+				"\nACTUAL:   ", err,
+			)
+		}
+	}
+}
+
+func TestTouchesRepoConfig(t *testing.T) {
+	commits := []github.PushEventCommit{{Modified: []string{"README.md"}}, {Added: []string{RepoConfigPath}}}
+	if !touchesRepoConfig(commits) {
+		t.Error("\nCONFIG CHANGE NOT DETECTED")
+	}
+	if touchesRepoConfig(commits[:1]) {
+		t.Error("\nUNRELATED CHANGE DETECTED AS CONFIG CHANGE")
+	}
+}
+
+func TestApplyRepoConfig(t *testing.T) {
+	gh := fakegithub.New()
+	defer gh.Close()
+	client := gh.Client()
+	gh.AddRepo("rebel-alliance", "echo-base")
+	gh.AddFile("rebel-alliance", "echo-base", RepoConfigPath, repoConfigFile)
+
+	integration := Integration{RepoID: 4}
+	db := NewMemoryDatabase()
+	db.InsertRepositoryIntegrationSettings(defaultSettings(integration))
+	w := Worker{Database: db}
+	apply := func() (HeuprConfigSettings, []string) {
+		if !w.ApplyRepoConfig(client, "rebel-alliance", "echo-base", "master", "", integration) {
+			t.Fatal("\nCONFIG NOT APPLIED")
+		}
+		settings, _ := db.ReadRepositoryIntegrationSettings(integration.RepoID)
+		labels, _ := db.ReadTypeLabels(integration.RepoID)
+		return settings, labels
+	}
+
+	settings, labels := apply()
+	if !settings.ConfigFile || !reflect.DeepEqual(labels, []string{"bug", "enhancement"}) {
+		t.Error(
+			"\nCONFIG SETTINGS NOT STORED",
+			"\nACTUAL:   ", settings.ConfigFile, labels,
+		)
+	}
+
+	c, _ := command.Parse("/heupr settings triager=off")
+	reply := commandHandler{database: db, repoID: integration.RepoID, integration: integration}.settings(c)
+	if settings, _ := db.ReadRepositoryIntegrationSettings(integration.RepoID); reply.OK || !settings.EnableTriager {
+		t.Error(
+			"\nCOMMAND OVERRODE CONFIG",
+			"\nACTUAL:   ", reply.Message,
+		)
+	}
+
+	gh.AddFile("rebel-alliance", "echo-base", RepoConfigPath, "version: 1\n")
+	if _, labels = apply(); len(labels) != 0 {
+		t.Error(
+			"\nREMOVED LABELS NOT CLEARED",
+			"\nACTUAL:   ", labels,
+		)
+	}
+
+	gh.RemoveFile("rebel-alliance", "echo-base", RepoConfigPath)
+	settings, _ = apply()
+	if settings.ConfigFile || settings.EnableTriager || !settings.EnableLabeler {
+		t.Error(
+			"\nSETTINGS NOT RESET",
+			"\nACTUAL:   ", settings,
+		)
+	}
+	if w.ApplyRepoConfig(client, "rebel-alliance", "echo-base", "master", "", integration) {
+		t.Error("\nSETTINGS RESET WITHOUT A CONFIG")
+	}
+}
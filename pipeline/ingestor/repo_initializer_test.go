@@ -9,12 +9,15 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
 
+	"core/pipeline/audit"
+	"core/testutil/fakegithub"
 	"core/utils"
 )
 
@@ -41,6 +44,14 @@ func (r *repoInitializerDBStub) InsertGobLabelSettings(settings storage) error {
 
 func (r *repoInitializerDBStub) ReadIntegrations() ([]Integration, error) { return nil, nil }
 
+func (r *repoInitializerDBStub) ReadTypeLabels(repoID int64) ([]string, error) {
+	return nil, nil
+}
+
+func (r *repoInitializerDBStub) ReadRepositoryIntegrationSettings(repoID int64) (HeuprConfigSettings, error) {
+	return HeuprConfigSettings{}, nil
+}
+
 func (r *repoInitializerDBStub) ReadIntegrationByRepoID(id int64) (i *Integration, err error) {
 	// Each case is for each of the test repo IDs passed in for the unit test.
 	switch id {
@@ -58,6 +69,9 @@ func (r *repoInitializerDBStub) InsertIssue(i github.Issue, action *string) {}
 
 func (r *repoInitializerDBStub) InsertPullRequest(p github.PullRequest, action *string) {}
 
+func (r *repoInitializerDBStub) InsertIssueFeedback(event github.IssuesEvent) {}
+func (r *repoInitializerDBStub) InsertActions(actions ...audit.Action)        {}
+
 func (r *repoInitializerDBStub) BulkInsertIssuesPullRequests(i []*github.Issue, p []*github.PullRequest) {
 	r.issues = i
 	r.pulls = p
@@ -72,21 +86,17 @@ func (r *repoInitializerDBStub) DeleteRepositoryIntegration(repoID int64, appID
 func (r *repoInitializerDBStub) ObliterateIntegration(appID int, installID int64) {}
 
 func TestAddRepo(t *testing.T) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/repos/san-hill/banking-clan/issues", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `[{"id":1,"number":1},{"id":2,"number":2}]`)
-	})
-	mux.HandleFunc("repos/san-hill/banking-clan/pulls", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `[{"id":3,"number":3},{"id":4,"number":4}]`)
-	})
-	server := httptest.NewServer(mux)
-	testURL, _ := url.Parse(server.URL + "/")
+	gh := fakegithub.New()
+	defer gh.Close()
+	closed := time.Now()
+	gh.AddIssue("san-hill", "banking-clan", github.Issue{ClosedAt: &closed})
+	gh.AddIssue("san-hill", "banking-clan", github.Issue{ClosedAt: &closed})
+	gh.AddIssue("san-hill", "banking-clan", github.Issue{})
+	gh.AddPull("san-hill", "banking-clan", github.PullRequest{ClosedAt: &closed})
+	gh.AddPull("san-hill", "banking-clan", github.PullRequest{ClosedAt: &closed})
 
 	NewClient = func(appID int, installationID int) *github.Client {
-		c := github.NewClient(nil)
-		c.BaseURL = testURL
-		c.UploadURL = testURL
-		return c
+		return gh.Client()
 	}
 	client := NewClient(1, 1)
 
@@ -105,7 +115,7 @@ func TestAddRepo(t *testing.T) {
 		Client: client,
 	}
 	testRI.AddRepo(testAuthRepo)
-	if len(db.issues) != 2 && len(db.pulls) != 2 {
+	if len(db.issues) != 2 || len(db.pulls) != 2 {
 		t.Error("inserting incorrect number of issues/pulls")
 	}
 }
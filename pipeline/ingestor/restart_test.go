@@ -9,6 +9,9 @@ import (
 	"testing"
 
 	"github.com/google/go-github/github"
+
+	"core/pipeline/audit"
+	"core/testutil/fakegithub"
 )
 
 type restartDA struct{}
@@ -37,6 +40,14 @@ func (r *restartDA) ReadIntegrations() ([]Integration, error) {
 	return []Integration{Integration{1, 1, 1}}, nil
 }
 
+func (r *restartDA) ReadTypeLabels(repoID int64) ([]string, error) {
+	return nil, nil
+}
+
+func (r *restartDA) ReadRepositoryIntegrationSettings(repoID int64) (HeuprConfigSettings, error) {
+	return HeuprConfigSettings{}, nil
+}
+
 func (r *restartDA) ReadIntegrationByRepoID(id int64) (*Integration, error) {
 	return nil, nil
 }
@@ -49,6 +60,9 @@ func (r *restartDA) InsertIssue(i github.Issue, action *string) {}
 
 func (r *restartDA) InsertPullRequest(p github.PullRequest, action *string) {}
 
+func (r *restartDA) InsertIssueFeedback(event github.IssuesEvent) {}
+func (r *restartDA) InsertActions(actions ...audit.Action)        {}
+
 func (r *restartDA) BulkInsertIssuesPullRequests(i []*github.Issue, p []*github.PullRequest) {}
 
 func (r *restartDA) InsertRepositoryIntegration(repoID int64, appID int, installID int64) {}
@@ -60,18 +74,12 @@ func (r *restartDA) DeleteRepositoryIntegration(repoID int64, appID int, install
 func (r *restartDA) ObliterateIntegration(appID int, installID int64) {}
 
 func TestRestart(t *testing.T) {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/repositories/1", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"id":1,"name":"stalgasin-hive","owner":{"login":"poggle-the-lesser"}}`)
-	})
-	server := httptest.NewServer(mux)
-	testURL, _ := url.Parse(server.URL + "/")
+	gh := fakegithub.New()
+	defer gh.Close()
+	gh.AddRepoWithID(1, "poggle-the-lesser", "stalgasin-hive")
 
 	NewClient = func(appID int, installationID int) *github.Client {
-		c := github.NewClient(nil)
-		c.BaseURL = testURL
-		c.UploadURL = testURL
-		return c
+		return gh.Client()
 	}
 
 	issueErr := false
@@ -63,6 +63,12 @@ func (i *IngestorServer) routes() *http.ServeMux {
 	return mux
 }
 
+// Handler returns the webhook routes of the server, so that events can be
+// delivered without listening on a port (see the replay simulator).
+func (i *IngestorServer) Handler() http.Handler {
+	return i.routes()
+}
+
 func (i *IngestorServer) Start() error {
 	bufferPool := NewPool()
 	i.Database = &Database{BufferPool: bufferPool}
@@ -102,6 +108,11 @@ func (i *IngestorServer) Start() error {
 						utils.AppLog.Error("error decoding user settings", zap.Error(err))
 						continue
 					}
+					// .github/heupr.yml takes precedence over the frontend.
+					if settings, err := i.Database.ReadRepositoryIntegrationSettings(s.RepoID); err == nil && settings.ConfigFile {
+						utils.AppLog.Info("repo config in use; user settings ignored", zap.Int64("RepoID", s.RepoID))
+						continue
+					}
 					i.Database.InsertGobLabelSettings(s)
 				}
 			case err := <-watcher.Errors:
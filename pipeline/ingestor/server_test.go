@@ -1,6 +1,7 @@
 package ingestor
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/google/go-github/github"
+
+	"core/testutil/fakegithub"
 )
 
 func Test_NewClient(t *testing.T) {
@@ -66,3 +69,45 @@ func Test_NewClient(t *testing.T) {
 		}
 	}
 }
+
+func TestWebhooks(t *testing.T) {
+	gh := fakegithub.New()
+	defer gh.Close()
+	db := NewMemoryDatabase()
+	server := IngestorServer{Database: db}
+	gh.SetWebhook(server.Handler(), secretKey)
+	worker := Worker{Database: db}
+	process := func() {
+		for {
+			select {
+			case work := <-Workload:
+				worker.Process(work)
+			default:
+				return
+			}
+		}
+	}
+
+	gh.AddRepoWithID(1138, "bomarr-order", "bt-16-perimeter-droid")
+	issue := gh.OpenIssue("bomarr-order", "bt-16-perimeter-droid", "Jabba", "Droid keeps wandering off", "")
+	gh.Client().Issues.AddAssignees(context.Background(), "bomarr-order", "bt-16-perimeter-droid", issue.GetNumber(), []string{"Bib-Fortuna"})
+	gh.Assign("bomarr-order", "bt-16-perimeter-droid", issue.GetNumber(), "Jabba", "Boba")
+	gh.CloseIssue("bomarr-order", "bt-16-perimeter-droid", issue.GetNumber(), "Boba")
+	process()
+
+	actions := []string{}
+	for _, event := range db.Events(0) {
+		if event.RepoID != 1138 || event.Number != issue.GetNumber() {
+			t.Error("\nEVENT STORED FOR WRONG ISSUE", "\nEXPECTED: ", 1138, issue.GetNumber(), "\nACTUAL:   ", event.RepoID, event.Number)
+		}
+		actions = append(actions, event.Action)
+	}
+	expected := []string{"opened", "assigned", "assigned", "closed"}
+	if fmt.Sprint(actions) != fmt.Sprint(expected) {
+		t.Error("\nUNEXPECTED STORED ACTIONS", "\nEXPECTED: ", expected, "\nACTUAL:   ", actions)
+	}
+	// Only the assignment by a human is feedback on the backend.
+	if feedback := db.Feedback(); len(feedback) != 1 || feedback[0].GetAssignee().GetLogin() != "Boba" {
+		t.Error("\nUNEXPECTED FEEDBACK", "\nEXPECTED: ", "Boba", "\nACTUAL:   ", feedback)
+	}
+}
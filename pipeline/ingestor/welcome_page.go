@@ -1,24 +1,9 @@
 package ingestor
 
-const ConfirmationMessage = `@%v Thank you! Would like me to apply the following settings?[Yes/No]
-                             IgnoreUsers: %v
-                             TriageStartTime: %v
-                             IgnoreLabels: %v
-                             Email: %v
-                             Twitter: %v`
+const RepoConfigErrMessage = `Heupr could not apply %v; the previous settings remain in use.
 
-const ConfirmationErrMessage = `@%v An error occured parsing your settings: %v
-                                IgnoreUsers: %v
-                                TriageStartTime: %v
-                                IgnoreLabels: %v
-                                Email: %v
-                                Twitter: %v`
+%v`
 
-const HoldOnMessage = `@%v Understood! The settings will NOT be applied.`
-
-const AppliedSettingsMessage = `@%v Done! Settings processed:
-                                IgnoreUsers: %v
-                                TriageStartTime: %v
-                                IgnoreLabels: %v
-                                Email: %v
-                                Twitter: %v`
+// SettingsMovedMessage answers edits and replies to the onboarding issues
+// that used to carry the settings.
+const SettingsMovedMessage = `@%v Heupr no longer reads settings from this issue. Comment ` + "`%v`" + ` on any issue (e.g. ` + "`/heupr settings triager=on ignore-users=@jabba`" + `) or commit a ` + "`%v`" + ` file instead.`
@@ -2,11 +2,11 @@ package ingestor
 
 import (
 	"context"
-	"time"
 
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
 
+	"core/pipeline/command"
 	"core/utils"
 )
 
@@ -44,8 +44,8 @@ func (w *Worker) ProcessHeuprInstallationEvent(event HeuprInstallationEvent) {
 					return
 				}
 				utils.AppLog.Info("AddRepoIntegrationSettings()", zap.Int64("RepoID", *repo.Repo.ID))
-				settings := HeuprConfigSettings{EnableTriager: false, EnableLabeler: true, Integration: *integration, IgnoreUsers: nil, StartTime: time.Now(), IgnoreLabels: nil, Email: "", Twitter: ""}
-				w.Database.InsertRepositoryIntegrationSettings(settings)
+				w.Database.InsertRepositoryIntegrationSettings(defaultSettings(*integration))
+				w.ApplyRepoConfig(client, *githubRepo.Owner.Login, *githubRepo.Name, githubRepo.GetDefaultBranch(), "", *integration)
 			}
 		case "deleted":
 			w.RepoInitializer.ObliterateIntegration(*e.HeuprInstallation.AppID, *e.HeuprInstallation.ID)
@@ -83,8 +83,8 @@ func (w *Worker) ProcessHeuprInstallationRepositoriesEvent(event HeuprInstallati
 					return
 				}
 				utils.AppLog.Info("AddRepoIntegrationSettings()", zap.Int64("RepoID", *repo.Repo.ID))
-				settings := HeuprConfigSettings{EnableTriager: false, EnableLabeler: true, Integration: *integration, IgnoreUsers: nil, StartTime: time.Now(), IgnoreLabels: nil, Email: "", Twitter: ""}
-				w.Database.InsertRepositoryIntegrationSettings(settings)
+				w.Database.InsertRepositoryIntegrationSettings(defaultSettings(*integration))
+				w.ApplyRepoConfig(client, *githubRepo.Owner.Login, *githubRepo.Name, githubRepo.GetDefaultBranch(), "", *integration)
 			}
 		case "removed":
 			client := NewClient(*e.HeuprInstallation.AppID, int(*e.HeuprInstallation.ID))
@@ -99,6 +99,16 @@ func (w *Worker) ProcessHeuprInstallationRepositoriesEvent(event HeuprInstallati
 	}(event)
 }
 
+// isFeedback reports whether the event is a human changing the assignees or
+// labels of an issue, which the backend compares against its own actions.
+func isFeedback(event github.IssuesEvent) bool {
+	switch event.GetAction() {
+	case "assigned", "unassigned", "labeled", "unlabeled":
+		return event.GetSender().GetLogin() != "heupr[bot]"
+	}
+	return false
+}
+
 func NewWorker(id int, db DataAccess, repoInitializer *RepoInitializer, queue chan chan interface{}) Worker {
 	return Worker{
 		ID:              id,
@@ -116,47 +126,7 @@ func (w *Worker) Start() {
 			w.Queue <- w.Work
 			select {
 			case event := <-w.Work:
-				switch v := event.(type) {
-				case github.IssuesEvent:
-					// The Action that was performed. Can be one of "assigned",
-					// "unassigned", "labeled", "unlabeled", "opened",
-					// "edited", "milestoned", "demilestoned", "closed", or
-					// "reopened".
-					v.Issue.Repository = v.Repo
-					if *v.Action == "edited" && *v.Issue.User.Login == "heupr[bot]" {
-						if *v.Sender.Login != "heupr[bot]" && v.Issue.Assignees != nil {
-							for i := 0; i < len(v.Issue.Assignees); i++ {
-								if *v.Sender.Login == *v.Issue.Assignees[i].Login {
-									go w.ProcessHeuprInteractionIssuesEvent(v)
-									break
-								}
-							}
-						}
-						continue
-					}
-					w.Database.InsertIssue(*v.Issue, v.Action)
-				case github.PullRequestEvent:
-					//v.PullRequest.Base.Repo = v.Repo //TODO: Confirm
-					w.Database.InsertPullRequest(*v.PullRequest, v.Action)
-				case github.IssueCommentEvent:
-					if *v.Action == "created" && *v.Issue.User.Login == "heupr[bot]" {
-						if *v.Sender.Login != "heupr[bot]" && v.Issue.Assignees != nil {
-							for i := 0; i < len(v.Issue.Assignees); i++ {
-								if *v.Sender.Login == *v.Issue.Assignees[i].Login {
-									v.Issue.Repository = v.Repo
-									go w.ProcessHeuprInteractionCommentEvent(v)
-									break
-								}
-							}
-						}
-					}
-				case HeuprInstallationEvent:
-					w.ProcessHeuprInstallationEvent(v)
-				case HeuprInstallationRepositoriesEvent:
-					w.ProcessHeuprInstallationRepositoriesEvent(v)
-				default:
-					utils.AppLog.Error("Unknown", zap.Any("GithubEvent", v))
-				}
+				w.Process(event)
 			case <-w.Quit:
 				return
 			}
@@ -164,6 +134,62 @@ func (w *Worker) Start() {
 	}()
 }
 
+// Process handles a webhook event read from the Workload.
+func (w *Worker) Process(event interface{}) {
+	switch v := event.(type) {
+	case github.IssuesEvent:
+		// The Action that was performed. Can be one of "assigned",
+		// "unassigned", "labeled", "unlabeled", "opened",
+		// "edited", "milestoned", "demilestoned", "closed", or
+		// "reopened".
+		v.Issue.Repository = v.Repo
+		// Settings are changed with "/heupr settings" or .github/heupr.yml,
+		// so edits to the issues Heupr opened are not events; assignees
+		// still editing the onboarding issue are told where settings moved.
+		if *v.Action == "edited" && *v.Issue.User.Login == "heupr[bot]" {
+			if *v.Sender.Login != "heupr[bot]" && isAssignee(v.Issue, *v.Sender.Login) {
+				go w.ProcessHeuprInteractionIssuesEvent(v)
+			}
+			return
+		}
+		w.Database.InsertIssue(*v.Issue, v.Action)
+		if isFeedback(v) {
+			w.Database.InsertIssueFeedback(v)
+		}
+	case github.PullRequestEvent:
+		//v.PullRequest.Base.Repo = v.Repo //TODO: Confirm
+		w.Database.InsertPullRequest(*v.PullRequest, v.Action)
+	case github.IssueCommentEvent:
+		if _, ok := command.Parse(v.Comment.GetBody()); ok && *v.Action == "created" && *v.Sender.Login != "heupr[bot]" {
+			go w.ProcessCommandEvent(v)
+			return
+		}
+		if *v.Action == "created" && *v.Issue.User.Login == "heupr[bot]" {
+			if *v.Sender.Login != "heupr[bot]" && isAssignee(v.Issue, *v.Sender.Login) {
+				go w.ProcessHeuprInteractionCommentEvent(v)
+			}
+		}
+	case github.PushEvent:
+		go w.ProcessPushEvent(v)
+	case HeuprInstallationEvent:
+		w.ProcessHeuprInstallationEvent(v)
+	case HeuprInstallationRepositoriesEvent:
+		w.ProcessHeuprInstallationRepositoriesEvent(v)
+	default:
+		utils.AppLog.Error("Unknown", zap.Any("GithubEvent", v))
+	}
+}
+
+// isAssignee reports whether the user is assigned to the issue.
+func isAssignee(issue *github.Issue, login string) bool {
+	for _, assignee := range issue.Assignees {
+		if assignee.GetLogin() == login {
+			return true
+		}
+	}
+	return false
+}
+
 func (w *Worker) Stop() {
 	go func() {
 		w.Quit <- true
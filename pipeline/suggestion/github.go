@@ -0,0 +1,61 @@
+package suggestion
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// CanAccept reports whether the user may accept suggestions, i.e. has write
+// or admin permission on the repository.
+func CanAccept(client *github.Client, owner, repo, login string) (bool, error) {
+	level, _, err := client.Repositories.GetPermissionLevel(context.Background(), owner, repo, login)
+	if err != nil {
+		return false, err
+	}
+	permission := level.GetPermission()
+	return permission == "admin" || permission == "write", nil
+}
+
+// Find returns the bot suggestion comment on an issue, if any.
+func Find(client *github.Client, owner, repo string, number int) (*github.IssueComment, Suggestion, bool, error) {
+	opt := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := client.Issues.ListComments(context.Background(), owner, repo, number, opt)
+		if err != nil {
+			return nil, Suggestion{}, false, err
+		}
+		for _, comment := range comments {
+			if !strings.HasSuffix(comment.GetUser().GetLogin(), "[bot]") {
+				continue
+			}
+			if s, ok := Parse(comment.GetBody()); ok {
+				return comment, s, true, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+	return nil, Suggestion{}, false, nil
+}
+
+// Accept assigns the chosen candidate, applies the suggested label and marks
+// the suggestion comment as accepted.
+func Accept(client *github.Client, owner, repo string, number int, comment *github.IssueComment, s Suggestion, acceptedBy, assignee string) error {
+	if _, _, err := client.Issues.AddAssignees(context.Background(), owner, repo, number, []string{assignee}); err != nil {
+		return err
+	}
+	if s.Label != "" {
+		if _, _, err := client.Issues.AddLabelsToIssue(context.Background(), owner, repo, number, []string{s.Label}); err != nil {
+			return err
+		}
+	}
+	s.AcceptedBy = acceptedBy
+	s.Assignee = assignee
+	body := s.Render()
+	_, _, err := client.Issues.EditComment(context.Background(), owner, repo, comment.GetID(), &github.IssueComment{Body: &body})
+	return err
+}
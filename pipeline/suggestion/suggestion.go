@@ -0,0 +1,101 @@
+package suggestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Marker starts the hidden JSON line that makes a suggestion comment
+// machine readable so that it can be updated and accepted statelessly.
+const Marker = "<!-- heupr-suggestion "
+
+// AcceptReaction is the reaction maintainers add to accept a suggestion.
+const AcceptReaction = "+1"
+
+// Candidate is a suggested assignee; Because lists the issue terms that
+// most favoured them.
+type Candidate struct {
+	Login      string   `json:"login"`
+	Confidence float64  `json:"confidence"`
+	Because    []string `json:"because,omitempty"`
+}
+
+// Suggestion is the content of the single bot comment posted on an issue by
+// repositories running in suggest-only mode.
+type Suggestion struct {
+	Candidates []Candidate `json:"candidates"`
+	Label      string      `json:"label,omitempty"`
+	AcceptedBy string      `json:"accepted_by,omitempty"`
+	Assignee   string      `json:"assignee,omitempty"`
+}
+
+// Render returns the markdown comment body.
+func (s Suggestion) Render() string {
+	buffer := new(bytes.Buffer)
+	if s.AcceptedBy != "" {
+		fmt.Fprintf(buffer, "@%v accepted the suggestion", s.AcceptedBy)
+		if s.Assignee != "" {
+			fmt.Fprintf(buffer, " and assigned @%v", s.Assignee)
+		}
+		buffer.WriteString(".\n\n")
+	}
+	buffer.WriteString("**Suggested assignees**\n\n| | Assignee | Confidence | Because |\n|---|---|---|---|\n")
+	for i, candidate := range s.Candidates {
+		fmt.Fprintf(buffer, "| %d | @%v | %.0f%% | %v |\n", i+1, candidate.Login, candidate.Confidence*100, strings.Join(candidate.Because, ", "))
+	}
+	if s.Label != "" {
+		fmt.Fprintf(buffer, "\n**Suggested label:** `%v`\n", s.Label)
+	}
+	if s.AcceptedBy == "" && len(s.Candidates) > 0 {
+		fmt.Fprintf(buffer, "\nReact with :+1: to assign @%v", s.Candidates[0].Login)
+		if s.Label != "" {
+			buffer.WriteString(" and apply the label")
+		}
+		buffer.WriteString(", or comment `/heupr accept @login` to pick another candidate.\n")
+	}
+	encoded, _ := json.Marshal(s)
+	fmt.Fprintf(buffer, "\n%v%s -->\n", Marker, encoded)
+	return buffer.String()
+}
+
+// Parse reads a suggestion back from a comment body.
+func Parse(body string) (Suggestion, bool) {
+	s := Suggestion{}
+	start := strings.Index(body, Marker)
+	if start < 0 {
+		return s, false
+	}
+	encoded := body[start+len(Marker):]
+	end := strings.Index(encoded, " -->")
+	if end < 0 {
+		return s, false
+	}
+	if err := json.Unmarshal([]byte(encoded[:end]), &s); err != nil {
+		return s, false
+	}
+	return s, true
+}
+
+// Choose returns the candidate to assign when a suggestion is accepted:
+// the requested login if given (with or without "@") and suggested,
+// otherwise the top candidate.
+func (s Suggestion) Choose(login string) (string, bool) {
+	login = strings.TrimPrefix(login, "@")
+	for _, candidate := range s.Candidates {
+		if login == "" || strings.EqualFold(candidate.Login, login) {
+			return candidate.Login, true
+		}
+	}
+	return "", false
+}
+
+// Confidences maps each candidate to its confidence.
+func (s Suggestion) Confidences() map[string]float64 {
+	confidences := make(map[string]float64)
+	for _, candidate := range s.Candidates {
+		confidences[candidate.Login] = candidate.Confidence
+	}
+	return confidences
+}
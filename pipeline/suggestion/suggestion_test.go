@@ -0,0 +1,45 @@
+package suggestion
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderParse(t *testing.T) {
+	s := Suggestion{
+		Candidates: []Candidate{{Login: "bail", Confidence: 0.72, Because: []string{"senate", "alderaan"}}, {Login: "mon", Confidence: 0.2}},
+		Label:      "bug",
+	}
+	body := s.Render()
+	if !strings.Contains(body, "| 1 | @bail | 72% | senate, alderaan |") || !strings.Contains(body, "`bug`") {
+		t.Error(
+			"\nSUGGESTION NOT RENDERED",
+			"\nACTUAL:   ", body,
+		)
+	}
+	parsed, ok := Parse(body)
+	if !ok || !reflect.DeepEqual(parsed, s) {
+		t.Error(
+			"\nSUGGESTION NOT PARSED",
+			"\nEXPECTED: ", s,
+			"\nACTUAL:   ", parsed,
+		)
+	}
+	if _, ok := Parse("looks good to me"); ok {
+		t.Error("\nPLAIN COMMENT PARSED AS SUGGESTION")
+	}
+}
+
+func TestChoose(t *testing.T) {
+	s := Suggestion{Candidates: []Candidate{{Login: "bail"}, {Login: "mon"}}}
+	if login, ok := s.Choose(""); !ok || login != "bail" {
+		t.Error("\nTOP CANDIDATE NOT CHOSEN", "\nACTUAL:   ", login)
+	}
+	if login, ok := s.Choose("@Mon"); !ok || login != "mon" {
+		t.Error("\nREQUESTED CANDIDATE NOT CHOSEN", "\nACTUAL:   ", login)
+	}
+	if _, ok := s.Choose("tarkin"); ok {
+		t.Error("\nUNSUGGESTED CANDIDATE CHOSEN")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -12,6 +13,7 @@ import (
 
 	"core/models"
 	"core/models/bhattacharya"
+	"core/models/evaluation"
 	"core/pipeline/gateway"
 	conf "core/pipeline/gateway/conflation"
 	"core/utils"
@@ -161,8 +163,15 @@ func (t *BackTestRunner) Run(repo string) {
 	utils.ModelLog.Info("Backtest model training...")
 	fmt.Println("Training set size: ", len(processedTrainingSet))
 
-	scoreJohn := t.Context.Model.JohnFold(processedTrainingSet)
-	fmt.Println("John Fold:", scoreJohn)
+	evaluator := evaluation.Evaluator{New: func() models.Algorithm { return &bhattacharya.NBModel{} }}
+	for _, splitter := range []evaluation.Splitter{evaluation.KFold{K: 10}, evaluation.Incremental{Steps: 10}} {
+		report := evaluator.Run(processedTrainingSet, splitter)
+		writeReport(report)
+		fmt.Println(report.Splitter, "Top-1:", report.Overall.TopK[1], "Top-5:", report.Overall.TopK[5], "MRR:", report.Overall.MRR)
+	}
+	chronological := evaluation.Chronological{New: evaluator.New, Window: 30 * 24 * time.Hour}.Run(processedTrainingSet)
+	writeReport(chronological)
+	fmt.Println(chronological.Splitter, "Top-1:", chronological.Overall.TopK[1], "Top-5:", chronological.Overall.TopK[5], "MRR:", chronological.Overall.MRR)
 
 	openIssues, err := newGateway.GetOpenIssues(r[0], r[1])
 	if err != nil {
@@ -223,3 +232,36 @@ func (t *BackTestRunner) Run(repo string) {
 		}
 	}
 }
+
+// writeReport saves the report as JSON and CSV, and the overall confusion
+// matrix as an HTML heatmap, next to the model log.
+func writeReport(report evaluation.Report) {
+	base := strings.TrimSuffix(utils.Config.ModelLogPath, filepath.Ext(utils.Config.ModelLogPath)) + "-" + report.Splitter
+	writers := map[string]func(io.Writer) error{
+		".json":        report.WriteJSON,
+		".csv":         report.WriteCSV,
+		"-classes.csv": report.WriteClassCSV,
+	}
+	for suffix, write := range writers {
+		file, err := os.Create(base + suffix)
+		if err != nil {
+			utils.ModelLog.Error("Cannot create backtest report.", zap.Error(err))
+			continue
+		}
+		if err := write(file); err != nil {
+			utils.ModelLog.Error("Cannot write backtest report.", zap.Error(err))
+		}
+		file.Close()
+	}
+	if confusion := report.Overall.Confusion(); confusion != nil {
+		file, err := os.Create(base + "-heatmap.html")
+		if err != nil {
+			utils.ModelLog.Error("Cannot create backtest heatmap.", zap.Error(err))
+			return
+		}
+		defer file.Close()
+		if err := confusion.WriteHTML(file, report.Splitter); err != nil {
+			utils.ModelLog.Error("Cannot write backtest heatmap.", zap.Error(err))
+		}
+	}
+}
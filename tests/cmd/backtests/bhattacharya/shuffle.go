@@ -6,13 +6,14 @@ import (
 )
 
 // DOC: Shuffle provides object shuffling for training / backtesting purposes.
-//      This helper function returns a new instance of a shuffled list.
+//      This helper function shuffles the list in place with a Fisher-Yates
+//      shuffle so every permutation is equally likely for a given seed.
+//      Shuffled issues train on the future; use evaluation.Chronological
+//      for results that reflect how the backend runs.
 func Shuffle(issues []conflation.ExpandedIssue, seed int64) {
-	rand.Seed(seed)
-	for i := 0; i < len(issues); i++ {
-		r := rand.Intn(len(issues) - 1)
-		if i != r {
-			issues[r], issues[i] = issues[i], issues[r]
-		}
+	random := rand.New(rand.NewSource(seed))
+	for i := len(issues) - 1; i > 0; i-- {
+		r := random.Intn(i + 1)
+		issues[r], issues[i] = issues[i], issues[r]
 	}
 }
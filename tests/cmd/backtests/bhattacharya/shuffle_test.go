@@ -11,8 +11,9 @@ var letters = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"}
 func generateRandomIssues() []conflation.ExpandedIssue {
 	list := []conflation.ExpandedIssue{}
 	for _, letter := range letters {
-		githubIssue := github.Issue{Body: &letter}
-		crIssue := conflation.CRIssue{githubIssue, []int{}, []conflation.CRPullRequest{}, false}
+		body := letter
+		githubIssue := github.Issue{Body: &body}
+		crIssue := conflation.CRIssue{Issue: githubIssue}
 		list = append(list, conflation.ExpandedIssue{Issue: crIssue})
 	}
 	return list
@@ -25,14 +26,36 @@ func TestShuffle(t *testing.T) {
 	shuffledList := generateRandomIssues()
 	Shuffle(shuffledList, seed)
 
-	for i, _ := range originalList {
-		if originalList[i].Issue.Body == shuffledList[i].Issue.Body {
-			t.Error(
-				"LISTS HAVE NOT BEEN SHUFFLED",
-				"\n", "ORIGINAL:", originalList,
-				"\n", "SHUFFLED:", shuffledList,
-			)
-			break
+	moved := false
+	seen := map[string]bool{}
+	for i := range originalList {
+		seen[*shuffledList[i].Issue.Body] = true
+		if *originalList[i].Issue.Body != *shuffledList[i].Issue.Body {
+			moved = true
 		}
 	}
+	if !moved || len(seen) != len(letters) {
+		t.Error(
+			"LISTS HAVE NOT BEEN SHUFFLED",
+			"\n", "ORIGINAL:", originalList,
+			"\n", "SHUFFLED:", shuffledList,
+		)
+	}
+}
+
+func TestShuffleUniform(t *testing.T) {
+	// Every letter must be able to reach every position, including the last.
+	last := map[string]bool{}
+	for s := int64(0); s < 200; s++ {
+		list := generateRandomIssues()
+		Shuffle(list, s)
+		last[*list[len(list)-1].Issue.Body] = true
+	}
+	if len(last) != len(letters) {
+		t.Error(
+			"SHUFFLE IS BIASED",
+			"\n", "EXPECTED LAST POSITIONS:", len(letters),
+			"\n", "ACTUAL LAST POSITIONS:", len(last),
+		)
+	}
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/go-github/github"
+
+	"core/pipeline/dataset"
+	"core/pipeline/gateway"
+	"core/pipeline/gateway/conflation"
+)
+
+// This program exports the conflated issues of a repository as a JSONL
+// dataset, from the gateway disk cache, the ingestor database or the closed
+// issues of a Jira project, so that backtests can be reproduced offline with
+// their -Dataset flag.
+// Example: ./dataset -Source cache -Repo dotnet/corefx -Output corefx.jsonl
+// Example: ./dataset -Source db -RepoID 724712 -Output corefx.jsonl
+// Example: ./dataset -Source jira -JiraURL https://issues.apache.org/jira/ -Repo LUCENE -Output lucene.jsonl
+func main() {
+	source := flag.String("Source", "cache", "cache, db or jira")
+	repo := flag.String("Repo", "", "repository to export, as owner/name, or the Jira project key")
+	repoID := flag.Int64("RepoID", 0, "repository id, required for the db source")
+	dsn := flag.String("DSN", "root@/heupr?interpolateParams=true&parseTime=true", "database connection string")
+	jiraURL := flag.String("JiraURL", "", "base URL of the Jira instance, required for the jira source")
+	jql := flag.String("JQL", "", "additional JQL restricting the Jira issues")
+	output := flag.String("Output", "dataset.jsonl", "path of the dataset")
+	flag.Parse()
+
+	provenance := dataset.Provenance{Repo: *repo, RepoID: *repoID, ExportedAt: time.Now().UTC()}
+	var issues []*github.Issue
+	var pulls []*github.PullRequest
+	var expanded []conflation.ExpandedIssue
+	var err error
+	switch *source {
+	case "cache":
+		r := strings.Split(*repo, "/")
+		if len(r) != 2 {
+			log.Fatal("Please specify a valid Repo. Example ./dataset -Source cache -Repo dotnet/corefx")
+		}
+		provenance.Source = dataset.SourceCache
+		issues, pulls, err = dataset.FromCache(r[0], r[1])
+	case "db":
+		if *repoID == 0 {
+			log.Fatal("Please specify a valid RepoID. Example ./dataset -Source db -RepoID 724712")
+		}
+		provenance.Source = dataset.SourceDatabase
+		var db *sql.DB
+		if db, err = sql.Open("mysql", *dsn); err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+		issues, pulls, err = dataset.FromDatabase(db, *repoID)
+	case "jira":
+		if *jiraURL == "" || *repo == "" {
+			log.Fatal("Please specify a valid JiraURL and Repo. Example ./dataset -Source jira -JiraURL https://issues.apache.org/jira/ -Repo LUCENE")
+		}
+		provenance.Source = dataset.SourceJira
+		var client *jira.Client
+		if client, err = jira.NewClient(nil, *jiraURL); err != nil {
+			log.Fatal(err)
+		}
+		expanded, err = (&gateway.JiraSource{Client: client, JQL: *jql}).ClosedIssues(*repo)
+	default:
+		log.Fatalf("Unknown source %q", *source)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	if expanded == nil {
+		expanded = dataset.Conflate(issues, pulls)
+	}
+	if err := dataset.Write(file, provenance, expanded); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Exported", len(expanded), "issues to", *output)
+}
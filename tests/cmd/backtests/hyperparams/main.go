@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+
+	"core/models/bhattacharya"
+	"core/models/evaluation"
+	"core/pipeline/dataset"
+	"core/pipeline/gateway"
+	conf "core/pipeline/gateway/conflation"
+)
+
+// This program searches the Bhattacharya hyperparameters of a repository.
+// Issues come from a JSONL dataset, or else from the gateway disk cache
+// (fetched once if missing), and each
+// configuration is scored on the evaluation splits. The ranked results are
+// written as CSV and the best configuration as JSON, which the backend loads
+// through "PUT /admin/repos/{id}/params".
+// Example: ./hyperparams -Repo dotnet/corefx -Search random -Trials 40
+// Example: ./hyperparams -Dataset corefx.jsonl
+func main() {
+	repo := flag.String("Repo", "", "repository to search, as owner/name")
+	data := flag.String("Dataset", "", "JSONL dataset to search offline instead of Repo")
+	token := flag.String("Token", os.Getenv("GITHUB_TOKEN"), "GitHub token used when the cache is empty")
+	search := flag.String("Search", "grid", "grid or random")
+	trials := flag.Int("Trials", 20, "number of random configurations")
+	seed := flag.Int64("Seed", 1, "random search seed")
+	split := flag.String("Split", "incremental", "incremental or kfold")
+	folds := flag.Int("Folds", 5, "number of folds or incremental steps")
+	metric := flag.String("Metric", "mrr", "ranking metric: top1, top3, top5, mrr, macro_f1 or kappa")
+	results := flag.String("Results", "hyperparams.csv", "path of the ranked results table")
+	output := flag.String("Output", "params.json", "path of the best configuration")
+	flag.Parse()
+
+	r := strings.Split(*repo, "/")
+	if *data == "" && len(r) != 2 {
+		log.Fatal("Please specify a valid Repo or Dataset. Example ./hyperparams -Repo dotnet/corefx")
+	}
+	if _, ok := metrics[*metric]; !ok {
+		log.Fatalf("Unknown metric %q", *metric)
+	}
+
+	var candidates []bhattacharya.Params
+	switch *search {
+	case "grid":
+		candidates = grid()
+	case "random":
+		candidates = random(*trials, *seed)
+	default:
+		log.Fatalf("Unknown search %q", *search)
+	}
+	if len(candidates) == 0 {
+		log.Fatal("Please specify at least one Trial.")
+	}
+	var splitter evaluation.Splitter
+	switch *split {
+	case "incremental":
+		splitter = evaluation.Incremental{Steps: *folds}
+	case "kfold":
+		splitter = evaluation.KFold{K: *folds}
+	default:
+		log.Fatalf("Unknown split %q", *split)
+	}
+
+	var expanded []conf.ExpandedIssue
+	var err error
+	if *data != "" {
+		expanded, err = read(*data)
+	} else {
+		expanded, err = fetch(r[0], r[1], *token)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	issues := training(expanded)
+	fmt.Println("Issues:", len(issues), "Configurations:", len(candidates))
+
+	ranked := rank(run(issues, candidates, splitter), *metric)
+	table, err := os.Create(*results)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer table.Close()
+	if err := writeTable(table, ranked); err != nil {
+		log.Fatal(err)
+	}
+	if err := writeTop(os.Stdout, ranked, 10); err != nil {
+		log.Fatal(err)
+	}
+
+	best, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer best.Close()
+	if err := bhattacharya.WriteParams(best, ranked[0].Params); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Best:", ranked[0].Params, "written to", *output)
+}
+
+// read returns the issues of a JSONL dataset.
+func read(path string) ([]conf.ExpandedIssue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records, err := dataset.Read(file)
+	if err != nil {
+		return nil, err
+	}
+	return dataset.Issues(records), nil
+}
+
+// fetch returns the conflated closed issues of a repo.
+func fetch(owner, repo, token string) ([]conf.ExpandedIssue, error) {
+	client := github.NewClient(oauth2.NewClient(oauth2.NoContext, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})))
+	cached := gateway.CachedGateway{Gateway: &gateway.Gateway{Client: client}, DiskCache: &gateway.DiskCache{}}
+	issues, err := cached.GetClosedIssues(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	pulls, err := cached.GetClosedPulls(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return dataset.Conflate(issues, pulls), nil
+}
+
+// training returns the conflated issues with a known assignee.
+func training(issues []conf.ExpandedIssue) []conf.ExpandedIssue {
+	training := []conf.ExpandedIssue{}
+	for _, issue := range issues {
+		if _, ok := evaluation.Assignee(issue); !ok || !issue.Conflate {
+			continue
+		}
+		empty := ""
+		if issue.Issue.ID != nil && issue.Issue.Body == nil {
+			issue.Issue.Body = &empty
+		}
+		if issue.Issue.ID == nil && issue.PullRequest.Body == nil {
+			issue.PullRequest.Body = &empty
+		}
+		training = append(training, issue)
+	}
+	return training
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"core/models"
+	"core/models/bhattacharya"
+	"core/models/evaluation"
+	conf "core/pipeline/gateway/conflation"
+)
+
+// Values searched by the grid; zero keeps the model default.
+var (
+	unseenProbs = []float64{0, 1e-8, 1e-5}
+	smoothers   = []float64{0.1, 0.5, 0, 2}
+)
+
+// metrics reads a ranking metric from the overall results.
+var metrics = map[string]func(evaluation.Fold) float64{
+	"top1":     func(f evaluation.Fold) float64 { return f.TopK[1] },
+	"top3":     func(f evaluation.Fold) float64 { return f.TopK[3] },
+	"top5":     func(f evaluation.Fold) float64 { return f.TopK[5] },
+	"mrr":      func(f evaluation.Fold) float64 { return f.MRR },
+	"macro_f1": func(f evaluation.Fold) float64 { return f.MacroF1 },
+	"kappa":    func(f evaluation.Fold) float64 { return f.Kappa },
+}
+
+// result is a configuration with its overall evaluation.
+type result struct {
+	Params  bhattacharya.Params
+	Overall evaluation.Fold
+	Score   float64
+}
+
+// grid returns every combination of the searched values.
+func grid() []bhattacharya.Params {
+	candidates := []bhattacharya.Params{}
+	for _, unseen := range unseenProbs {
+		for _, smoother := range smoothers {
+			for flags := 0; flags < 8; flags++ {
+				candidates = append(candidates, bhattacharya.Params{
+					UnseenProb:      unseen,
+					Smoother:        smoother,
+					DisableTfIdf:    flags&1 != 0,
+					KeepStopWords:   flags&2 != 0,
+					DisableStemming: flags&4 != 0,
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// random samples n configurations, drawing the probabilities log-uniformly.
+func random(n int, seed int64) []bhattacharya.Params {
+	r := rand.New(rand.NewSource(seed))
+	candidates := []bhattacharya.Params{}
+	for i := 0; i < n; i++ {
+		candidates = append(candidates, bhattacharya.Params{
+			UnseenProb:      math.Pow(10, -12+8*r.Float64()),
+			Smoother:        math.Pow(10, -2+3*r.Float64()),
+			DisableTfIdf:    r.Intn(2) == 1,
+			KeepStopWords:   r.Intn(2) == 1,
+			DisableStemming: r.Intn(2) == 1,
+		})
+	}
+	return candidates
+}
+
+// run evaluates every configuration on the same splits.
+func run(issues []conf.ExpandedIssue, candidates []bhattacharya.Params, splitter evaluation.Splitter) []result {
+	results := []result{}
+	for i, params := range candidates {
+		params := params
+		evaluator := evaluation.Evaluator{New: func() models.Algorithm { return &bhattacharya.NBModel{Params: params} }}
+		report := evaluator.Run(issues, splitter)
+		results = append(results, result{Params: params, Overall: report.Overall})
+		fmt.Printf("%d/%d %s MRR %.4f\n", i+1, len(candidates), params, report.Overall.MRR)
+	}
+	return results
+}
+
+// rank orders the results by the metric, best first; ties keep the
+// candidate order so the search is deterministic.
+func rank(results []result, metric string) []result {
+	score := metrics[metric]
+	for i := range results {
+		results[i].Score = score(results[i].Overall)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
+
+var header = []string{"rank", "score", "unseen_prob", "smoother", "tfidf", "stopwords", "stemming", "top1", "top3", "top5", "mrr", "macro_f1", "kappa", "coverage"}
+
+func (r result) row(rank int) []string {
+	p := r.Params
+	return []string{
+		strconv.Itoa(rank),
+		formatFloat(r.Score),
+		strconv.FormatFloat(p.UnseenProb, 'g', 3, 64),
+		strconv.FormatFloat(p.Smoother, 'g', 3, 64),
+		strconv.FormatBool(!p.DisableTfIdf),
+		strconv.FormatBool(!p.KeepStopWords),
+		strconv.FormatBool(!p.DisableStemming),
+		formatFloat(r.Overall.TopK[1]),
+		formatFloat(r.Overall.TopK[3]),
+		formatFloat(r.Overall.TopK[5]),
+		formatFloat(r.Overall.MRR),
+		formatFloat(r.Overall.MacroF1),
+		formatFloat(r.Overall.Kappa),
+		formatFloat(r.Overall.Coverage),
+	}
+}
+
+// writeTable writes the ranked results as CSV.
+func writeTable(w io.Writer, ranked []result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, r := range ranked {
+		if err := writer.Write(r.row(i + 1)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTop prints the n best results as an aligned table.
+func writeTop(w io.Writer, ranked []result, n int) error {
+	writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	line := func(columns []string) {
+		for i, column := range columns {
+			if i > 0 {
+				fmt.Fprint(writer, "\t")
+			}
+			fmt.Fprint(writer, column)
+		}
+		fmt.Fprintln(writer)
+	}
+	line(header)
+	for i := 0; i < n && i < len(ranked); i++ {
+		line(ranked[i].row(i + 1))
+	}
+	return writer.Flush()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
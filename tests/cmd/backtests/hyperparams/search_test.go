@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"core/models"
+	"core/models/evaluation"
+)
+
+func TestCandidates(t *testing.T) {
+	candidates := grid()
+	unique := make(map[string]bool)
+	for _, p := range candidates {
+		unique[p.String()] = true
+	}
+	if expected := len(unseenProbs) * len(smoothers) * 8; len(candidates) != expected || len(unique) != expected {
+		t.Error(
+			"\nGRID INCOMPLETE",
+			"\nEXPECTED: ", expected,
+			"\nACTUAL:   ", len(candidates), len(unique),
+		)
+	}
+
+	first, second := random(5, 66), random(5, 66)
+	for i := range first {
+		if first[i] != second[i] || first[i].UnseenProb <= 0 || first[i].Smoother <= 0 {
+			t.Error(
+				"\nRANDOM SEARCH NOT DETERMINISTIC",
+				"\nEXPECTED: ", first[i],
+				"\nACTUAL:   ", second[i],
+			)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := grid()[:3]
+	results := []result{}
+	for i, mrr := range []float64{0.2, 0.6, 0.4} {
+		results = append(results, result{Params: candidates[i], Overall: evaluation.Fold{Metrics: models.Metrics{MRR: mrr}}})
+	}
+	ranked := rank(results, "mrr")
+	if ranked[0].Params != candidates[1] || ranked[1].Params != candidates[2] || ranked[0].Score != 0.6 {
+		t.Error(
+			"\nRESULTS NOT RANKED",
+			"\nACTUAL:   ", ranked,
+		)
+	}
+
+	buffer := bytes.Buffer{}
+	if err := writeTable(&buffer, ranked); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "1,0.6000,") {
+		t.Error(
+			"\nTABLE INCORRECT",
+			"\nACTUAL:   ", buffer.String(),
+		)
+	}
+}
@@ -3,15 +3,17 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"core/models/labelmaker"
 	conf "core/pipeline/gateway/conflation"
 	"core/pipeline/gateway"
-	"core/tests/cmd/backtests/gateway/jira"
 	"core/utils"
 
 	"github.com/google/go-github/github"
@@ -120,7 +122,39 @@ type LabelStats struct {
 	Total int
 }
 
-func jiraBacktest() {
+// correctedJiraIssues returns the issues of a Jira project matching the JQL
+// that have a corrected classification, labeled with it.
+func correctedJiraIssues(client *jiraclient.Client, project, correctedFile, jql string) []*github.Issue {
+	file, err := os.Open(correctedFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	corrections, err := gateway.ReadCorrections(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	source := gateway.CachedSource{
+		Name:      "jira",
+		Source:    &gateway.JiraSource{Client: client, JQL: jql, Corrections: corrections},
+		DiskCache: &gateway.DiskCache{},
+	}
+	expanded, err := source.ClosedIssues(project)
+	if err != nil {
+		log.Fatal(err)
+	}
+	issues := []*github.Issue{}
+	for i := range expanded {
+		issue := expanded[i].Issue.Issue
+		if _, ok := corrections[project+"-"+strconv.Itoa(issue.GetNumber())]; ok {
+			issue.Labels = issue.Labels[:1]
+			issues = append(issues, &issue)
+		}
+	}
+	return issues
+}
+
+func jiraBacktest(jql string) {
 	ctx = context.Background()
 	var err error
 	client, err = language.NewClient(ctx)
@@ -130,12 +164,10 @@ func jiraBacktest() {
 	NlpGateway := labelmaker.CachedNlpGateway{NlpGateway: &labelmaker.NlpGateway{Client: client}, DiskCache: &labelmaker.HashedDiskCache{}}
 
 	jiraClient, _ := jiraclient.NewClient(nil, "https://issues.apache.org/jira/")
-	jiraGateway := jira.CachedGateway{Gateway: &jira.Gateway{Client: jiraClient}, DiskCache: &gateway.DiskCache{}}
-	jiraIssues, _ := jiraGateway.GetIssues("JCR", "./jackrabbit_classification_vs_type.csv")
-	jiraIssues2, _ := jiraGateway.GetIssues("LUCENE", "./lucene_classification_vs_type.csv")
-	jiraIssues3,_ := jiraGateway.GetIssues("HTTPCLIENT", "./httpclient_classification_vs_type.csv")
-	jiraIssues = append(jiraIssues, jiraIssues2...)
-	jiraIssues = append(jiraIssues, jiraIssues3...)
+	jiraIssues := []*github.Issue{}
+	jiraIssues = append(jiraIssues, correctedJiraIssues(jiraClient, "JCR", "./jackrabbit_classification_vs_type.csv", jql)...)
+	jiraIssues = append(jiraIssues, correctedJiraIssues(jiraClient, "LUCENE", "./lucene_classification_vs_type.csv", jql)...)
+	jiraIssues = append(jiraIssues, correctedJiraIssues(jiraClient, "HTTPCLIENT", "./httpclient_classification_vs_type.csv", jql)...)
 
 
 	featureLabel := "RFE"
@@ -225,7 +257,10 @@ func jiraBacktest() {
 
 
 func main() {
-	jiraBacktest()
+	// The corrected classifications cover the issues created in this range.
+	jql := flag.String("JQL", "created >= 2005-08-05 AND created <= 2013-12-09", "JQL restricting the Jira issues")
+	flag.Parse()
+	jiraBacktest(*jql)
   return
 	ctx = context.Background()
 
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"core/models/bhattacharya"
+)
+
+// This program converts a model recovery file (any supported format version)
+// into indented JSON so that models can be inspected and diffed.
+// Example: ./modelexport -Model JFold9.model > JFold9.json
+func main() {
+	model := flag.String("Model", "", "path to the model recovery file")
+	flag.Parse()
+
+	if *model == "" {
+		log.Fatal("Please specify a valid Model. Example ./modelexport -Model JFold9.model")
+	}
+
+	nbModel := bhattacharya.NBModel{}
+	if err := nbModel.RecoverModelFromFile(*model); err != nil {
+		log.Fatal(err)
+	}
+	if err := nbModel.ExportJSON(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
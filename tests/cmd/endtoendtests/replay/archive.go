@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gzip "github.com/klauspost/pgzip"
+)
+
+// Event is a GH Archive event, see https://www.gharchive.org.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Actor     json.RawMessage `json:"actor"`
+	Repo      ArchiveRepo     `json:"repo"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ArchiveRepo is the repository of an event; Name is "owner/name".
+type ArchiveRepo struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// webhooks maps the replayed archive event types to their webhook names.
+var webhooks = map[string]string{
+	"IssuesEvent":      "issues",
+	"PullRequestEvent": "pull_request",
+}
+
+// ReadArchive reads the issue and pull request events of the gzipped GH
+// Archive file, or of every file of the directory, in time order. Events of
+// equal time keep their archive order. If repos is not empty only the events
+// of those repos ("owner/name") are kept.
+func ReadArchive(path string, repos ...string) ([]Event, error) {
+	files, err := archiveFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool)
+	for _, repo := range repos {
+		keep[repo] = true
+	}
+	events := []Event{}
+	for _, file := range files {
+		if events, err = readFile(file, keep, events); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	return events, nil
+}
+
+// archiveFiles returns the path, or the gzipped files of the directory in
+// name order (GH Archive names sort chronologically within a day).
+func archiveFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".gz") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func readFile(filename string, keep map[string]bool, events []Event) ([]Event, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	decoder := json.NewDecoder(gr)
+	for {
+		e := Event{}
+		if err := decoder.Decode(&e); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := webhooks[e.Type]; !ok {
+			continue
+		}
+		if len(keep) != 0 && !keep[e.Repo.Name] {
+			continue
+		}
+		events = append(events, e)
+	}
+}
@@ -1,79 +1,59 @@
 package main
 
 import (
-	"core/pipeline/backend"
-	"core/pipeline/ingestor"
-	"core/tests/cmd/endtoendtests/replay"
 	"flag"
 	"fmt"
-	"github.com/google/go-github/github"
-	"net/url"
-	"time"
+	"log"
+	"os"
+	"strings"
+
+	"core/tests/cmd/endtoendtests/replay"
 )
 
+// This program replays GH Archive files (https://www.gharchive.org) through
+// the ingestor and the backend in simulated time and reports the assignment
+// accuracy per repo. Nothing is read from MySQL or the network.
+// Example: ./cmd -Archive /data/GithubArchive/ -Repos dotnet/corefx -Warmup 720h
 func main() {
-	runBacktestFlag := flag.Bool("runbacktest", false, "runs the end to end backtest")
-	loadArchiveFlag := flag.Bool("loadarchive", false, "load archive into the database")
-	archivePathFlag := flag.String("archivepath", "", "location of github archive")
+	archive := flag.String("Archive", "", "gzipped GH Archive file or directory")
+	repos := flag.String("Repos", "", "comma separated repos to replay, as owner/name; all if empty")
+	warmup := flag.Duration("Warmup", 0, "simulated time during which issues are only learned")
+	interval := flag.Duration("Interval", replay.DefaultInterval, "simulated time between backend pulldowns")
+	policy := flag.String("Policy", "", "assignment policy; greedy if empty")
+	output := flag.String("Output", "", "path of the JSON report with every mutation")
 	flag.Parse()
 
-	if !*runBacktestFlag && !*loadArchiveFlag {
-		fmt.Println("Usage: ./cmd --loadarchive=true --archivepath=/home/michael/Data/GithubArchive/")
-		fmt.Println("Usage: ./cmd --runbacktest=true")
-		return
+	if *archive == "" {
+		log.Fatal("Please specify a valid Archive. Example ./cmd -Archive /data/GithubArchive/")
 	}
-
-	//bufferPool := ingestor.NewPool()
-	db := ingestor.Database{} //{BufferPool: bufferPool}
-	db.Open()
-
-	dispatcher := ingestor.Dispatcher{}
-	dispatcher.Start(5)
-	ingestorServer := ingestor.IngestorServer{}
-	go ingestorServer.Start()
-
-	bs := replay.BacktestServer{DB: &db, Ingestor: &ingestorServer}
-	go bs.Start()
-
-	if *loadArchiveFlag && *archivePathFlag != "" {
-		bs.LoadArchive(*archivePathFlag)
+	filter := []string{}
+	if *repos != "" {
+		filter = strings.Split(*repos, ",")
 	}
+	events, err := replay.ReadArchive(*archive, filter...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Events:", len(events))
 
-	if *runBacktestFlag {
-		backendServer := backend.BackendServer{}
-		client := github.NewClient(nil)
-		url, _ := url.Parse("http://localhost:8000/")
-		client.BaseURL = url
-		client.UploadURL = url
-
-		repos, err := db.ReadBacktestRepos()
-		if err != nil {
-			panic(err)
-		}
-		backendServer.Repos = new(backend.ActiveRepos)
-		backendServer.Repos.Actives = make(map[int]*backend.ArchRepo)
-
-		for i := 0; i < len(repos); i++ {
-			repo := repos[i]
-			backendServer.Repos.Actives[*repo.ID] = new(backend.ArchRepo)
-			backendServer.Repos.Actives[*repo.ID].Hive = new(backend.ArchHive)
-			backendServer.Repos.Actives[*repo.ID].Hive.Blender = new(backend.Blender)
-			backendServer.NewModel(*repo.ID)
-			backendServer.Repos.Actives[*repo.ID].Client = client
-		}
-
-		for i := 0; i < len(repos); i++ {
-			repo := repos[i]
-			bs.AddRepo(*repo.ID, *repo.Organization.Name, *repo.Name)
-		}
-
-		fmt.Println("bs.StreamWebhookEvents() start")
-		bs.StreamWebhookEvents()
-		//go backendServer.Start()
-		fmt.Println("bs.StreamWebhookEvents() end")
-
-		time.Sleep(15 * time.Second)
-		bs.PredictionAccuracy()
-		time.Sleep(15 * time.Second)
+	simulator := replay.Simulator{Interval: *interval, Warmup: *warmup}
+	simulator.Settings.AssignmentPolicy = *policy
+	report, err := simulator.Run(events)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := report.WriteText(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		return
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	if err := report.WriteJSON(file); err != nil {
+		log.Fatal(err)
 	}
 }
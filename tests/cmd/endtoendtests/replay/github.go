@@ -0,0 +1,68 @@
+package replay
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+
+	"core/testutil/fakegithub"
+)
+
+// Mutation is a write request made to the simulated GitHub API.
+type Mutation struct {
+	At     time.Time       `json:"at"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Assignees returns the repo ("owner/name"), issue number and assignees of
+// an add assignees mutation.
+func (m Mutation) Assignees() (string, int, []string, bool) {
+	parts := strings.Split(strings.Trim(m.Path, "/"), "/")
+	if m.Method != "POST" || len(parts) != 6 || parts[0] != "repos" || parts[3] != "issues" || parts[5] != "assignees" {
+		return "", 0, nil, false
+	}
+	number, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return "", 0, nil, false
+	}
+	var body struct {
+		Assignees []string `json:"assignees"`
+	}
+	if err := json.Unmarshal(m.Body, &body); err != nil {
+		return "", 0, nil, false
+	}
+	return parts[1] + "/" + parts[2], number, body.Assignees, true
+}
+
+// mutations returns the mutations received by the fake GitHub in order.
+func mutations(gh *fakegithub.Server) []Mutation {
+	result := []Mutation{}
+	for _, request := range gh.Mutations() {
+		mutation := Mutation{At: request.At, Method: request.Method, Path: request.Path}
+		if len(request.Body) != 0 && json.Valid([]byte(request.Body)) {
+			mutation.Body = json.RawMessage(request.Body)
+		}
+		result = append(result, mutation)
+	}
+	return result
+}
+
+// mirror stores the repository and the issue or pull request of an event in
+// the fake GitHub, so that the mutations made by the pipeline apply to the
+// state of the archive.
+func mirror(gh *fakegithub.Server, id int64, owner, name string, payload map[string]json.RawMessage) {
+	gh.AddRepoWithID(id, owner, name)
+	issue := github.Issue{}
+	if err := json.Unmarshal(payload["issue"], &issue); err == nil && issue.Number != nil {
+		gh.AddIssue(owner, name, issue)
+	}
+	pull := github.PullRequest{}
+	if err := json.Unmarshal(payload["pull_request"], &pull); err == nil && pull.Number != nil {
+		gh.AddPull(owner, name, pull)
+	}
+}
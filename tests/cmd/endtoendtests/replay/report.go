@@ -0,0 +1,106 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Report holds the outcome of a replay.
+type Report struct {
+	Start     time.Time   `json:"start"`
+	End       time.Time   `json:"end"`
+	Events    int         `json:"events"`
+	Mutations []Mutation  `json:"mutations"`
+	Repos     []RepoScore `json:"repos"`
+}
+
+// RepoScore is the assignment accuracy on a repo. Every issue the backend
+// assigned is predicted; it is scored once it is closed with assignees, and
+// correct if the first assignee made by the backend is one of them.
+type RepoScore struct {
+	Repo      string  `json:"repo"`
+	Events    int     `json:"events"`
+	Predicted int     `json:"predicted"`
+	Scored    int     `json:"scored"`
+	Correct   int     `json:"correct"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+func (s *Simulator) report(start time.Time, events int) Report {
+	report := Report{Start: start, End: s.now, Events: events, Mutations: mutations(s.github), Repos: []RepoScore{}}
+	scores := make(map[string]*RepoScore)
+	score := func(repo string) *RepoScore {
+		if _, ok := scores[repo]; !ok {
+			scores[repo] = &RepoScore{Repo: repo}
+		}
+		return scores[repo]
+	}
+	for repo, count := range s.events {
+		score(repo).Events = count
+	}
+
+	predicted := make(map[string]bool)
+	for _, mutation := range report.Mutations {
+		repo, number, assignees, ok := mutation.Assignees()
+		key := issueKey(repo, number)
+		if !ok || len(assignees) == 0 || predicted[key] {
+			continue
+		}
+		predicted[key] = true
+		repoScore := score(repo)
+		repoScore.Predicted++
+		actual := s.resolved[key]
+		if len(actual) == 0 {
+			continue
+		}
+		repoScore.Scored++
+		for _, assignee := range actual {
+			if assignee == assignees[0] {
+				repoScore.Correct++
+				break
+			}
+		}
+	}
+
+	for _, repoScore := range scores {
+		if repoScore.Scored != 0 {
+			repoScore.Accuracy = Round(float64(repoScore.Correct) / float64(repoScore.Scored))
+		}
+		report.Repos = append(report.Repos, *repoScore)
+	}
+	sort.Slice(report.Repos, func(i, j int) bool { return report.Repos[i].Repo < report.Repos[j].Repo })
+	return report
+}
+
+func Round(input float64) float64 {
+	rounded := math.Floor((input*10000.0)+0.5) / 10000.0
+	return rounded
+}
+
+func ToString(number float64) string {
+	return strconv.FormatFloat(number, 'f', 4, 64)
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes a table of the per repo accuracy.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Replayed %d events from %s to %s, %d mutations\n", r.Events, r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339), len(r.Mutations))
+	fmt.Fprintln(tw, "repo\tevents\tpredicted\tscored\tcorrect\taccuracy")
+	for _, repo := range r.Repos {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", repo.Repo, repo.Events, repo.Predicted, repo.Scored, repo.Correct, ToString(repo.Accuracy))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,304 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	language "cloud.google.com/go/language/apiv1"
+	"github.com/google/go-github/github"
+
+	"core/pipeline/backend"
+	"core/pipeline/ingestor"
+	"core/testutil/fakegithub"
+)
+
+// secretKey is the webhook secret of the ingestor outside production.
+const secretKey = ""
+
+// DefaultInterval is the period of the backend Timer.
+const DefaultInterval = 5 * time.Second
+
+// Simulator replays GH Archive events through the real ingestor webhook
+// handler and worker, and the real backend worker, in simulated time. Every
+// component runs in-process and synchronously on embedded storage, with a
+// local fake GitHub API that mirrors the archived issues and records each
+// mutation, so a replay needs neither MySQL nor GitHub and two replays of the
+// same events give the same report.
+type Simulator struct {
+	// Interval is the simulated time between two backend pulldowns;
+	// DefaultInterval if zero.
+	Interval time.Duration
+	// Warmup is how long after the first event issues start being triaged;
+	// the issues opened before are only learned.
+	Warmup time.Duration
+	// Settings are the backend settings of every repo. EnableTriager and
+	// StartTime are set by the simulator.
+	Settings backend.HeuprConfigSettings
+
+	start     time.Time
+	now       time.Time
+	database  *ingestor.MemoryDatabase
+	handler   http.Handler
+	ingestor  ingestor.Worker
+	backend   *backend.Server
+	worker    backend.Worker
+	github    *fakegithub.Server
+	read      int
+	resolvers map[int64]map[string]time.Time
+	resolved  map[string][]string
+	events    map[string]int
+}
+
+// Now returns the simulated time.
+func (s *Simulator) Now() time.Time {
+	return s.now
+}
+
+func (s *Simulator) setup(start time.Time) {
+	// Type labels need the Google Natural Language API.
+	backend.NewLanguageClient = func(ctx context.Context) (*language.Client, error) {
+		return nil, errors.New("language client disabled in replay")
+	}
+	s.start, s.now = start, start
+	s.read = 0
+	s.database = ingestor.NewMemoryDatabase()
+	server := &ingestor.IngestorServer{Database: s.database}
+	s.handler = server.Handler()
+	s.ingestor = ingestor.Worker{Database: s.database}
+	s.backend = &backend.Server{Repos: &backend.ActiveRepos{Actives: make(map[int64]*backend.ArchRepo)}}
+	s.worker = backend.Worker{Repos: s.backend.Repos}
+	s.github = fakegithub.New()
+	s.github.SetClock(s.Now)
+	// A replay can make more requests than an hourly budget allows.
+	s.github.SetRateLimit(math.MaxInt32, math.MaxInt32, start.Add(time.Hour))
+	s.resolvers = make(map[int64]map[string]time.Time)
+	s.resolved = make(map[string][]string)
+	s.events = make(map[string]int)
+}
+
+// Run replays the events, which must be in time order (see ReadArchive).
+func (s *Simulator) Run(events []Event) (Report, error) {
+	if len(events) == 0 {
+		return Report{Mutations: []Mutation{}, Repos: []RepoScore{}}, nil
+	}
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	start := events[0].CreatedAt
+	s.setup(start)
+	defer s.github.Close()
+	next := start.Add(interval)
+	for _, e := range events {
+		if !e.CreatedAt.Before(next) {
+			s.tick(next)
+			next = start.Add((e.CreatedAt.Sub(start)/interval + 1) * interval)
+		}
+		s.now = e.CreatedAt
+		if err := s.deliver(e); err != nil {
+			return Report{}, err
+		}
+	}
+	s.tick(next)
+	return s.report(start, len(events)), nil
+}
+
+// deliver posts the event to the ingestor as a signed webhook and processes
+// the resulting work.
+func (s *Simulator) deliver(e Event) error {
+	payload := map[string]json.RawMessage{}
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return err
+	}
+	r := strings.SplitN(e.Repo.Name, "/", 2)
+	if len(r) != 2 {
+		return errors.New("invalid archive repo name " + e.Repo.Name)
+	}
+	repository, _ := json.Marshal(github.Repository{
+		ID:       &e.Repo.ID,
+		Name:     &r[1],
+		FullName: &e.Repo.Name,
+		Owner:    &github.User{Login: &r[0]},
+	})
+	payload["repository"] = repository
+	if len(e.Actor) != 0 {
+		payload["sender"] = e.Actor
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	s.events[e.Repo.Name]++
+	s.observe(e.Repo.Name, payload)
+	mirror(s.github, e.Repo.ID, r[0], r[1], payload)
+
+	mac := hmac.New(sha1.New, []byte(secretKey))
+	mac.Write(body)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header.Set("X-Github-Event", webhooks[e.Type])
+	req.Header.Set("X-GitHub-Delivery", e.ID)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+	s.handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The handler queues valid events before returning.
+	select {
+	case work := <-ingestor.Workload:
+		s.ingestor.Process(work)
+	default:
+	}
+	return nil
+}
+
+// observe records who resolved each closed issue, to score the assignments.
+func (s *Simulator) observe(repo string, payload map[string]json.RawMessage) {
+	issue := github.Issue{}
+	if err := json.Unmarshal(payload["issue"], &issue); err != nil || issue.Number == nil || issue.ClosedAt == nil {
+		return
+	}
+	assignees := []string{}
+	for _, assignee := range issue.Assignees {
+		assignees = append(assignees, assignee.GetLogin())
+	}
+	if len(assignees) == 0 && issue.Assignee != nil {
+		assignees = append(assignees, issue.Assignee.GetLogin())
+	}
+	s.resolved[issueKey(repo, *issue.Number)] = assignees
+}
+
+func issueKey(repo string, number int) string {
+	return repo + "#" + strconv.Itoa(number)
+}
+
+// tick is a backend pulldown at the given time: the latest state of the
+// issues and pull requests stored since the last pulldown is read the way
+// MemSQL.Read does and processed repo by repo.
+func (s *Simulator) tick(at time.Time) {
+	s.now = at
+	rows := s.database.Events(s.read)
+	if len(rows) == 0 {
+		return
+	}
+	s.read = rows[len(rows)-1].ID
+
+	type key struct {
+		repoID   int64
+		issuesID int64
+		number   int
+	}
+	latest := make(map[key]ingestor.StoredEvent)
+	for _, row := range rows {
+		latest[key{row.RepoID, row.IssuesID, row.Number}] = row
+	}
+	current := []ingestor.StoredEvent{}
+	for _, row := range latest {
+		if row.Action == "opened" || row.Action == "closed" {
+			current = append(current, row)
+		}
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].ID < current[j].ID })
+
+	data := make(map[int64]*backend.RepoData)
+	for _, row := range current {
+		rd, ok := data[row.RepoID]
+		if !ok {
+			rd = &backend.RepoData{RepoID: row.RepoID, Open: []*github.Issue{}, Closed: []*github.Issue{}, Pulls: []*github.PullRequest{}}
+			data[row.RepoID] = rd
+		}
+		decoder := json.NewDecoder(bytes.NewReader(row.Payload))
+		decoder.UseNumber()
+		if row.IsPull {
+			pull := &github.PullRequest{}
+			if decoder.Decode(pull) == nil {
+				rd.Pulls = append(rd.Pulls, pull)
+			}
+			continue
+		}
+		issue := &github.Issue{}
+		if decoder.Decode(issue) != nil {
+			continue
+		}
+		if issue.ClosedAt == nil {
+			rd.Open = append(rd.Open, issue)
+			continue
+		}
+		rd.Closed = append(rd.Closed, issue)
+		s.resolve(row.RepoID, issue)
+	}
+
+	repoIDs := []int64{}
+	for repoID := range data {
+		repoIDs = append(repoIDs, repoID)
+	}
+	sort.Slice(repoIDs, func(i, j int) bool { return repoIDs[i] < repoIDs[j] })
+	for _, repoID := range repoIDs {
+		rd := data[repoID]
+		s.activate(repoID)
+		rd.AssigneeAllocations = s.backend.Repos.Actives[repoID].Workload.Allocations()
+		rd.EligibleAssignees = s.eligible(repoID)
+		rd.Settings = s.settings()
+		s.worker.Process(rd)
+	}
+}
+
+// resolve records the assignees of a closed issue as eligible.
+func (s *Simulator) resolve(repoID int64, issue *github.Issue) {
+	if s.resolvers[repoID] == nil {
+		s.resolvers[repoID] = make(map[string]time.Time)
+	}
+	for _, assignee := range issue.Assignees {
+		s.resolvers[repoID][assignee.GetLogin()] = *issue.ClosedAt
+	}
+}
+
+// eligible returns the assignees of the issues closed in the past six
+// months, with the default cap of ReadEligibleAssignees.
+func (s *Simulator) eligible(repoID int64) map[string]int {
+	eligible := make(map[string]int)
+	since := s.now.AddDate(0, -6, 0)
+	for assignee, closed := range s.resolvers[repoID] {
+		if closed.After(since) {
+			eligible[assignee] = 10
+		}
+	}
+	return eligible
+}
+
+func (s *Simulator) settings() backend.HeuprConfigSettings {
+	settings := s.Settings
+	settings.EnableTriager = true
+	settings.StartTime = s.start.Add(s.Warmup)
+	if settings.IgnoreLabels == nil {
+		settings.IgnoreLabels = make(map[string]bool)
+	}
+	if settings.IgnoreUsers == nil {
+		settings.IgnoreUsers = make(map[string]bool)
+	}
+	return settings
+}
+
+// activate creates the backend repo on its first data, as the activation
+// endpoint does, with the fake GitHub client and the simulated clock.
+func (s *Simulator) activate(repoID int64) {
+	if _, ok := s.backend.Repos.Actives[repoID]; ok {
+		return
+	}
+	s.backend.NewArchRepo(repoID, s.settings())
+	s.backend.NewModel(repoID)
+	repo := s.backend.Repos.Actives[repoID]
+	repo.Client = s.github.Client()
+	repo.Clock = s.Now
+	repo.Labelmaker = nil
+}
@@ -0,0 +1,123 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gzip "github.com/klauspost/pgzip"
+)
+
+var start = time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC)
+
+// archiveIssue returns an issues event of the x-wing repo.
+func archiveIssue(action string, number int, title, assignee string, at time.Duration) map[string]interface{} {
+	issue := map[string]interface{}{
+		"id":         1000 + number,
+		"number":     number,
+		"title":      title,
+		"body":       title + " needs to be fixed before the next sortie",
+		"url":        fmt.Sprint("https://api.github.com/repos/rebellion/x-wing/issues/", number),
+		"state":      "open",
+		"comments":   0,
+		"user":       map[string]interface{}{"login": "Wedge"},
+		"created_at": start.Add(at).Format(time.RFC3339),
+	}
+	if action == "closed" {
+		issue["state"] = "closed"
+		issue["closed_at"] = start.Add(at).Format(time.RFC3339)
+		issue["created_at"] = start.Add(at - time.Hour).Format(time.RFC3339)
+		issue["assignee"] = map[string]interface{}{"login": assignee}
+		issue["assignees"] = []interface{}{map[string]interface{}{"login": assignee}}
+	}
+	return map[string]interface{}{
+		"id":         "event",
+		"type":       "IssuesEvent",
+		"actor":      map[string]interface{}{"login": "Wedge"},
+		"repo":       map[string]interface{}{"id": 1977, "name": "rebellion/x-wing"},
+		"payload":    map[string]interface{}{"action": action, "issue": issue},
+		"created_at": start.Add(at).Format(time.RFC3339),
+	}
+}
+
+func writeArchive(t *testing.T, dir string, events ...map[string]interface{}) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	encoder := json.NewEncoder(writer)
+	// Other event types and repos are skipped.
+	encoder.Encode(map[string]interface{}{"type": "WatchEvent", "repo": map[string]interface{}{"id": 1, "name": "empire/tie"}})
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.Close()
+	if err := ioutil.WriteFile(filepath.Join(dir, "1977-05-25-0.json.gz"), buffer.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimulator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeArchive(t, dir,
+		// Written out of order; the archive is replayed in time order.
+		archiveIssue("closed", 4, "Hyperdrive motivator broken", "Chewbacca", 5*time.Hour),
+		archiveIssue("closed", 1, "Targeting computer broken", "Luke", time.Hour),
+		archiveIssue("closed", 2, "Targeting computer drifting", "Luke", time.Hour),
+		archiveIssue("closed", 3, "Hyperdrive motivator stuck", "Chewbacca", time.Hour),
+		archiveIssue("opened", 4, "Hyperdrive motivator broken", "", 3*time.Hour),
+		archiveIssue("opened", 5, "Targeting computer broken again", "", 3*time.Hour),
+	)
+
+	events, err := ReadArchive(dir, "rebellion/x-wing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 6 || !events[0].CreatedAt.Equal(start.Add(time.Hour)) || !events[5].CreatedAt.Equal(start.Add(5*time.Hour)) {
+		t.Fatal("\nARCHIVE NOT READ IN ORDER", "\nACTUAL:   ", events)
+	}
+
+	run := func() Report {
+		simulator := Simulator{Warmup: 90 * time.Minute}
+		report, err := simulator.Run(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	report := run()
+
+	assigned := map[int]string{}
+	for _, mutation := range report.Mutations {
+		if repo, number, assignees, ok := mutation.Assignees(); ok && repo == "rebellion/x-wing" && len(assignees) == 1 {
+			assigned[number] = assignees[0]
+			if !mutation.At.Equal(start.Add(3*time.Hour + DefaultInterval)) {
+				t.Error("\nMUTATION NOT AT SIMULATED TIME", "\nEXPECTED: ", start.Add(3*time.Hour+DefaultInterval), "\nACTUAL:   ", mutation.At)
+			}
+		}
+	}
+	if assigned[4] != "Chewbacca" || assigned[5] != "Luke" || len(assigned) != 2 {
+		t.Error("\nUNEXPECTED ASSIGNMENTS", "\nEXPECTED: ", map[int]string{4: "Chewbacca", 5: "Luke"}, "\nACTUAL:   ", assigned)
+	}
+
+	expected := RepoScore{Repo: "rebellion/x-wing", Events: 6, Predicted: 2, Scored: 1, Correct: 1, Accuracy: 1}
+	if len(report.Repos) != 1 || report.Repos[0] != expected {
+		t.Error("\nUNEXPECTED SCORE", "\nEXPECTED: ", expected, "\nACTUAL:   ", report.Repos)
+	}
+
+	var first, second bytes.Buffer
+	report.WriteJSON(&first)
+	run().WriteJSON(&second)
+	if first.String() != second.String() {
+		t.Error("\nREPLAY NOT DETERMINISTIC", "\nEXPECTED: ", first.String(), "\nACTUAL:   ", second.String())
+	}
+}
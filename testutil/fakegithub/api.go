@@ -0,0 +1,802 @@
+package fakegithub
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// defaultPerPage is the page size when a list request sets none.
+const defaultPerPage = 30
+
+// route is an API endpoint. Pattern segments starting with ":" match any
+// path segment and are passed to the handler by name.
+type route struct {
+	method  string
+	pattern []string
+	handle  func(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte)
+}
+
+var routes = []route{
+	{"GET", split("rate_limit"), getRateLimit},
+	{"GET", split("repositories/:id"), getRepositoryByID},
+	{"GET", split("user/installations"), listInstallations},
+	{"GET", split("user/installations/:id/repositories"), listInstallationRepos},
+	{"GET", split("installation/repositories"), listInstallationRepos},
+	{"GET", split("repos/:owner/:repo"), getRepository},
+	{"GET", split("repos/:owner/:repo/issues"), listIssues},
+	{"POST", split("repos/:owner/:repo/issues"), createIssue},
+	{"GET", split("repos/:owner/:repo/issues/comments/:id"), getComment},
+	{"PATCH", split("repos/:owner/:repo/issues/comments/:id"), editComment},
+	{"GET", split("repos/:owner/:repo/issues/comments/:id/reactions"), listCommentReactions},
+	{"GET", split("repos/:owner/:repo/issues/:number"), getIssue},
+	{"PATCH", split("repos/:owner/:repo/issues/:number"), editIssue},
+	{"POST", split("repos/:owner/:repo/issues/:number/assignees"), addAssignees},
+	{"DELETE", split("repos/:owner/:repo/issues/:number/assignees"), removeAssignees},
+	{"GET", split("repos/:owner/:repo/issues/:number/labels"), listIssueLabels},
+	{"POST", split("repos/:owner/:repo/issues/:number/labels"), addIssueLabels},
+	{"DELETE", split("repos/:owner/:repo/issues/:number/labels/:name"), removeIssueLabel},
+	{"GET", split("repos/:owner/:repo/issues/:number/comments"), listComments},
+	{"POST", split("repos/:owner/:repo/issues/:number/comments"), createComment},
+	{"GET", split("repos/:owner/:repo/pulls"), listPulls},
+	{"GET", split("repos/:owner/:repo/pulls/:number"), getPull},
+	{"GET", split("repos/:owner/:repo/pulls/:number/files"), listPullFiles},
+	{"GET", split("repos/:owner/:repo/collaborators/:login/permission"), getPermission},
+	{"GET", split("repos/:owner/:repo/labels"), listLabels},
+	{"POST", split("repos/:owner/:repo/labels"), createLabel},
+	{"GET", split("repos/:owner/:repo/labels/:name"), getLabel},
+	{"GET", split("repos/:owner/:repo/contents/*path"), getContents},
+}
+
+func split(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// match returns the parameters of the path if it matches the pattern. A
+// final "*name" segment matches the rest of the path.
+func match(pattern, path []string) (map[string]string, bool) {
+	params := make(map[string]string)
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, "*") && i == len(pattern)-1 && i < len(path) {
+			params[segment[1:]] = strings.Join(path[i:], "/")
+			return params, true
+		}
+		if i >= len(path) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			params[segment[1:]] = path[i]
+		case segment != path[i]:
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(path)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body := []byte{}
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+	r.ParseForm()
+
+	s.Lock()
+	s.requests = append(s.requests, Request{At: s.now(), Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Body: string(body)})
+	limited := s.remaining <= 0
+	// The rate limit endpoint does not count against the budget.
+	if !limited && r.URL.Path != "/rate_limit" {
+		s.remaining--
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(s.remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(s.reset.Unix(), 10))
+	s.Unlock()
+
+	if limited && r.URL.Path != "/rate_limit" {
+		writeError(w, http.StatusForbidden, "API rate limit exceeded for installation.")
+		return
+	}
+	path := split(r.URL.Path)
+	for _, rt := range routes {
+		if rt.method != r.Method {
+			continue
+		}
+		if params, ok := match(rt.pattern, path); ok {
+			rt.handle(s, w, r, params, body)
+			s.flush()
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message":           message,
+		"documentation_url": "https://developer.github.com/v3",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+// page returns the bounds of the requested page of n items and sets the
+// Link header of the neighbouring pages.
+func page(w http.ResponseWriter, r *http.Request, n int) (int, int) {
+	perPage, _ := strconv.Atoi(r.Form.Get("per_page"))
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	current, _ := strconv.Atoi(r.Form.Get("page"))
+	if current <= 0 {
+		current = 1
+	}
+	last := (n + perPage - 1) / perPage
+	if last == 0 {
+		last = 1
+	}
+	link := func(p int, rel string) string {
+		query := r.URL.Query()
+		query.Set("page", itoa(p))
+		query.Set("per_page", itoa(perPage))
+		return fmt.Sprintf(`<http://%s%s?%s>; rel="%s"`, r.Host, r.URL.Path, query.Encode(), rel)
+	}
+	links := []string{}
+	if current < last {
+		links = append(links, link(current+1, "next"), link(last, "last"))
+	}
+	if current > 1 {
+		links = append(links, link(1, "first"), link(current-1, "prev"))
+	}
+	if len(links) != 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	start := (current - 1) * perPage
+	if start > n {
+		start = n
+	}
+	end := start + perPage
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+func getRateLimit(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	rate := github.Rate{Limit: s.limit, Remaining: s.remaining, Reset: github.Timestamp{Time: s.reset}}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resources": map[string]interface{}{"core": rate},
+		"rate":      rate,
+	})
+}
+
+func getRepositoryByID(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	id, _ := strconv.ParseInt(params["id"], 10, 64)
+	s.Lock()
+	defer s.Unlock()
+	fullName, ok := s.ids[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.repos[fullName].repository)
+}
+
+func getRepository(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.repos[params["owner"]+"/"+params["repo"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, repo.repository)
+}
+
+func listInstallations(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	ids := []int64{}
+	for id := range s.installations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	start, end := page(w, r, len(ids))
+	installations := []map[string]interface{}{}
+	for _, id := range ids[start:end] {
+		installations = append(installations, map[string]interface{}{"id": id, "app_id": s.appIDs[id]})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total_count": len(ids), "installations": installations})
+}
+
+// listInstallationRepos lists the repositories of the installation in the
+// path, or of every installation for the installation token endpoint.
+func listInstallationRepos(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	names := []string{}
+	if id, ok := params["id"]; ok {
+		installationID, _ := strconv.ParseInt(id, 10, 64)
+		repos, ok := s.installations[installationID]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		names = append(names, repos...)
+	} else {
+		for _, repos := range s.installations {
+			names = append(names, repos...)
+		}
+		sort.Strings(names)
+	}
+	start, end := page(w, r, len(names))
+	repositories := []*github.Repository{}
+	for _, name := range names[start:end] {
+		repositories = append(repositories, s.repos[name].repository)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total_count": len(names), "repositories": repositories})
+}
+
+// lookup returns the repository of the path, answering a 404 if it does not
+// exist. The server must be locked.
+func (s *Server) lookup(w http.ResponseWriter, params map[string]string) (*repo, bool) {
+	repo, ok := s.repos[params["owner"]+"/"+params["repo"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+	return repo, ok
+}
+
+// lookupIssue returns the issue of the path, answering a 404 if it does not
+// exist. The server must be locked.
+func (s *Server) lookupIssue(w http.ResponseWriter, params map[string]string) (*repo, *github.Issue, bool) {
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return nil, nil, false
+	}
+	number, _ := strconv.Atoi(params["number"])
+	issue, ok := repo.issues[number]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+	return repo, issue, ok
+}
+
+func states(r *http.Request) string {
+	state := r.Form.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	return state
+}
+
+// listIssues lists the issues newest first unless the direction is asc.
+// Unlike GitHub, pull requests are not listed as issues.
+func listIssues(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	state := states(r)
+	issues := []*github.Issue{}
+	for _, issue := range repo.issues {
+		if state == "all" || issue.GetState() == state {
+			issues = append(issues, issue)
+		}
+	}
+	ascending := r.Form.Get("direction") == "asc"
+	sort.Slice(issues, func(i, j int) bool { return (*issues[i].Number < *issues[j].Number) == ascending })
+	start, end := page(w, r, len(issues))
+	writeJSON(w, http.StatusOK, issues[start:end])
+}
+
+func createIssue(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	request := github.IssueRequest{}
+	if err := json.Unmarshal(body, &request); err != nil || request.Title == nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.lookup(w, params); !ok {
+		return
+	}
+	issue := github.Issue{Title: request.Title, Body: request.Body, User: &github.User{Login: github.String(Login)}}
+	if request.Labels != nil {
+		for _, name := range *request.Labels {
+			issue.Labels = append(issue.Labels, github.Label{Name: github.String(name)})
+		}
+	}
+	if request.Assignees != nil {
+		for _, login := range *request.Assignees {
+			issue.Assignees = append(issue.Assignees, &github.User{Login: github.String(login)})
+		}
+	}
+	stored := s.addIssue(params["owner"], params["repo"], issue)
+	s.queueIssue("opened", stored, nil)
+	writeJSON(w, http.StatusCreated, stored)
+}
+
+func getIssue(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	if _, issue, ok := s.lookupIssue(w, params); ok {
+		writeJSON(w, http.StatusOK, issue)
+	}
+}
+
+func editIssue(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	request := github.IssueRequest{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	repo, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	if request.Title != nil || request.Body != nil {
+		if request.Title != nil {
+			issue.Title = request.Title
+		}
+		if request.Body != nil {
+			issue.Body = request.Body
+		}
+		s.queueIssue("edited", issue, nil)
+	}
+	if request.Labels != nil {
+		issue.Labels = nil
+		for _, name := range *request.Labels {
+			issue.Labels = append(issue.Labels, *repo.addLabel(name))
+		}
+	}
+	if request.Assignees != nil {
+		issue.Assignees = nil
+		for _, login := range *request.Assignees {
+			issue.Assignees = append(issue.Assignees, &github.User{Login: github.String(login)})
+		}
+		issue.Assignee = nil
+		if len(issue.Assignees) != 0 {
+			issue.Assignee = issue.Assignees[0]
+		}
+	}
+	if request.State != nil && *request.State != issue.GetState() {
+		issue.State = request.State
+		now := s.now()
+		switch *request.State {
+		case "closed":
+			issue.ClosedAt = &now
+			issue.ClosedBy = &github.User{Login: github.String(Login)}
+			s.queueIssue("closed", issue, nil)
+		case "open":
+			issue.ClosedAt = nil
+			issue.ClosedBy = nil
+			s.queueIssue("reopened", issue, nil)
+		}
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+func logins(body []byte) ([]string, bool) {
+	request := struct {
+		Assignees []string `json:"assignees"`
+	}{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false
+	}
+	return request.Assignees, true
+}
+
+func addAssignees(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	assignees, ok := logins(body)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	for _, login := range assignees {
+		if hasAssignee(issue, login) {
+			continue
+		}
+		assignee := &github.User{Login: github.String(login)}
+		issue.Assignees = append(issue.Assignees, assignee)
+		s.queueIssue("assigned", issue, map[string]interface{}{"assignee": assignee})
+	}
+	if issue.Assignee == nil && len(issue.Assignees) != 0 {
+		issue.Assignee = issue.Assignees[0]
+	}
+	writeJSON(w, http.StatusCreated, issue)
+}
+
+func hasAssignee(issue *github.Issue, login string) bool {
+	for _, assignee := range issue.Assignees {
+		if strings.EqualFold(assignee.GetLogin(), login) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeAssignees(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	assignees, ok := logins(body)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	_, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	for _, login := range assignees {
+		kept := []*github.User{}
+		for _, assignee := range issue.Assignees {
+			if strings.EqualFold(assignee.GetLogin(), login) {
+				s.queueIssue("unassigned", issue, map[string]interface{}{"assignee": assignee})
+				continue
+			}
+			kept = append(kept, assignee)
+		}
+		issue.Assignees = kept
+	}
+	issue.Assignee = nil
+	if len(issue.Assignees) != 0 {
+		issue.Assignee = issue.Assignees[0]
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+func listIssueLabels(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	if _, issue, ok := s.lookupIssue(w, params); ok {
+		labels := append([]github.Label{}, issue.Labels...)
+		start, end := page(w, r, len(labels))
+		writeJSON(w, http.StatusOK, labels[start:end])
+	}
+}
+
+func addIssueLabels(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	names := []string{}
+	if err := json.Unmarshal(body, &names); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	repo, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	for _, name := range names {
+		if hasLabel(issue, name) {
+			continue
+		}
+		label := repo.addLabel(name)
+		issue.Labels = append(issue.Labels, *label)
+		s.queueIssue("labeled", issue, map[string]interface{}{"label": label})
+	}
+	writeJSON(w, http.StatusOK, append([]github.Label{}, issue.Labels...))
+}
+
+func hasLabel(issue *github.Issue, name string) bool {
+	for _, label := range issue.Labels {
+		if strings.EqualFold(label.GetName(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeIssueLabel(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	_, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	if !hasLabel(issue, params["name"]) {
+		writeError(w, http.StatusNotFound, "Label does not exist")
+		return
+	}
+	kept := []github.Label{}
+	for _, label := range issue.Labels {
+		if strings.EqualFold(label.GetName(), params["name"]) {
+			removed := label
+			s.queueIssue("unlabeled", issue, map[string]interface{}{"label": &removed})
+			continue
+		}
+		kept = append(kept, label)
+	}
+	issue.Labels = kept
+	writeJSON(w, http.StatusOK, kept)
+}
+
+func listComments(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	comments := append([]*github.IssueComment{}, repo.comments[*issue.Number]...)
+	start, end := page(w, r, len(comments))
+	writeJSON(w, http.StatusOK, comments[start:end])
+}
+
+func createComment(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	request := github.IssueComment{}
+	if err := json.Unmarshal(body, &request); err != nil || request.Body == nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	repo, issue, ok := s.lookupIssue(w, params)
+	if !ok {
+		return
+	}
+	comment := s.addComment(repo, issue, Login, *request.Body)
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// addComment stores a comment and queues its webhook. The server must be
+// locked.
+func (s *Server) addComment(repo *repo, issue *github.Issue, login, body string) *github.IssueComment {
+	now := s.now()
+	comment := &github.IssueComment{
+		ID:        github.Int64(s.id()),
+		Body:      github.String(body),
+		User:      &github.User{Login: github.String(login)},
+		CreatedAt: &now,
+		UpdatedAt: &now,
+		IssueURL:  issue.URL,
+	}
+	repo.comments[*issue.Number] = append(repo.comments[*issue.Number], comment)
+	issue.Comments = github.Int(len(repo.comments[*issue.Number]))
+	s.queue("issue_comment", repo, login, map[string]interface{}{"action": "created", "issue": issue, "comment": comment})
+	return comment
+}
+
+// findComment returns the comment of the path, answering a 404 if it does
+// not exist. The server must be locked.
+func (s *Server) findComment(w http.ResponseWriter, params map[string]string) (*repo, *github.IssueComment, bool) {
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return nil, nil, false
+	}
+	id, _ := strconv.ParseInt(params["id"], 10, 64)
+	for _, comments := range repo.comments {
+		for _, comment := range comments {
+			if comment.GetID() == id {
+				return repo, comment, true
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+	return nil, nil, false
+}
+
+func getComment(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	if _, comment, ok := s.findComment(w, params); ok {
+		writeJSON(w, http.StatusOK, comment)
+	}
+}
+
+func editComment(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	request := github.IssueComment{}
+	if err := json.Unmarshal(body, &request); err != nil || request.Body == nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if _, comment, ok := s.findComment(w, params); ok {
+		now := s.now()
+		comment.Body = request.Body
+		comment.UpdatedAt = &now
+		writeJSON(w, http.StatusOK, comment)
+	}
+}
+
+func listCommentReactions(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	if repo, comment, ok := s.findComment(w, params); ok {
+		reactions := append([]*github.Reaction{}, repo.reactions[comment.GetID()]...)
+		start, end := page(w, r, len(reactions))
+		writeJSON(w, http.StatusOK, reactions[start:end])
+	}
+}
+
+func getPermission(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	permission, ok := repo.permissions[params["login"]]
+	if !ok {
+		permission = "read"
+	}
+	writeJSON(w, http.StatusOK, github.RepositoryPermissionLevel{
+		Permission: github.String(permission),
+		User:       &github.User{Login: github.String(params["login"])},
+	})
+}
+
+// listPulls lists the pull requests newest first unless the direction is asc.
+func listPulls(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	state := states(r)
+	pulls := []*github.PullRequest{}
+	for _, pull := range repo.pulls {
+		if state == "all" || pull.GetState() == state {
+			pulls = append(pulls, pull)
+		}
+	}
+	ascending := r.Form.Get("direction") == "asc"
+	sort.Slice(pulls, func(i, j int) bool { return (*pulls[i].Number < *pulls[j].Number) == ascending })
+	start, end := page(w, r, len(pulls))
+	writeJSON(w, http.StatusOK, pulls[start:end])
+}
+
+func getPull(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	number, _ := strconv.Atoi(params["number"])
+	pull, ok := repo.pulls[number]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, pull)
+}
+
+// listPullFiles lists every file of the default branch as changed by the
+// pull request, which is enough for the file based features.
+func listPullFiles(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	number, _ := strconv.Atoi(params["number"])
+	if _, ok := repo.pulls[number]; !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	paths := []string{}
+	for path := range repo.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	start, end := page(w, r, len(paths))
+	files := []*github.CommitFile{}
+	for _, path := range paths[start:end] {
+		files = append(files, &github.CommitFile{Filename: github.String(path), Status: github.String("modified")})
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func listLabels(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	names := []string{}
+	for name := range repo.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	start, end := page(w, r, len(names))
+	labels := []*github.Label{}
+	for _, name := range names[start:end] {
+		labels = append(labels, repo.labels[name])
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func createLabel(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	request := github.Label{}
+	if err := json.Unmarshal(body, &request); err != nil || request.Name == nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	if _, ok := repo.labels[*request.Name]; ok {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	label := repo.addLabel(*request.Name)
+	if request.Color != nil {
+		label.Color = request.Color
+	}
+	label.Description = request.Description
+	writeJSON(w, http.StatusCreated, label)
+}
+
+func getLabel(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	label, ok := repo.labels[params["name"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, label)
+}
+
+func getContents(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string, body []byte) {
+	s.Lock()
+	defer s.Unlock()
+	repo, ok := s.lookup(w, params)
+	if !ok {
+		return
+	}
+	content, ok := repo.files[params["path"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, github.RepositoryContent{
+		Type:     github.String("file"),
+		Name:     github.String(params["path"][strings.LastIndex(params["path"], "/")+1:]),
+		Path:     github.String(params["path"]),
+		Encoding: github.String("base64"),
+		Size:     github.Int(len(content)),
+		Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+	})
+}
@@ -0,0 +1,447 @@
+// Package fakegithub is an in-memory GitHub API for tests.
+//
+// A Server holds repositories, issues, pull requests, labels, comments, files
+// and installations, serves them through the REST endpoints used by the
+// pipeline and applies the mutations made through them, so that tests can
+// assert the resulting state instead of the requests. Every response carries
+// rate limit headers and a request fails with a rate limit error once the
+// budget set with SetRateLimit is spent. Changes can be delivered as signed
+// webhooks to a handler such as the ingestor's, which makes pipeline tests
+// end-to-end without network.
+//
+//	gh := fakegithub.New()
+//	defer gh.Close()
+//	gh.AddRepo("rebellion", "x-wing")
+//	client := gh.Client()
+package fakegithub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// DefaultRateLimit is the hourly request budget of an installation.
+const DefaultRateLimit = 5000
+
+// Login is the sender of the webhooks emitted for mutations made through
+// the API.
+const Login = "heupr[bot]"
+
+// Request is a request received by the server. At is the time of the
+// server clock (see SetClock) when it was received.
+type Request struct {
+	At     time.Time
+	Method string
+	Path   string
+	Query  url.Values
+	Body   string
+}
+
+type repo struct {
+	repository  *github.Repository
+	issues      map[int]*github.Issue
+	pulls       map[int]*github.PullRequest
+	labels      map[string]*github.Label
+	comments    map[int][]*github.IssueComment
+	reactions   map[int64][]*github.Reaction
+	permissions map[string]string
+	files       map[string]string
+}
+
+// Server is a fake GitHub. It is safe for concurrent use.
+type Server struct {
+	sync.Mutex
+	server        *httptest.Server
+	repos         map[string]*repo
+	ids           map[int64]string
+	installations map[int64][]string
+	appIDs        map[int64]int
+	requests      []Request
+	nextID        int64
+	limit         int
+	remaining     int
+	reset         time.Time
+	webhook       http.Handler
+	secret        []byte
+	deliveries    []Delivery
+	pending       []Delivery
+	now           func() time.Time
+}
+
+// New starts a fake GitHub on a local port.
+func New() *Server {
+	s := &Server{
+		repos:         make(map[string]*repo),
+		ids:           make(map[int64]string),
+		installations: make(map[int64][]string),
+		appIDs:        make(map[int64]int),
+		requests:      []Request{},
+		deliveries:    []Delivery{},
+		limit:         DefaultRateLimit,
+		remaining:     DefaultRateLimit,
+		reset:         time.Now().Add(time.Hour),
+		now:           time.Now,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// URL returns the base URL of the API, with a trailing slash.
+func (s *Server) URL() string {
+	return s.server.URL + "/"
+}
+
+// Client returns a client of the fake.
+func (s *Server) Client() *github.Client {
+	client := github.NewClient(nil)
+	s.Configure(client)
+	return client
+}
+
+// Configure points an existing client, e.g. an authenticated one, to the
+// fake.
+func (s *Server) Configure(client *github.Client) {
+	base, _ := url.Parse(s.URL())
+	client.BaseURL = base
+	client.UploadURL = base
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetClock sets the time used for timestamps and requests; time.Now by
+// default.
+func (s *Server) SetClock(now func() time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	s.now = now
+}
+
+// SetRateLimit sets the request budget and when it resets.
+func (s *Server) SetRateLimit(limit, remaining int, reset time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	s.limit, s.remaining, s.reset = limit, remaining, reset
+}
+
+// RateLimit returns the remaining request budget.
+func (s *Server) RateLimit() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.remaining
+}
+
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// AddRepo creates a repository with the next free ID.
+func (s *Server) AddRepo(owner, name string) *github.Repository {
+	s.Lock()
+	defer s.Unlock()
+	return copyRepository(s.addRepo(owner, name, s.id()).repository)
+}
+
+// AddRepoWithID creates a repository with the given ID.
+func (s *Server) AddRepoWithID(id int64, owner, name string) *github.Repository {
+	s.Lock()
+	defer s.Unlock()
+	return copyRepository(s.addRepo(owner, name, id).repository)
+}
+
+func (s *Server) addRepo(owner, name string, id int64) *repo {
+	fullName := owner + "/" + name
+	if r, ok := s.repos[fullName]; ok {
+		return r
+	}
+	r := &repo{
+		repository: &github.Repository{
+			ID:            github.Int64(id),
+			Name:          github.String(name),
+			FullName:      github.String(fullName),
+			Owner:         &github.User{Login: github.String(owner)},
+			DefaultBranch: github.String("master"),
+			URL:           github.String(s.URL() + "repos/" + fullName),
+		},
+		issues:      make(map[int]*github.Issue),
+		pulls:       make(map[int]*github.PullRequest),
+		labels:      make(map[string]*github.Label),
+		comments:    make(map[int][]*github.IssueComment),
+		reactions:   make(map[int64][]*github.Reaction),
+		permissions: make(map[string]string),
+		files:       make(map[string]string),
+	}
+	s.repos[fullName] = r
+	s.ids[id] = fullName
+	return r
+}
+
+// repo returns the repository, creating it if needed.
+func (s *Server) repo(owner, name string) *repo {
+	return s.addRepo(owner, name, s.id())
+}
+
+// AddIssue stores an issue. The ID, number, state, user, URL and creation
+// time are filled in when missing; the number follows the highest issue or
+// pull request number of the repository.
+func (s *Server) AddIssue(owner, name string, issue github.Issue) *github.Issue {
+	s.Lock()
+	defer s.Unlock()
+	return copyIssue(s.addIssue(owner, name, issue))
+}
+
+func (s *Server) addIssue(owner, name string, issue github.Issue) *github.Issue {
+	r := s.repo(owner, name)
+	stored := copyIssue(&issue)
+	if stored.ID == nil {
+		stored.ID = github.Int64(s.id())
+	}
+	if stored.Number == nil {
+		stored.Number = github.Int(r.nextNumber())
+	}
+	if stored.State == nil {
+		stored.State = github.String("open")
+		if stored.ClosedAt != nil {
+			stored.State = github.String("closed")
+		}
+	}
+	if stored.User == nil {
+		stored.User = &github.User{Login: github.String(owner)}
+	}
+	if stored.URL == nil {
+		stored.URL = github.String(s.URL() + "repos/" + owner + "/" + name + "/issues/" + itoa(*stored.Number))
+	}
+	if stored.CreatedAt == nil {
+		now := s.now()
+		stored.CreatedAt = &now
+	}
+	if stored.Comments == nil {
+		stored.Comments = github.Int(0)
+	}
+	stored.Repository = copyRepository(r.repository)
+	r.issues[*stored.Number] = stored
+	for _, label := range stored.Labels {
+		if label.Name != nil {
+			r.addLabel(*label.Name)
+		}
+	}
+	return stored
+}
+
+// AddPull stores a pull request, filling in the same fields as AddIssue and
+// the base repository.
+func (s *Server) AddPull(owner, name string, pull github.PullRequest) *github.PullRequest {
+	s.Lock()
+	defer s.Unlock()
+	r := s.repo(owner, name)
+	stored := pull
+	if stored.ID == nil {
+		stored.ID = github.Int64(s.id())
+	}
+	if stored.Number == nil {
+		stored.Number = github.Int(r.nextNumber())
+	}
+	if stored.State == nil {
+		stored.State = github.String("open")
+		if stored.ClosedAt != nil {
+			stored.State = github.String("closed")
+		}
+	}
+	if stored.User == nil {
+		stored.User = &github.User{Login: github.String(owner)}
+	}
+	if stored.CreatedAt == nil {
+		now := s.now()
+		stored.CreatedAt = &now
+	}
+	if stored.Base == nil {
+		stored.Base = &github.PullRequestBranch{Ref: r.repository.DefaultBranch}
+	}
+	stored.Base.Repo = copyRepository(r.repository)
+	r.pulls[*stored.Number] = &stored
+	result := stored
+	return &result
+}
+
+func (r *repo) nextNumber() int {
+	number := 0
+	for n := range r.issues {
+		if n > number {
+			number = n
+		}
+	}
+	for n := range r.pulls {
+		if n > number {
+			number = n
+		}
+	}
+	return number + 1
+}
+
+// AddLabel creates a repository label.
+func (s *Server) AddLabel(owner, name, label string) {
+	s.Lock()
+	defer s.Unlock()
+	s.repo(owner, name).addLabel(label)
+}
+
+func (r *repo) addLabel(name string) *github.Label {
+	if label, ok := r.labels[name]; ok {
+		return label
+	}
+	label := &github.Label{Name: github.String(name), Color: github.String("ededed")}
+	r.labels[name] = label
+	return label
+}
+
+// AddReaction adds a reaction (e.g. "+1") by the user to an issue comment.
+func (s *Server) AddReaction(owner, name string, commentID int64, login, content string) {
+	s.Lock()
+	defer s.Unlock()
+	r := s.repo(owner, name)
+	r.reactions[commentID] = append(r.reactions[commentID], &github.Reaction{
+		ID:      github.Int64(s.id()),
+		User:    &github.User{Login: github.String(login)},
+		Content: github.String(content),
+	})
+}
+
+// SetPermission sets the permission level ("admin", "write", "read" or
+// "none") of a user on a repository; users default to "read".
+func (s *Server) SetPermission(owner, name, login, permission string) {
+	s.Lock()
+	defer s.Unlock()
+	s.repo(owner, name).permissions[login] = permission
+}
+
+// AddFile stores a file of the default branch, served by the contents API.
+func (s *Server) AddFile(owner, name, path, content string) {
+	s.Lock()
+	defer s.Unlock()
+	s.repo(owner, name).files[strings.TrimPrefix(path, "/")] = content
+}
+
+// RemoveFile deletes a file of the default branch.
+func (s *Server) RemoveFile(owner, name, path string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.repo(owner, name).files, strings.TrimPrefix(path, "/"))
+}
+
+// AddInstallation registers an app installation with access to the
+// repositories ("owner/name"), which are created if needed.
+func (s *Server) AddInstallation(id int64, appID int, repos ...string) {
+	s.Lock()
+	defer s.Unlock()
+	for _, fullName := range repos {
+		r := strings.SplitN(fullName, "/", 2)
+		s.repo(r[0], r[1])
+	}
+	s.installations[id] = append(s.installations[id], repos...)
+	s.appIDs[id] = appID
+}
+
+// Issue returns a copy of the current state of an issue.
+func (s *Server) Issue(owner, name string, number int) (*github.Issue, bool) {
+	s.Lock()
+	defer s.Unlock()
+	r, ok := s.repos[owner+"/"+name]
+	if !ok || r.issues[number] == nil {
+		return nil, false
+	}
+	return copyIssue(r.issues[number]), true
+}
+
+// Assignees returns the logins assigned to an issue, in order.
+func (s *Server) Assignees(owner, name string, number int) []string {
+	logins := []string{}
+	if issue, ok := s.Issue(owner, name, number); ok {
+		for _, assignee := range issue.Assignees {
+			logins = append(logins, assignee.GetLogin())
+		}
+	}
+	return logins
+}
+
+// Labels returns the label names of an issue, in order.
+func (s *Server) Labels(owner, name string, number int) []string {
+	names := []string{}
+	if issue, ok := s.Issue(owner, name, number); ok {
+		for _, label := range issue.Labels {
+			names = append(names, label.GetName())
+		}
+	}
+	return names
+}
+
+// Comments returns the comment bodies of an issue, in order.
+func (s *Server) Comments(owner, name string, number int) []string {
+	s.Lock()
+	defer s.Unlock()
+	bodies := []string{}
+	if r, ok := s.repos[owner+"/"+name]; ok {
+		for _, comment := range r.comments[number] {
+			bodies = append(bodies, comment.GetBody())
+		}
+	}
+	return bodies
+}
+
+// RepoLabels returns the label names of a repository, sorted.
+func (s *Server) RepoLabels(owner, name string) []string {
+	s.Lock()
+	defer s.Unlock()
+	names := []string{}
+	if r, ok := s.repos[owner+"/"+name]; ok {
+		for label := range r.labels {
+			names = append(names, label)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Requests returns every request received, in order.
+func (s *Server) Requests() []Request {
+	s.Lock()
+	defer s.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Mutations returns the requests that were not reads.
+func (s *Server) Mutations() []Request {
+	mutations := []Request{}
+	for _, request := range s.Requests() {
+		if request.Method != "GET" && request.Method != "HEAD" {
+			mutations = append(mutations, request)
+		}
+	}
+	return mutations
+}
+
+func copyRepository(repository *github.Repository) *github.Repository {
+	if repository == nil {
+		return nil
+	}
+	c := *repository
+	return &c
+}
+
+// copyIssue copies the issue and its slices so callers cannot change the
+// stored state.
+func copyIssue(issue *github.Issue) *github.Issue {
+	c := *issue
+	c.Assignees = append([]*github.User(nil), issue.Assignees...)
+	c.Labels = append([]github.Label(nil), issue.Labels...)
+	return &c
+}
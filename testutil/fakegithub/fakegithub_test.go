@@ -0,0 +1,223 @@
+package fakegithub
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestIssues(t *testing.T) {
+	gh := New()
+	defer gh.Close()
+	client := gh.Client()
+	ctx := context.Background()
+	yavin := time.Date(1977, 5, 25, 0, 0, 0, 0, time.UTC)
+	gh.SetClock(func() time.Time { return yavin })
+
+	gh.AddRepoWithID(1977, "rebellion", "x-wing")
+	for i := 0; i < 5; i++ {
+		gh.AddIssue("rebellion", "x-wing", github.Issue{Title: github.String("Targeting computer broken")})
+	}
+	gh.AddPull("rebellion", "x-wing", github.PullRequest{Title: github.String("Recalibrate targeting computer")})
+	gh.CloseIssue("rebellion", "x-wing", 5, "Luke")
+
+	repo, _, err := client.Repositories.GetByID(ctx, 1977)
+	if err != nil || repo.GetFullName() != "rebellion/x-wing" {
+		t.Error("\nREPOSITORY NOT FOUND BY ID", "\nEXPECTED: ", "rebellion/x-wing", "\nACTUAL:   ", repo.GetFullName(), err)
+	}
+
+	numbers := []int{}
+	opts := &github.IssueListByRepoOptions{State: "all", ListOptions: github.ListOptions{PerPage: 2}}
+	for {
+		issues, resp, err := client.Issues.ListByRepo(ctx, "rebellion", "x-wing", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, issue := range issues {
+			numbers = append(numbers, issue.GetNumber())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if expected := []int{5, 4, 3, 2, 1}; !reflect.DeepEqual(numbers, expected) {
+		t.Error("\nISSUES NOT PAGINATED NEWEST FIRST", "\nEXPECTED: ", expected, "\nACTUAL:   ", numbers)
+	}
+	open, _, _ := client.Issues.ListByRepo(ctx, "rebellion", "x-wing", nil)
+	if len(open) != 4 {
+		t.Error("\nCLOSED ISSUES LISTED AS OPEN", "\nEXPECTED: ", 4, "\nACTUAL:   ", len(open))
+	}
+	pulls, _, _ := client.PullRequests.List(ctx, "rebellion", "x-wing", nil)
+	if len(pulls) != 1 || pulls[0].GetNumber() != 6 {
+		t.Error("\nPULL NOT NUMBERED AFTER ISSUES", "\nEXPECTED: ", 6, "\nACTUAL:   ", pulls)
+	}
+
+	if _, _, err := client.Issues.AddAssignees(ctx, "rebellion", "x-wing", 1, []string{"Luke", "Wedge"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Issues.RemoveAssignees(ctx, "rebellion", "x-wing", 1, []string{"Wedge"}); err != nil {
+		t.Fatal(err)
+	}
+	if assignees := gh.Assignees("rebellion", "x-wing", 1); !reflect.DeepEqual(assignees, []string{"Luke"}) {
+		t.Error("\nUNEXPECTED ASSIGNEES", "\nEXPECTED: ", []string{"Luke"}, "\nACTUAL:   ", assignees)
+	}
+
+	if _, _, err := client.Issues.AddLabelsToIssue(ctx, "rebellion", "x-wing", 1, []string{"bug", "triaged"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Issues.RemoveLabelForIssue(ctx, "rebellion", "x-wing", 1, "bug"); err != nil {
+		t.Fatal(err)
+	}
+	if labels := gh.Labels("rebellion", "x-wing", 1); !reflect.DeepEqual(labels, []string{"triaged"}) {
+		t.Error("\nUNEXPECTED ISSUE LABELS", "\nEXPECTED: ", []string{"triaged"}, "\nACTUAL:   ", labels)
+	}
+	if labels := gh.RepoLabels("rebellion", "x-wing"); !reflect.DeepEqual(labels, []string{"bug", "triaged"}) {
+		t.Error("\nUNEXPECTED REPO LABELS", "\nEXPECTED: ", []string{"bug", "triaged"}, "\nACTUAL:   ", labels)
+	}
+	if _, _, err := client.Issues.GetLabel(ctx, "rebellion", "x-wing", "wontfix"); err == nil {
+		t.Error("\nMISSING LABEL FOUND")
+	}
+
+	comment, _, err := client.Issues.CreateComment(ctx, "rebellion", "x-wing", 1, &github.IssueComment{Body: github.String("Stay on target")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Issues.EditComment(ctx, "rebellion", "x-wing", comment.GetID(), &github.IssueComment{Body: github.String("Stay on target!")}); err != nil {
+		t.Fatal(err)
+	}
+	if comments := gh.Comments("rebellion", "x-wing", 1); !reflect.DeepEqual(comments, []string{"Stay on target!"}) {
+		t.Error("\nUNEXPECTED COMMENTS", "\nEXPECTED: ", []string{"Stay on target!"}, "\nACTUAL:   ", comments)
+	}
+
+	gh.AddReaction("rebellion", "x-wing", comment.GetID(), "Leia", "+1")
+	reactions, _, err := client.Reactions.ListIssueCommentReactions(ctx, "rebellion", "x-wing", comment.GetID(), nil)
+	if err != nil || len(reactions) != 1 || reactions[0].GetUser().GetLogin() != "Leia" || reactions[0].GetContent() != "+1" {
+		t.Error("\nUNEXPECTED COMMENT REACTIONS", "\nEXPECTED: ", "Leia +1", "\nACTUAL:   ", reactions, err)
+	}
+	gh.SetPermission("rebellion", "x-wing", "Leia", "admin")
+	for login, expected := range map[string]string{"Leia": "admin", "Jabba": "read"} {
+		level, _, err := client.Repositories.GetPermissionLevel(ctx, "rebellion", "x-wing", login)
+		if err != nil || level.GetPermission() != expected {
+			t.Error("\nUNEXPECTED PERMISSION", "\nUSER:     ", login, "\nEXPECTED: ", expected, "\nACTUAL:   ", level.GetPermission(), err)
+		}
+	}
+
+	if _, _, err := client.Issues.Edit(ctx, "rebellion", "x-wing", 2, &github.IssueRequest{State: github.String("closed")}); err != nil {
+		t.Fatal(err)
+	}
+	if issue, _ := gh.Issue("rebellion", "x-wing", 2); issue.GetState() != "closed" || issue.ClosedAt == nil {
+		t.Error("\nISSUE NOT CLOSED", "\nEXPECTED: ", "closed", "\nACTUAL:   ", issue.GetState())
+	}
+	mutations := gh.Mutations()
+	if len(mutations) != 7 {
+		t.Error("\nUNEXPECTED MUTATION COUNT", "\nEXPECTED: ", 7, "\nACTUAL:   ", len(mutations))
+	}
+	if len(mutations) != 0 && !mutations[0].At.Equal(yavin) {
+		t.Error("\nMUTATION NOT AT CLOCK TIME", "\nEXPECTED: ", yavin, "\nACTUAL:   ", mutations[0].At)
+	}
+}
+
+func TestInstallations(t *testing.T) {
+	gh := New()
+	defer gh.Close()
+	client := gh.Client()
+	ctx := context.Background()
+
+	gh.AddInstallation(1234, 6807, "contingency/chancellor", "contingency/jedi")
+	gh.AddFile("contingency", "jedi", ".heupr.toml", "[triager]")
+
+	repos, _, err := client.Apps.ListUserRepos(ctx, 1234, nil)
+	if err != nil || len(repos) != 2 || repos[0].GetFullName() != "contingency/chancellor" {
+		t.Error("\nUNEXPECTED INSTALLATION REPOS", "\nEXPECTED: ", "contingency/chancellor contingency/jedi", "\nACTUAL:   ", repos, err)
+	}
+	if _, _, err := client.Apps.ListUserRepos(ctx, 4321, nil); err == nil {
+		t.Error("\nMISSING INSTALLATION FOUND")
+	}
+	content, _, _, err := client.Repositories.GetContents(ctx, "contingency", "jedi", ".heupr.toml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, _ := content.GetContent(); decoded != "[triager]" {
+		t.Error("\nUNEXPECTED FILE CONTENT", "\nEXPECTED: ", "[triager]", "\nACTUAL:   ", decoded)
+	}
+	gh.RemoveFile("contingency", "jedi", ".heupr.toml")
+	if _, _, _, err := client.Repositories.GetContents(ctx, "contingency", "jedi", ".heupr.toml", nil); err == nil {
+		t.Error("\nREMOVED FILE FOUND")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gh := New()
+	defer gh.Close()
+	client := gh.Client()
+	ctx := context.Background()
+
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	gh.SetRateLimit(10, 1, reset)
+	gh.AddRepo("empire", "death-star")
+
+	_, resp, err := client.Repositories.Get(ctx, "empire", "death-star")
+	if err != nil || resp.Rate.Limit != 10 || resp.Rate.Remaining != 0 || !resp.Rate.Reset.Time.Equal(reset) {
+		t.Error("\nUNEXPECTED RATE HEADERS", "\nEXPECTED: ", 10, 0, reset, "\nACTUAL:   ", resp.Rate, err)
+	}
+	if _, _, err := client.Repositories.Get(ctx, "empire", "death-star"); err == nil {
+		t.Error("\nRATE LIMIT NOT ENFORCED")
+	} else if _, ok := err.(*github.RateLimitError); !ok {
+		t.Error("\nUNEXPECTED RATE LIMIT ERROR", "\nEXPECTED: ", "*github.RateLimitError", "\nACTUAL:   ", err)
+	}
+	// A fresh client is not stopped by the go-github rate limit cache.
+	if _, _, err := gh.Client().Repositories.Get(ctx, "empire", "death-star"); err == nil {
+		t.Error("\nRATE LIMIT NOT ENFORCED BY SERVER")
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	gh := New()
+	defer gh.Close()
+
+	events := []interface{}{}
+	gh.SetWebhook(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := github.ValidatePayload(r, []byte("sith"))
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		event, err := github.ParseWebHook(github.WebHookType(r), payload)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events = append(events, event)
+	}), "sith")
+
+	gh.Install(1234, 6807, "rebellion/x-wing")
+	issue := gh.OpenIssue("rebellion", "x-wing", "Wedge", "Hyperdrive motivator broken", "")
+	gh.Client().Issues.AddAssignees(context.Background(), "rebellion", "x-wing", issue.GetNumber(), []string{"Chewbacca"})
+	gh.Comment("rebellion", "x-wing", issue.GetNumber(), "Han", "It's not my fault")
+
+	if len(events) != 4 {
+		t.Fatal("\nUNEXPECTED WEBHOOK COUNT", "\nEXPECTED: ", 4, "\nACTUAL:   ", len(events))
+	}
+	if e, ok := events[0].(*github.InstallationEvent); !ok || e.GetInstallation().GetID() != 1234 {
+		t.Error("\nINSTALLATION NOT EMITTED", "\nEXPECTED: ", 1234, "\nACTUAL:   ", events[0])
+	}
+	if e, ok := events[1].(*github.IssuesEvent); !ok || e.GetAction() != "opened" || e.GetSender().GetLogin() != "Wedge" || e.GetRepo().GetFullName() != "rebellion/x-wing" {
+		t.Error("\nOPENED ISSUE NOT EMITTED", "\nEXPECTED: ", "opened by Wedge", "\nACTUAL:   ", events[1])
+	}
+	if e, ok := events[2].(*github.IssuesEvent); !ok || e.GetAction() != "assigned" || e.GetSender().GetLogin() != Login || e.GetAssignee().GetLogin() != "Chewbacca" || e.GetInstallation().GetID() != 1234 {
+		t.Error("\nASSIGNMENT NOT EMITTED", "\nEXPECTED: ", "Chewbacca assigned by "+Login, "\nACTUAL:   ", events[2])
+	}
+	if e, ok := events[3].(*github.IssueCommentEvent); !ok || e.GetComment().GetBody() != "It's not my fault" {
+		t.Error("\nCOMMENT NOT EMITTED", "\nEXPECTED: ", "It's not my fault", "\nACTUAL:   ", events[3])
+	}
+	for _, delivery := range gh.Deliveries() {
+		if delivery.Status != http.StatusOK {
+			t.Error("\nWEBHOOK NOT ACCEPTED", "\nEXPECTED: ", http.StatusOK, "\nACTUAL:   ", delivery.Status, delivery.Event)
+		}
+	}
+}
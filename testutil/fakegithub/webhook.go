@@ -0,0 +1,257 @@
+package fakegithub
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// Delivery is a webhook delivered to the handler set with SetWebhook.
+type Delivery struct {
+	ID      string
+	Event   string
+	Payload json.RawMessage
+	// Status is the response code of the handler.
+	Status int
+}
+
+// SetWebhook sets the handler that receives the webhooks, posted to
+// /webhook and signed with the secret. Until it is set, no webhook is
+// emitted.
+func (s *Server) SetWebhook(handler http.Handler, secret string) {
+	s.Lock()
+	defer s.Unlock()
+	s.webhook, s.secret = handler, []byte(secret)
+}
+
+// Deliveries returns the webhooks delivered so far, in order.
+func (s *Server) Deliveries() []Delivery {
+	s.Lock()
+	defer s.Unlock()
+	return append([]Delivery{}, s.deliveries...)
+}
+
+// Emit delivers a webhook of the event type (e.g. "issues") with the payload
+// to the handler and returns the response code; 0 if no handler is set.
+func (s *Server) Emit(event string, payload interface{}) int {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	s.Lock()
+	s.pending = append(s.pending, Delivery{Event: event, Payload: body})
+	s.Unlock()
+	deliveries := s.flush()
+	if len(deliveries) == 0 {
+		return 0
+	}
+	return deliveries[len(deliveries)-1].Status
+}
+
+// queue adds a webhook of the repository to be delivered by flush. The
+// payload is encoded right away so later changes do not leak into it. The
+// server must be locked.
+func (s *Server) queue(event string, repo *repo, sender string, payload map[string]interface{}) {
+	if s.webhook == nil {
+		return
+	}
+	payload["repository"] = repo.repository
+	payload["sender"] = &github.User{Login: github.String(sender)}
+	if id, ok := s.installation(*repo.repository.FullName); ok {
+		payload["installation"] = &github.Installation{ID: github.Int64(id)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+	s.pending = append(s.pending, Delivery{Event: event, Payload: body})
+}
+
+// queueIssue adds an issues webhook. The server must be locked.
+func (s *Server) queueIssue(action string, issue *github.Issue, fields map[string]interface{}) {
+	repo, ok := s.repos[issue.Repository.GetFullName()]
+	if !ok {
+		return
+	}
+	payload := map[string]interface{}{"action": action, "issue": issue}
+	for key, value := range fields {
+		payload[key] = value
+	}
+	s.queue("issues", repo, Login, payload)
+}
+
+// installation returns the ID of the first installation with access to the
+// repository. The server must be locked.
+func (s *Server) installation(fullName string) (int64, bool) {
+	found, result := false, int64(0)
+	for id, repos := range s.installations {
+		for _, name := range repos {
+			if name == fullName && (!found || id < result) {
+				found, result = true, id
+			}
+		}
+	}
+	return result, found
+}
+
+// flush delivers the queued webhooks in order, without holding the lock so
+// the handler can call the API, and returns them.
+func (s *Server) flush() []Delivery {
+	s.Lock()
+	pending, handler, secret := s.pending, s.webhook, s.secret
+	s.pending = nil
+	s.Unlock()
+	if handler == nil {
+		return nil
+	}
+
+	delivered := []Delivery{}
+	for _, delivery := range pending {
+		s.Lock()
+		delivery.ID = "delivery-" + itoa(len(s.deliveries)+len(delivered)+1)
+		s.Unlock()
+
+		mac := hmac.New(sha1.New, secret)
+		mac.Write(delivery.Payload)
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(delivery.Payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", delivery.Event)
+		req.Header.Set("X-GitHub-Delivery", delivery.ID)
+		req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		delivery.Status = recorder.Code
+		delivered = append(delivered, delivery)
+	}
+	s.Lock()
+	s.deliveries = append(s.deliveries, delivered...)
+	s.Unlock()
+	return delivered
+}
+
+// OpenIssue creates an issue opened by the user and emits its webhook.
+func (s *Server) OpenIssue(owner, name, login, title, body string) *github.Issue {
+	s.Lock()
+	issue := s.addIssue(owner, name, github.Issue{
+		Title: github.String(title),
+		Body:  github.String(body),
+		User:  &github.User{Login: github.String(login)},
+	})
+	s.queue("issues", s.repos[owner+"/"+name], login, map[string]interface{}{"action": "opened", "issue": issue})
+	result := copyIssue(issue)
+	s.Unlock()
+	s.flush()
+	return result
+}
+
+// CloseIssue closes an issue as the user and emits its webhook. It returns
+// false if the issue does not exist.
+func (s *Server) CloseIssue(owner, name string, number int, login string) bool {
+	s.Lock()
+	repo, ok := s.repos[owner+"/"+name]
+	if !ok || repo.issues[number] == nil {
+		s.Unlock()
+		return false
+	}
+	issue := repo.issues[number]
+	now := s.now()
+	issue.State = github.String("closed")
+	issue.ClosedAt = &now
+	issue.ClosedBy = &github.User{Login: github.String(login)}
+	s.queue("issues", repo, login, map[string]interface{}{"action": "closed", "issue": issue})
+	s.Unlock()
+	s.flush()
+	return true
+}
+
+// Assign assigns an issue as the user, as opposed to through the API, and
+// emits its webhook. It returns false if the issue does not exist.
+func (s *Server) Assign(owner, name string, number int, login, assignee string) bool {
+	s.Lock()
+	repo, ok := s.repos[owner+"/"+name]
+	if !ok || repo.issues[number] == nil {
+		s.Unlock()
+		return false
+	}
+	issue := repo.issues[number]
+	if !hasAssignee(issue, assignee) {
+		user := &github.User{Login: github.String(assignee)}
+		issue.Assignees = append(issue.Assignees, user)
+		if issue.Assignee == nil {
+			issue.Assignee = user
+		}
+		s.queue("issues", repo, login, map[string]interface{}{"action": "assigned", "issue": issue, "assignee": user})
+	}
+	s.Unlock()
+	s.flush()
+	return true
+}
+
+// Comment adds a comment of the user to an issue and emits its webhook. It
+// returns nil if the issue does not exist.
+func (s *Server) Comment(owner, name string, number int, login, body string) *github.IssueComment {
+	s.Lock()
+	repo, ok := s.repos[owner+"/"+name]
+	if !ok || repo.issues[number] == nil {
+		s.Unlock()
+		return nil
+	}
+	comment := *s.addComment(repo, repo.issues[number], login, body)
+	s.Unlock()
+	s.flush()
+	return &comment
+}
+
+// OpenPull creates a pull request opened by the user and emits its webhook.
+func (s *Server) OpenPull(owner, name, login, title, body string) *github.PullRequest {
+	pull := s.AddPull(owner, name, github.PullRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+		User:  &github.User{Login: github.String(login)},
+	})
+	s.Lock()
+	s.queue("pull_request", s.repos[owner+"/"+name], login, map[string]interface{}{"action": "opened", "number": pull.GetNumber(), "pull_request": pull})
+	s.Unlock()
+	s.flush()
+	return pull
+}
+
+// Install adds an installation, as AddInstallation does, and emits its
+// created webhook, sent by the owner of the first repository.
+func (s *Server) Install(id int64, appID int, repos ...string) {
+	s.AddInstallation(id, appID, repos...)
+	s.Lock()
+	if s.webhook != nil {
+		repositories := []*github.Repository{}
+		for _, fullName := range repos {
+			repositories = append(repositories, s.repos[fullName].repository)
+		}
+		account := ""
+		if len(repos) != 0 {
+			account = strings.SplitN(repos[0], "/", 2)[0]
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"action": "created",
+			"installation": &github.Installation{
+				ID:      github.Int64(id),
+				AppID:   github.Int64(int64(appID)),
+				Account: &github.User{Login: github.String(account)},
+			},
+			"repositories": repositories,
+			"sender":       &github.User{Login: github.String(account)},
+		})
+		if err != nil {
+			panic(err)
+		}
+		s.pending = append(s.pending, Delivery{Event: "installation", Payload: body})
+	}
+	s.Unlock()
+	s.flush()
+}
@@ -24,6 +24,9 @@ type configuration struct {
 	IngestorActivationEndpoint string
 	BackendServerAddress       string
 	BackendActivationEndpoint  string
+	// BackendAdminToken authenticates the backend admin API, which is
+	// disabled when it is empty.
+	BackendAdminToken string
 }
 
 var initOnceCnf sync.Once